@@ -0,0 +1,105 @@
+package ask
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jfox85/devx/session"
+)
+
+const projectPrefix = "project:"
+
+// Remember describes how an "always" approval is kept: for how long (forever
+// when For is zero) and whether it covers the requests' whole projects rather
+// than the session pair.
+type Remember struct {
+	For      time.Duration
+	Projects bool
+}
+
+// approval builds the approval remembering req.
+func (r Remember) approval(req *Request, sessions *session.SessionStore) (Approval, error) {
+	now := time.Now().UTC()
+	a := Approval{CreatedAt: now}
+	if r.For > 0 {
+		expires := now.Add(r.For)
+		a.ExpiresAt = &expires
+	}
+	if !r.Projects {
+		a.FromSession, a.ToSession, a.FromPath, a.ToPath = req.FromSession, req.ToSession, req.FromPath, req.ToPath
+		return a, nil
+	}
+	a.FromProject = sessionProject(sessions, req.FromSession, req.FromPath)
+	a.ToProject = sessionProject(sessions, req.ToSession, req.ToPath)
+	if a.FromProject == "" || a.ToProject == "" {
+		return a, fmt.Errorf("project approval needs both %q and %q to belong to a registered project", req.FromSession, req.ToSession)
+	}
+	return a, nil
+}
+
+// sessionProject returns the project of a session, or "" when the session is
+// unknown, has no project or no longer lives at path.
+func sessionProject(sessions *session.SessionStore, name, path string) string {
+	sess, ok := sessions.GetSession(name)
+	if !ok || sess.Path != path {
+		return ""
+	}
+	return sess.ProjectAlias
+}
+
+// IsProject reports whether the approval covers two projects.
+func (a Approval) IsProject() bool { return a.FromProject != "" || a.ToProject != "" }
+
+// Expired reports whether the approval has run out at now.
+func (a Approval) Expired(now time.Time) bool {
+	return a.ExpiresAt != nil && !now.Before(*a.ExpiresAt)
+}
+
+// From names the requester side: a session, or "project:<alias>".
+func (a Approval) From() string {
+	if a.IsProject() {
+		return projectPrefix + a.FromProject
+	}
+	return a.FromSession
+}
+
+// To names the target side: a session, or "project:<alias>".
+func (a Approval) To() string {
+	if a.IsProject() {
+		return projectPrefix + a.ToProject
+	}
+	return a.ToSession
+}
+
+func (a Approval) samePair(b Approval) bool {
+	if a.IsProject() || b.IsProject() {
+		return a.FromProject == b.FromProject && a.ToProject == b.ToProject
+	}
+	return a.FromSession == b.FromSession && a.ToSession == b.ToSession && a.FromPath == b.FromPath && a.ToPath == b.ToPath
+}
+
+// Revoke removes every approval from one subject to another (a session name
+// or "project:<alias>") and returns how many it removed.
+func (s *Store) Revoke(from, to string) (int, error) {
+	removed := 0
+	err := s.withApprovalsLock(func() error {
+		approvals, err := s.loadApprovals()
+		if err != nil {
+			return err
+		}
+		kept := approvals.Approvals[:0]
+		for _, approval := range approvals.Approvals {
+			if approval.From() == from && approval.To() == to {
+				removed++
+				continue
+			}
+			kept = append(kept, approval)
+		}
+		if removed == 0 {
+			return nil
+		}
+		approvals.Approvals = kept
+		return s.saveApprovals(approvals)
+	})
+	return removed, err
+}
@@ -0,0 +1,269 @@
+package ask
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfox85/devx/session"
+)
+
+// Combined statuses of a broadcast, in addition to the request statuses all
+// of its requests share.
+const (
+	BroadcastPending = "pending"
+	BroadcastRunning = "running"
+	BroadcastPartial = "partial"
+)
+
+const defaultParallel = 4
+
+var broadcastIDPattern = regexp.MustCompile(`^bc_[0-9a-f]+$`)
+
+// IsBroadcastID reports whether id names a broadcast rather than a request.
+func IsBroadcastID(id string) bool { return broadcastIDPattern.MatchString(id) }
+
+// SelectTargets resolves a broadcast selector to sessions, sorted by name.
+// A selector is "project:<alias>", "tag:<tag>" or a session name. The
+// requesting session is never its own target.
+func SelectTargets(sessions map[string]*session.Session, selector, from string) ([]*session.Session, error) {
+	kind, value, ok := strings.Cut(selector, ":")
+	if !ok {
+		kind, value = "session", selector
+	}
+	if value == "" {
+		return nil, fmt.Errorf("empty target selector %q", selector)
+	}
+	var match func(*session.Session) bool
+	switch kind {
+	case "project":
+		match = func(s *session.Session) bool { return s.ProjectAlias == value }
+	case "tag":
+		match = func(s *session.Session) bool { return hasTag(s, value) }
+	case "session":
+		match = func(s *session.Session) bool { return s.Name == value }
+	default:
+		return nil, fmt.Errorf("unknown target selector %q (want project:<alias>, tag:<tag> or a session name)", selector)
+	}
+	var out []*session.Session
+	for name, sess := range sessions {
+		if name != from && match(sess) {
+			out = append(out, sess)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no sessions other than %q match %q", from, selector)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func hasTag(s *session.Session, tag string) bool {
+	for _, t := range s.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateBroadcast creates one pending request per target, all sharing a
+// broadcast id so they can be approved and read back together.
+func (s *Store) CreateBroadcast(fromSession, fromPath string, targets []*session.Session, question string) ([]*Request, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("broadcast needs at least one target session")
+	}
+	id := newBroadcastID()
+	reqs := make([]*Request, 0, len(targets))
+	for _, target := range targets {
+		req, err := s.Create(fromSession, target.Name, fromPath, target.Path, question)
+		if err != nil {
+			return reqs, err
+		}
+		req.BroadcastID = id
+		if err := s.Save(req); err != nil {
+			return reqs, err
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// Broadcast returns the requests of a broadcast, ordered by target session.
+func (s *Store) Broadcast(id string) ([]*Request, error) {
+	if !IsBroadcastID(id) {
+		return nil, fmt.Errorf("invalid broadcast id %q", id)
+	}
+	all, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var out []*Request
+	for _, req := range all {
+		if req.BroadcastID == id {
+			out = append(out, req)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("broadcast %s not found", id)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ToSession < out[j].ToSession })
+	return out, nil
+}
+
+// ApproveBroadcast approves every pending request of a broadcast and runs
+// their responders, at most policy.Parallel at a time. A non-nil remember
+// keeps each approval as ApproveRememberAndExecute does. It returns the
+// broadcast's requests afterwards; failures of single responders are joined
+// into the error.
+func (s *Store) ApproveBroadcast(ctx context.Context, id string, policy Policy, remember *Remember) ([]*Request, error) {
+	reqs, err := s.Broadcast(id)
+	if err != nil {
+		return nil, err
+	}
+	var pending []*Request
+	for _, req := range reqs {
+		if req.Status == StatusPendingApproval {
+			pending = append(pending, req)
+		}
+	}
+	if len(pending) == 0 {
+		return reqs, fmt.Errorf("broadcast %s has no pending requests", id)
+	}
+	runErr := RunParallel(pending, parallelLimit(policy), func(req *Request) error {
+		_, err := s.approveAndExecute(ctx, req.ID, policy, remember)
+		return err
+	})
+	reqs, err = s.Broadcast(id)
+	if err != nil {
+		return nil, err
+	}
+	return reqs, runErr
+}
+
+// DenyBroadcast denies every pending request of a broadcast.
+func (s *Store) DenyBroadcast(id string) ([]*Request, error) {
+	reqs, err := s.Broadcast(id)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for i, req := range reqs {
+		if req.Status != StatusPendingApproval {
+			continue
+		}
+		denied, err := s.Deny(req.ID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		reqs[i] = denied
+	}
+	return reqs, errors.Join(errs...)
+}
+
+// RunParallel calls fn for every request with at most limit calls in flight
+// and joins their errors, each prefixed with the target session.
+func RunParallel(reqs []*Request, limit int, fn func(*Request) error) error {
+	if limit <= 0 {
+		limit = defaultParallel
+	}
+	sem := make(chan struct{}, limit)
+	errs := make([]error, len(reqs))
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(req); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", req.ToSession, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func parallelLimit(policy Policy) int {
+	if policy.Parallel > 0 {
+		return policy.Parallel
+	}
+	if n := EffectivePolicy().Parallel; n > 0 {
+		return n
+	}
+	return defaultParallel
+}
+
+// BroadcastStatus combines the statuses of a broadcast's requests: the shared
+// status when they all agree, running or pending while any responder has yet
+// to finish, and otherwise partial when some answered or failed when none did.
+func BroadcastStatus(reqs []*Request) string {
+	if len(reqs) == 0 {
+		return ""
+	}
+	counts := map[string]int{}
+	for _, req := range reqs {
+		counts[req.Status]++
+	}
+	if len(counts) == 1 {
+		return reqs[0].Status
+	}
+	switch {
+	case counts[StatusRunning] > 0 || counts[StatusApproved] > 0:
+		return BroadcastRunning
+	case counts[StatusPendingApproval] > 0:
+		return BroadcastPending
+	case counts[StatusAnswered] > 0:
+		return BroadcastPartial
+	}
+	return StatusFailed
+}
+
+// RenderBroadcast aggregates a broadcast into one Markdown document with a
+// section per target session.
+func RenderBroadcast(reqs []*Request) string {
+	if len(reqs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	answered := 0
+	for _, req := range reqs {
+		if req.Status == StatusAnswered {
+			answered++
+		}
+	}
+	fmt.Fprintf(&b, "# Broadcast %s: %s (%d/%d answered)\n\n", reqs[0].BroadcastID, BroadcastStatus(reqs), answered, len(reqs))
+	fmt.Fprintf(&b, "Question: %s\n", reqs[0].Question)
+	for _, req := range reqs {
+		fmt.Fprintf(&b, "\n## %s [%s]\n\n", req.ToSession, req.Status)
+		switch {
+		case req.Response != nil:
+			b.WriteString(req.Response.Body)
+		case req.Error != "":
+			b.WriteString("Error: " + req.Error)
+		case req.Status == StatusPendingApproval:
+			fmt.Fprintf(&b, "Waiting for approval (%s).", req.ID)
+		default:
+			fmt.Fprintf(&b, "No answer yet (%s).", req.ID)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func newBroadcastID() string {
+	var b [6]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("bc_%x", time.Now().UnixNano())
+	}
+	return "bc_" + hex.EncodeToString(b[:])
+}
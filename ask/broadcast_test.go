@@ -0,0 +1,116 @@
+package ask
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfox85/devx/session"
+)
+
+func TestSelectTargets(t *testing.T) {
+	sessions := map[string]*session.Session{
+		"api-a":    {Name: "api-a", ProjectAlias: "api", Tags: []string{"backend"}},
+		"api-b":    {Name: "api-b", ProjectAlias: "api"},
+		"web":      {Name: "web", ProjectAlias: "web", Tags: []string{"Backend", "ui"}},
+		"frontend": {Name: "frontend", ProjectAlias: "api", Tags: []string{"backend"}},
+	}
+	names := func(ss []*session.Session) string {
+		var out []string
+		for _, s := range ss {
+			out = append(out, s.Name)
+		}
+		return strings.Join(out, ",")
+	}
+	for selector, want := range map[string]string{
+		"project:api": "api-a,api-b",
+		"tag:backend": "api-a,web",
+		"web":         "web",
+	} {
+		got, err := SelectTargets(sessions, selector, "frontend")
+		if err != nil {
+			t.Fatalf("SelectTargets(%q): %v", selector, err)
+		}
+		if names(got) != want {
+			t.Errorf("SelectTargets(%q) = %s, want %s", selector, names(got), want)
+		}
+	}
+	for _, selector := range []string{"project:none", "frontend", "owner:me", "tag:"} {
+		if _, err := SelectTargets(sessions, selector, "frontend"); err == nil {
+			t.Errorf("SelectTargets(%q) should fail", selector)
+		}
+	}
+}
+
+func TestApproveBroadcastRunsEveryTarget(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	configDir := filepath.Join(tmp, ".config", "devx")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	a, b := t.TempDir(), t.TempDir()
+	sessionsJSON := fmt.Sprintf(`{"sessions":{
+		"api-a":{"name":"api-a","branch":"main","path":%q,"created_at":"2026-01-01T00:00:00Z","updated_at":"2026-01-01T00:00:00Z"},
+		"api-b":{"name":"api-b","branch":"main","path":%q,"created_at":"2026-01-01T00:00:00Z","updated_at":"2026-01-01T00:00:00Z"}}}`, a, b)
+	if err := os.WriteFile(filepath.Join(configDir, "sessions.json"), []byte(sessionsJSON), 0600); err != nil {
+		t.Fatal(err)
+	}
+	store := NewStoreAt(filepath.Join(tmp, "asks"))
+	targets := []*session.Session{{Name: "api-a", Path: a}, {Name: "api-b", Path: b}}
+	reqs, err := store.CreateBroadcast("frontend", "/front", targets, "touch billing?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := reqs[0].BroadcastID
+	if !IsBroadcastID(id) || reqs[1].BroadcastID != id {
+		t.Fatalf("broadcast ids = %q, %q", id, reqs[1].BroadcastID)
+	}
+	if got := BroadcastStatus(reqs); got != StatusPendingApproval {
+		t.Fatalf("status before approval = %q", got)
+	}
+
+	policy := Policy{Enabled: true, Mode: "approval", Command: "/bin/echo", Args: []string{"no billing in {{.ToSession}}"}, Timeout: 5 * time.Second, Parallel: 2}
+	reqs, err = store.ApproveBroadcast(context.Background(), id, policy, nil)
+	if err != nil {
+		t.Fatalf("ApproveBroadcast: %v", err)
+	}
+	if got := BroadcastStatus(reqs); got != StatusAnswered {
+		t.Fatalf("status after approval = %q: %+v", got, reqs)
+	}
+	doc := RenderBroadcast(reqs)
+	for _, want := range []string{"(2/2 answered)", "## api-a [answered]", "no billing in api-a", "## api-b [answered]", "no billing in api-b"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document missing %q:\n%s", want, doc)
+		}
+	}
+	if _, err := store.ApproveBroadcast(context.Background(), id, policy, nil); err == nil {
+		t.Error("approving a finished broadcast should fail")
+	}
+}
+
+func TestBroadcastStatus(t *testing.T) {
+	req := func(status string) *Request { return &Request{Status: status} }
+	for _, tc := range []struct {
+		statuses []string
+		want     string
+	}{
+		{[]string{StatusAnswered, StatusDenied}, BroadcastPartial},
+		{[]string{StatusFailed, StatusDenied}, StatusFailed},
+		{[]string{StatusAnswered, StatusPendingApproval}, BroadcastPending},
+		{[]string{StatusRunning, StatusPendingApproval}, BroadcastRunning},
+		{[]string{StatusDenied, StatusDenied}, StatusDenied},
+	} {
+		var reqs []*Request
+		for _, s := range tc.statuses {
+			reqs = append(reqs, req(s))
+		}
+		if got := BroadcastStatus(reqs); got != tc.want {
+			t.Errorf("BroadcastStatus(%v) = %q, want %q", tc.statuses, got, tc.want)
+		}
+	}
+}
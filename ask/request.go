@@ -19,6 +19,9 @@ type Request struct {
 	FromPath    string             `json:"from_path,omitempty"`
 	ToPath      string             `json:"to_path,omitempty"`
 	Question    string             `json:"question"`
+	ThreadID    string             `json:"thread_id,omitempty"`
+	ParentID    string             `json:"parent_id,omitempty"`
+	BroadcastID string             `json:"broadcast_id,omitempty"`
 	Status      string             `json:"status"`
 	CreatedAt   time.Time          `json:"created_at"`
 	UpdatedAt   time.Time          `json:"updated_at"`
@@ -27,6 +30,15 @@ type Request struct {
 	Error       string             `json:"error,omitempty"`
 }
 
+// Thread returns the id of the conversation the request belongs to: its
+// ThreadID, or its own id when it starts a thread.
+func (r *Request) Thread() string {
+	if r.ThreadID != "" {
+		return r.ThreadID
+	}
+	return r.ID
+}
+
 type Response struct {
 	Body      string    `json:"body"`
 	CreatedAt time.Time `json:"created_at"`
@@ -42,12 +54,18 @@ type ExecutionMetadata struct {
 	LogPath    string     `json:"log_path,omitempty"`
 }
 
+// Approval lets asks run without a prompt. It covers either one session pair,
+// pinned to both worktree paths, or when FromProject and ToProject are set,
+// any session of one project asking any session of the other.
 type Approval struct {
-	FromSession string    `json:"from_session"`
-	ToSession   string    `json:"to_session"`
-	FromPath    string    `json:"from_path"`
-	ToPath      string    `json:"to_path"`
-	CreatedAt   time.Time `json:"created_at"`
+	FromSession string     `json:"from_session,omitempty"`
+	ToSession   string     `json:"to_session,omitempty"`
+	FromPath    string     `json:"from_path,omitempty"`
+	ToPath      string     `json:"to_path,omitempty"`
+	FromProject string     `json:"from_project,omitempty"`
+	ToProject   string     `json:"to_project,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
 }
 
 type ApprovalStore struct {
@@ -61,4 +79,5 @@ type Policy struct {
 	Args     []string
 	Timeout  time.Duration
 	ReadOnly bool
+	Parallel int // responders a broadcast runs at once
 }
@@ -54,6 +54,67 @@ func (s *Store) Create(fromSession, toSession, fromPath, toPath, question string
 	return req, s.Save(req)
 }
 
+// Reply creates a follow-up question in the thread of parentID, addressed to
+// the same session. The parent must have been answered so the responder can
+// see the conversation so far.
+func (s *Store) Reply(parentID, question string) (*Request, error) {
+	parent, err := s.Get(parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Status != StatusAnswered {
+		return nil, fmt.Errorf("request %s is %s; only answered asks can be replied to", parent.ID, parent.Status)
+	}
+	req, err := s.Create(parent.FromSession, parent.ToSession, parent.FromPath, parent.ToPath, question)
+	if err != nil {
+		return nil, err
+	}
+	req.ThreadID = parent.Thread()
+	req.ParentID = parent.ID
+	return req, s.Save(req)
+}
+
+// Thread returns every request in the conversation id belongs to, oldest
+// first.
+func (s *Store) Thread(id string) ([]*Request, error) {
+	req, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	thread := req.Thread()
+	var out []*Request
+	for _, r := range all {
+		if r.Thread() == thread {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// history returns the answered requests that precede req in its thread,
+// following ParentID links back to the start of the conversation.
+func (s *Store) history(req *Request) []*Request {
+	var out []*Request
+	seen := map[string]bool{req.ID: true}
+	for id := req.ParentID; id != "" && !seen[id]; {
+		seen[id] = true
+		parent, err := s.Get(id)
+		if err != nil {
+			break
+		}
+		if parent.Status == StatusAnswered && parent.Response != nil {
+			out = append([]*Request{parent}, out...)
+		}
+		id = parent.ParentID
+	}
+	return out
+}
+
 func (s *Store) Save(req *Request) error {
 	if req == nil || req.ID == "" {
 		return fmt.Errorf("request id is required")
@@ -77,14 +138,20 @@ func (s *Store) Save(req *Request) error {
 }
 
 func (s *Store) ApproveAndExecute(ctx context.Context, id string, policy Policy) (*Request, error) {
-	return s.approveAndExecute(ctx, id, policy, false)
+	return s.approveAndExecute(ctx, id, policy, nil)
 }
 
 func (s *Store) ApproveAlwaysAndExecute(ctx context.Context, id string, policy Policy) (*Request, error) {
-	return s.approveAndExecute(ctx, id, policy, true)
+	return s.approveAndExecute(ctx, id, policy, &Remember{})
 }
 
-func (s *Store) approveAndExecute(ctx context.Context, id string, policy Policy, allowFuture bool) (*Request, error) {
+// ApproveRememberAndExecute approves a request and, once its responder
+// succeeds, remembers the approval as remember describes.
+func (s *Store) ApproveRememberAndExecute(ctx context.Context, id string, policy Policy, remember Remember) (*Request, error) {
+	return s.approveAndExecute(ctx, id, policy, &remember)
+}
+
+func (s *Store) approveAndExecute(ctx context.Context, id string, policy Policy, remember *Remember) (*Request, error) {
 	var result *Request
 	err := s.withRequestLock(id, func() error {
 		req, err := s.Get(id)
@@ -102,9 +169,15 @@ func (s *Store) approveAndExecute(ctx context.Context, id string, policy Policy,
 		if !ok {
 			return fmt.Errorf("session %q not found", req.ToSession)
 		}
+		var approval Approval
+		if remember != nil {
+			if approval, err = remember.approval(req, sessions); err != nil {
+				return err
+			}
+		}
 		updated, err := Execute(ctx, req, target, ExecuteOptions{Policy: policy, Store: s})
-		if err == nil && allowFuture {
-			if allowErr := s.AllowFuture(req.FromSession, req.ToSession, req.FromPath, req.ToPath); allowErr != nil {
+		if err == nil && remember != nil {
+			if allowErr := s.Allow(approval); allowErr != nil {
 				log.Printf("warning: remember ask approval %s failed: %v", req.ID, allowErr)
 			}
 		}
@@ -206,13 +279,37 @@ func (s *Store) Pending() ([]*Request, error) {
 	return pending, nil
 }
 
+// IsAllowed reports whether an unexpired approval covers asks from one
+// session to another: one for exactly this session pair, or one for their
+// projects.
 func (s *Store) IsAllowed(fromSession, toSession, fromPath, toPath string) (bool, error) {
 	approvals, err := s.loadApprovals()
 	if err != nil {
 		return false, err
 	}
+	now := time.Now()
+	var fromProject, toProject string
+	projectsLoaded := false
 	for _, approval := range approvals.Approvals {
-		if approval.FromSession == fromSession && approval.ToSession == toSession && approval.FromPath == fromPath && approval.ToPath == toPath {
+		if approval.Expired(now) {
+			continue
+		}
+		if !approval.IsProject() {
+			if approval.FromSession == fromSession && approval.ToSession == toSession && approval.FromPath == fromPath && approval.ToPath == toPath {
+				return true, nil
+			}
+			continue
+		}
+		if !projectsLoaded {
+			projectsLoaded = true
+			sessions, err := session.LoadSessions()
+			if err != nil {
+				return false, err
+			}
+			fromProject = sessionProject(sessions, fromSession, fromPath)
+			toProject = sessionProject(sessions, toSession, toPath)
+		}
+		if fromProject != "" && toProject != "" && approval.FromProject == fromProject && approval.ToProject == toProject {
 			return true, nil
 		}
 	}
@@ -220,17 +317,35 @@ func (s *Store) IsAllowed(fromSession, toSession, fromPath, toPath string) (bool
 }
 
 func (s *Store) AllowFuture(fromSession, toSession, fromPath, toPath string) error {
+	return s.Allow(Approval{FromSession: fromSession, ToSession: toSession, FromPath: fromPath, ToPath: toPath})
+}
+
+// Allow remembers an approval, replacing any for the same pair so a repeated
+// approval renews its expiry. Expired approvals are dropped on the way.
+func (s *Store) Allow(approval Approval) error {
+	if approval.IsProject() {
+		if approval.FromProject == "" || approval.ToProject == "" {
+			return fmt.Errorf("project approvals need both projects")
+		}
+	} else if approval.FromSession == "" || approval.ToSession == "" {
+		return fmt.Errorf("approvals need both sessions")
+	}
+	if approval.CreatedAt.IsZero() {
+		approval.CreatedAt = time.Now().UTC()
+	}
 	return s.withApprovalsLock(func() error {
 		approvals, err := s.loadApprovals()
 		if err != nil {
 			return err
 		}
-		for _, approval := range approvals.Approvals {
-			if approval.FromSession == fromSession && approval.ToSession == toSession && approval.FromPath == fromPath && approval.ToPath == toPath {
-				return nil
+		now := time.Now()
+		kept := approvals.Approvals[:0]
+		for _, existing := range approvals.Approvals {
+			if !existing.Expired(now) && !existing.samePair(approval) {
+				kept = append(kept, existing)
 			}
 		}
-		approvals.Approvals = append(approvals.Approvals, Approval{FromSession: fromSession, ToSession: toSession, FromPath: fromPath, ToPath: toPath, CreatedAt: time.Now().UTC()})
+		approvals.Approvals = append(kept, approval)
 		return s.saveApprovals(approvals)
 	})
 }
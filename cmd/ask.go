@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/jfox85/devx/ask"
+	"github.com/jfox85/devx/internal/duration"
 	"github.com/jfox85/devx/session"
 	"github.com/spf13/cobra"
 )
@@ -16,12 +18,24 @@ var askJSON bool
 var askNoAgent bool
 var askTimeout string
 var askApproveAlways bool
+var askTo string
+var askToTag string
+var askParallel int
+var askApproveFor time.Duration
+var askApproveProjects bool
 
 var askCmd = &cobra.Command{
 	Use:   "ask <session> <question>",
 	Short: "Ask another DevX session a question",
-	Args:  cobra.MinimumNArgs(2),
-	RunE:  runAsk,
+	Long: `Ask another DevX session a question.
+
+With --to or --to-tag the question is broadcast to every matching session
+instead. Each target gets its own request in the usual approval flow, approved
+responders run in parallel, and the answers are aggregated into one document.
+A broadcast id (bc_...) can be passed to read, approve and deny to act on the
+whole batch.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAsk,
 }
 
 var askPendingCmd = &cobra.Command{
@@ -53,6 +67,13 @@ var askReadCmd = &cobra.Command{
 	Short: "Read an ask request",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if ask.IsBroadcastID(args[0]) {
+			reqs, err := ask.NewStore().Broadcast(args[0])
+			if err != nil {
+				return err
+			}
+			return printAskBroadcast(cmd, reqs)
+		}
 		req, err := ask.NewStore().Get(args[0])
 		if err != nil {
 			return fmt.Errorf("read ask %s: %w", args[0], err)
@@ -77,10 +98,32 @@ var askApproveCmd = &cobra.Command{
 			policy = ask.EffectivePolicy()
 			policy.Timeout = d
 		}
+		if askParallel > 0 {
+			if policy.Command == "" {
+				policy = ask.EffectivePolicy()
+			}
+			policy.Parallel = askParallel
+		}
+		remember, err := askApproveRemember()
+		if err != nil {
+			return err
+		}
+		if ask.IsBroadcastID(args[0]) {
+			reqs, err := store.ApproveBroadcast(ctx, args[0], policy, remember)
+			if reqs == nil {
+				return fmt.Errorf("approve broadcast %s: %w", args[0], err)
+			}
+			if printErr := printAskBroadcast(cmd, reqs); printErr != nil {
+				return printErr
+			}
+			if err != nil {
+				return fmt.Errorf("approve broadcast %s: %w", args[0], err)
+			}
+			return nil
+		}
 		var req *ask.Request
-		var err error
-		if askApproveAlways {
-			req, err = store.ApproveAlwaysAndExecute(ctx, args[0], policy)
+		if remember != nil {
+			req, err = store.ApproveRememberAndExecute(ctx, args[0], policy, *remember)
 		} else {
 			req, err = store.ApproveAndExecute(ctx, args[0], policy)
 		}
@@ -91,12 +134,64 @@ var askApproveCmd = &cobra.Command{
 	},
 }
 
+// askApproveRemember turns the --always, --for and --projects flags of
+// approve into how the approval is kept, or nil for a one-off approval.
+func askApproveRemember() (*ask.Remember, error) {
+	if !askApproveAlways {
+		if askApproveFor != 0 || askApproveProjects {
+			return nil, fmt.Errorf("--for and --projects only apply with --always")
+		}
+		return nil, nil
+	}
+	return &ask.Remember{Projects: askApproveProjects, For: askApproveFor}, nil
+}
+
+var askReplyCmd = &cobra.Command{
+	Use:   "reply <request-id> <question>",
+	Short: "Ask a follow-up question in the thread of an answered ask",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runAskReply,
+}
+
+var askThreadCmd = &cobra.Command{
+	Use:   "thread <request-id>",
+	Short: "Print the whole conversation an ask belongs to",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reqs, err := ask.NewStore().Thread(args[0])
+		if err != nil {
+			return fmt.Errorf("read ask thread %s: %w", args[0], err)
+		}
+		if askJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(reqs)
+		}
+		for i, req := range reqs {
+			if i > 0 {
+				fmt.Fprintln(cmd.OutOrStdout())
+			}
+			if err := printAsk(cmd, req); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 var askDenyCmd = &cobra.Command{
 	Use:   "deny <request-id>",
 	Short: "Deny a pending ask",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		store := ask.NewStore()
+		if ask.IsBroadcastID(args[0]) {
+			reqs, err := store.DenyBroadcast(args[0])
+			if err != nil {
+				return fmt.Errorf("deny broadcast %s: %w", args[0], err)
+			}
+			return printAskBroadcast(cmd, reqs)
+		}
 		req, err := store.Deny(args[0])
 		if err != nil {
 			return fmt.Errorf("deny ask %s: %w", args[0], err)
@@ -106,6 +201,12 @@ var askDenyCmd = &cobra.Command{
 }
 
 func runAsk(cmd *cobra.Command, args []string) error {
+	if askTo != "" || askToTag != "" {
+		return runAskBroadcast(cmd, args)
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("usage: devx ask <session> <question>, or devx ask --to <selector> <question>")
+	}
 	targetName := args[0]
 	question := strings.TrimSpace(strings.Join(args[1:], " "))
 	if question == "" {
@@ -134,12 +235,126 @@ func runAsk(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("create ask: %w", err)
 	}
+	return dispatchAsk(cmd, askStore, req, target)
+}
+
+func runAskBroadcast(cmd *cobra.Command, args []string) error {
+	if askTo != "" && askToTag != "" {
+		return fmt.Errorf("use either --to or --to-tag, not both")
+	}
+	selector := askTo
+	if askToTag != "" {
+		selector = "tag:" + askToTag
+	}
+	question := strings.TrimSpace(strings.Join(args, " "))
+	if question == "" {
+		return fmt.Errorf("question is required")
+	}
+	sessions, err := session.LoadSessions()
+	if err != nil {
+		return fmt.Errorf("load sessions: %w", err)
+	}
+	fromName := session.GetCurrentSessionName()
+	if fromName == "" {
+		return fmt.Errorf("devx ask must be run from inside a DevX session so approvals have a requester identity")
+	}
+	fromPath := ""
+	if from, ok := sessions.GetSession(fromName); ok {
+		fromPath = from.Path
+	}
+	targets, err := ask.SelectTargets(sessions.Sessions, selector, fromName)
+	if err != nil {
+		return err
+	}
+	askStore := ask.NewStore()
+	reqs, err := askStore.CreateBroadcast(fromName, fromPath, targets, question)
+	if err != nil {
+		return fmt.Errorf("create broadcast: %w", err)
+	}
+	if askNoAgent {
+		return printAskBroadcast(cmd, reqs)
+	}
+
+	policy := ask.EffectivePolicy()
+	if askParallel > 0 {
+		policy.Parallel = askParallel
+	}
+	var run func(*ask.Request) error
+	switch {
+	case !policy.Enabled || policy.Mode == "none":
+	case policy.Mode == "approval":
+		// Only pairs approved with --always run now; the rest wait.
+		run = func(req *ask.Request) error {
+			allowed, err := askStore.IsAllowed(req.FromSession, req.ToSession, req.FromPath, req.ToPath)
+			if err != nil || !allowed {
+				return err
+			}
+			_, err = askStore.ApproveAndExecute(context.Background(), req.ID, policy)
+			return err
+		}
+	case policy.Mode == "always":
+		run = func(req *ask.Request) error {
+			_, err := ask.Execute(context.Background(), req, sessions.Sessions[req.ToSession], ask.ExecuteOptions{Policy: policy, Store: askStore})
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported agent_responder.mode %q", policy.Mode)
+	}
+	var runErr error
+	if run != nil {
+		runErr = ask.RunParallel(reqs, policy.Parallel, run)
+	}
+	if reqs, err = askStore.Broadcast(reqs[0].BroadcastID); err != nil {
+		return err
+	}
+	if err := printAskBroadcast(cmd, reqs); err != nil {
+		return err
+	}
+	if runErr != nil {
+		return fmt.Errorf("broadcast %s: %w", reqs[0].BroadcastID, runErr)
+	}
+	return nil
+}
+
+func runAskReply(cmd *cobra.Command, args []string) error {
+	question := strings.TrimSpace(strings.Join(args[1:], " "))
+	if question == "" {
+		return fmt.Errorf("question is required")
+	}
+	askStore := ask.NewStore()
+	parent, err := askStore.Get(args[0])
+	if err != nil {
+		return err
+	}
+	// Follow-ups inherit the requester identity, so only the session that
+	// started the thread may continue it.
+	if fromName := session.GetCurrentSessionName(); fromName == "" || fromName != parent.FromSession {
+		return fmt.Errorf("devx ask reply must be run from session %q, which started this thread", parent.FromSession)
+	}
+	sessions, err := session.LoadSessions()
+	if err != nil {
+		return fmt.Errorf("load sessions: %w", err)
+	}
+	target, ok := sessions.GetSession(parent.ToSession)
+	if !ok {
+		return fmt.Errorf("session %q not found", parent.ToSession)
+	}
+	req, err := askStore.Reply(parent.ID, question)
+	if err != nil {
+		return fmt.Errorf("reply to ask %s: %w", parent.ID, err)
+	}
+	return dispatchAsk(cmd, askStore, req, target)
+}
+
+// dispatchAsk runs the responder for a new ask as the agent_responder policy
+// allows, or leaves it pending approval.
+func dispatchAsk(cmd *cobra.Command, askStore *ask.Store, req *ask.Request, target *session.Session) error {
 	if askNoAgent {
 		return printAsk(cmd, req)
 	}
 	policy := ask.EffectivePolicy()
 	if policy.Enabled && policy.Mode == "approval" {
-		allowed, err := askStore.IsAllowed(fromName, targetName, fromPath, target.Path)
+		allowed, err := askStore.IsAllowed(req.FromSession, req.ToSession, req.FromPath, req.ToPath)
 		if err != nil {
 			return fmt.Errorf("check ask approval allowance: %w", err)
 		}
@@ -151,13 +366,13 @@ func runAsk(cmd *cobra.Command, args []string) error {
 			return printAsk(cmd, req)
 		}
 	}
-	if askNoAgent || !policy.Enabled || policy.Mode == "none" || policy.Mode == "approval" {
+	if !policy.Enabled || policy.Mode == "none" || policy.Mode == "approval" {
 		return printAsk(cmd, req)
 	}
 	if policy.Mode != "always" {
 		return fmt.Errorf("unsupported agent_responder.mode %q", policy.Mode)
 	}
-	req, err = ask.Execute(context.Background(), req, target, ask.ExecuteOptions{Policy: policy, Store: askStore})
+	req, err := ask.Execute(context.Background(), req, target, ask.ExecuteOptions{Policy: policy, Store: askStore})
 	if err != nil {
 		return fmt.Errorf("execute ask %s: %w", req.ID, err)
 	}
@@ -171,6 +386,9 @@ func printAsk(cmd *cobra.Command, req *ask.Request) error {
 		return enc.Encode(req)
 	}
 	fmt.Fprintf(cmd.OutOrStdout(), "Ask %s: %s -> %s [%s]\n", req.ID, req.FromSession, req.ToSession, req.Status)
+	if req.ParentID != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "Follow-up to: %s (thread %s)\n", req.ParentID, req.Thread())
+	}
 	fmt.Fprintf(cmd.OutOrStdout(), "Question: %s\n", req.Question)
 	if req.Response != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "\n%s\n", req.Response.Body)
@@ -181,23 +399,75 @@ func printAsk(cmd *cobra.Command, req *ask.Request) error {
 	return nil
 }
 
+func printAskBroadcast(cmd *cobra.Command, reqs []*ask.Request) error {
+	if len(reqs) == 0 {
+		return nil
+	}
+	if askJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]any{
+			"broadcast_id": reqs[0].BroadcastID,
+			"status":       ask.BroadcastStatus(reqs),
+			"document":     ask.RenderBroadcast(reqs),
+			"requests":     reqs,
+		})
+	}
+	fmt.Fprint(cmd.OutOrStdout(), ask.RenderBroadcast(reqs))
+	return nil
+}
+
 func printAskList(cmd *cobra.Command, reqs []*ask.Request) error {
 	if askJSON {
 		enc := json.NewEncoder(cmd.OutOrStdout())
 		enc.SetIndent("", "  ")
 		return enc.Encode(reqs)
 	}
-	for _, req := range reqs {
-		fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s -> %s\t%s\t%s\n", req.ID, req.FromSession, req.ToSession, req.Status, req.Question)
+	for _, thread := range groupAskThreads(reqs) {
+		for i, req := range thread {
+			indent := ""
+			if i > 0 {
+				indent = "  └ "
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s%s\t%s -> %s\t%s\t%s\n", indent, req.ID, req.FromSession, req.ToSession, req.Status, req.Question)
+		}
 	}
 	return nil
 }
 
+// groupAskThreads groups reqs by thread, keeping the order in which threads
+// first appear in reqs and listing each thread's requests oldest first.
+func groupAskThreads(reqs []*ask.Request) [][]*ask.Request {
+	var order []string
+	byThread := map[string][]*ask.Request{}
+	for _, req := range reqs {
+		id := req.Thread()
+		if _, ok := byThread[id]; !ok {
+			order = append(order, id)
+		}
+		byThread[id] = append(byThread[id], req)
+	}
+	out := make([][]*ask.Request, 0, len(order))
+	for _, id := range order {
+		thread := byThread[id]
+		sort.SliceStable(thread, func(i, j int) bool { return thread[i].CreatedAt.Before(thread[j].CreatedAt) })
+		out = append(out, thread)
+	}
+	return out
+}
+
 func init() {
 	rootCmd.AddCommand(askCmd)
 	askCmd.PersistentFlags().BoolVar(&askJSON, "json", false, "Output JSON")
 	askCmd.Flags().BoolVar(&askNoAgent, "no-agent", false, "Create the ask without running a responder")
-	askCmd.AddCommand(askPendingCmd, askListCmd, askReadCmd, askApproveCmd, askDenyCmd)
+	askCmd.Flags().StringVar(&askTo, "to", "", "Broadcast to every session matching project:<alias>, tag:<tag> or a session name")
+	askCmd.Flags().StringVar(&askToTag, "to-tag", "", "Broadcast to every session with this tag")
+	askCmd.Flags().IntVar(&askParallel, "parallel", 0, "Responders a broadcast runs at once (default agent_responder.parallel)")
+	askCmd.AddCommand(askPendingCmd, askListCmd, askReadCmd, askApproveCmd, askDenyCmd, askReplyCmd, askThreadCmd)
+	askReplyCmd.Flags().BoolVar(&askNoAgent, "no-agent", false, "Create the follow-up without running a responder")
 	askApproveCmd.Flags().BoolVar(&askApproveAlways, "always", false, "Approve this ask and remember this requester/target pair for future asks")
 	askApproveCmd.Flags().StringVar(&askTimeout, "timeout", "", "Override responder timeout for approve")
+	askApproveCmd.Flags().IntVar(&askParallel, "parallel", 0, "Responders an approved broadcast runs at once")
+	askApproveCmd.Flags().Var(duration.NewFlag(&askApproveFor), "for", "With --always, let the approval expire after this long, e.g. 12h or 7d")
+	askApproveCmd.Flags().BoolVar(&askApproveProjects, "projects", false, "With --always, approve any session of the requester's project asking any session of the target's project")
 }
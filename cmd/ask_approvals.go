@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jfox85/devx/ask"
+	"github.com/spf13/cobra"
+)
+
+var askApprovalsCmd = &cobra.Command{
+	Use:   "approvals",
+	Short: "Manage remembered ask approvals",
+	Long: `Manage remembered ask approvals.
+
+An approval lets one session's asks to another run without a prompt. It covers
+a session pair, or with project:<alias> on both sides any session of one
+project asking any session of the other. Approvals can expire. They are
+created only by approving an ask with --always.`,
+}
+
+var askApprovalsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List remembered ask approvals",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		approvals, err := ask.NewStore().Approvals()
+		if err != nil {
+			return fmt.Errorf("load ask approvals: %w", err)
+		}
+		if askJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(approvals.Approvals)
+		}
+		if len(approvals.Approvals) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No ask approvals.")
+			return nil
+		}
+		now := time.Now()
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FROM\tTO\tEXPIRES\tCREATED")
+		for _, a := range approvals.Approvals {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.From(), a.To(), approvalExpiry(a, now), a.CreatedAt.Local().Format("2006-01-02 15:04"))
+		}
+		return w.Flush()
+	},
+}
+
+var askApprovalsRevokeCmd = &cobra.Command{
+	Use:   "revoke <from> <to>",
+	Short: "Revoke remembered approvals from one session or project to another",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := ask.NewStore().Revoke(args[0], args[1])
+		if err != nil {
+			return fmt.Errorf("revoke ask approval: %w", err)
+		}
+		if n == 0 {
+			return fmt.Errorf("no ask approval from %s to %s", args[0], args[1])
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Revoked %d ask approval(s) from %s to %s\n", n, args[0], args[1])
+		return nil
+	},
+}
+
+// approvalExpiry describes when an approval runs out.
+func approvalExpiry(a ask.Approval, now time.Time) string {
+	switch {
+	case a.ExpiresAt == nil:
+		return "never"
+	case a.Expired(now):
+		return "expired"
+	}
+	return a.ExpiresAt.Local().Format("2006-01-02 15:04")
+}
+
+func init() {
+	askApprovalsCmd.AddCommand(askApprovalsListCmd, askApprovalsRevokeCmd)
+	askCmd.AddCommand(askApprovalsCmd)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/jfox85/devx/config"
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage shared cache volumes",
+	Long: `Manage the named volumes backing docker.caches.
+
+Each configured cache (for example the Go module or npm cache) is a volume
+named devx-cache-<project>-<name>, shared by all of the project's container
+sessions so dependencies are downloaded once.`,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// cacheSpecs converts docker.caches entries to target cache specs.
+func cacheSpecs(caches []config.CacheConfig) []target.CacheSpec {
+	specs := make([]target.CacheSpec, 0, len(caches))
+	for _, c := range caches {
+		specs = append(specs, target.CacheSpec{Name: c.Name, Path: c.Path, Gatepost: c.Gatepost})
+	}
+	return specs
+}
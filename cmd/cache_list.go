@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+)
+
+var cacheListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List shared cache volumes",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		volumes, err := target.ListCacheVolumes(context.Background())
+		if err != nil {
+			return err
+		}
+		if len(volumes) == 0 {
+			fmt.Println("No devx cache volumes found.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "VOLUME\tPROJECT\tCACHE\tRUNTIME\tSIZE\tCONTAINERS\n")
+		fmt.Fprintf(w, "------\t-------\t-----\t-------\t----\t----------\n")
+		for _, v := range volumes {
+			size := v.Size
+			if size == "" {
+				size = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", v.Name, v.Project, v.Cache, v.CLI, size, cacheLinks(v))
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheListCmd)
+}
+
+func cacheLinks(v target.CacheVolume) string {
+	if v.Links < 0 {
+		return "-"
+	}
+	return strconv.Itoa(v.Links)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cachePruneProject string
+	cachePruneDryRun  bool
+)
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cache volumes no container uses",
+	Long: `Remove devx cache volumes that are not mounted by any container. Running
+sessions keep their caches; the next session recreates removed caches empty.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		volumes, err := target.ListCacheVolumes(ctx)
+		if err != nil {
+			return err
+		}
+		candidates := cachePruneCandidates(volumes, cachePruneProject)
+		if len(candidates) == 0 {
+			fmt.Println("No unused devx cache volumes.")
+			return nil
+		}
+		var failed int
+		for _, v := range candidates {
+			size := v.Size
+			if size == "" {
+				size = "unknown size"
+			}
+			if cachePruneDryRun {
+				fmt.Printf("Would remove %s (%s)\n", v.Name, size)
+				continue
+			}
+			if err := target.RemoveCacheVolume(ctx, v); err != nil {
+				fmt.Printf("Warning: failed to remove %s: %v\n", v.Name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Removed %s (%s)\n", v.Name, size)
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to remove %d cache volume(s)", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	cachePruneCmd.Flags().StringVarP(&cachePruneProject, "project", "p", "", "Only prune caches of this project")
+	cachePruneCmd.Flags().BoolVar(&cachePruneDryRun, "dry-run", false, "List the volumes that would be removed")
+	cacheCmd.AddCommand(cachePruneCmd)
+}
+
+// cachePruneCandidates returns the volumes of project (all projects when
+// empty) that no container mounts. Volumes with an unknown container count
+// are included; the runtime refuses to remove a volume still in use.
+func cachePruneCandidates(volumes []target.CacheVolume, project string) []target.CacheVolume {
+	var out []target.CacheVolume
+	for _, v := range volumes {
+		if project != "" && v.Project != project {
+			continue
+		}
+		if v.Links > 0 {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
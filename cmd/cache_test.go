@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jfox85/devx/target"
+)
+
+func TestCachePruneCandidatesSkipsMountedVolumes(t *testing.T) {
+	volumes := []target.CacheVolume{
+		{Name: "devx-cache-app-gomod", Project: "app", Links: 1},
+		{Name: "devx-cache-app-npm", Project: "app", Links: 0},
+		{Name: "devx-cache-web-npm", Project: "web", Links: -1},
+	}
+	var names []string
+	for _, v := range cachePruneCandidates(volumes, "") {
+		names = append(names, v.Name)
+	}
+	if len(names) != 2 || names[0] != "devx-cache-app-npm" || names[1] != "devx-cache-web-npm" {
+		t.Errorf("candidates = %v", names)
+	}
+	if got := cachePruneCandidates(volumes, "web"); len(got) != 1 || got[0].Name != "devx-cache-web-npm" {
+		t.Errorf("project filter: %+v", got)
+	}
+}
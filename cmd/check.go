@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/jfox85/devx/deps"
+	"github.com/jfox85/devx/target"
 	"github.com/spf13/cobra"
 )
 
@@ -20,4 +24,7 @@ func runCheck(cmd *cobra.Command, args []string) {
 	results := deps.CheckAllDependencies()
 	editorResult := deps.CheckConfiguredEditor()
 	deps.PrintResults(results, editorResult)
+	if plugins := target.ListPlugins(); len(plugins) > 0 {
+		fmt.Printf("\nTarget plugins: %s\n", strings.Join(plugins, ", "))
+	}
 }
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jfox85/devx/egress"
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+)
+
+var (
+	egressProxyListenFlag  string
+	egressProxyPolicyFlag  string
+	egressProxyPhaseFlag   string
+	egressProxyAuditFlag   string
+	egressProxyForwardFlag []string
+)
+
+// egressProxyCmd runs inside the proxy container of a docker session with
+// network: allowlist. It is not meant to be run by hand.
+var egressProxyCmd = &cobra.Command{
+	Use:    "egress-proxy",
+	Short:  "Run the filtering egress proxy for a docker session",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runEgressProxy,
+}
+
+func init() {
+	egressProxyCmd.Flags().StringVar(&egressProxyListenFlag, "listen", ":3128", "Proxy listen address")
+	egressProxyCmd.Flags().StringVar(&egressProxyPolicyFlag, "policy", "", "Gatepost policy file")
+	egressProxyCmd.Flags().StringVar(&egressProxyPhaseFlag, "phase", "run", "Policy phase to enforce")
+	egressProxyCmd.Flags().StringVar(&egressProxyAuditFlag, "audit", "", "Append decisions to this JSONL file")
+	egressProxyCmd.Flags().StringArrayVar(&egressProxyForwardFlag, "forward", nil, "Relay a port to the session, e.g. 3000=devx-feat:3000")
+	rootCmd.AddCommand(egressProxyCmd)
+}
+
+func runEgressProxy(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(egressProxyPolicyFlag)
+	if err != nil {
+		return fmt.Errorf("read policy: %w", err)
+	}
+	policy, err := target.ParseGatepostPolicy(data)
+	if err != nil {
+		return fmt.Errorf("parse policy: %w", err)
+	}
+	var audit io.Writer
+	if egressProxyAuditFlag != "" {
+		f, err := os.OpenFile(egressProxyAuditFlag, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("open audit log: %w", err)
+		}
+		defer f.Close()
+		audit = f
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, len(egressProxyForwardFlag)+1)
+	for _, fwd := range egressProxyForwardFlag {
+		port, addr, ok := strings.Cut(fwd, "=")
+		if !ok || port == "" || addr == "" {
+			return fmt.Errorf("--forward %q: want <port>=<host>:<port>", fwd)
+		}
+		ln, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			return fmt.Errorf("--forward %s: %w", fwd, err)
+		}
+		go func() { errCh <- egress.Relay(ctx, ln, addr) }()
+	}
+
+	srv := &http.Server{
+		Addr:              egressProxyListenFlag,
+		Handler:           &egress.Proxy{Policy: policy, Phase: egressProxyPhaseFlag, Audit: audit},
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	go func() { errCh <- srv.ListenAndServe() }()
+	fmt.Fprintf(cmd.OutOrStdout(), "devx egress proxy listening on %s (%s phase)\n", egressProxyListenFlag, egressProxyPhaseFlag)
+
+	select {
+	case <-ctx.Done():
+	case err := <-errCh:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jfox85/devx/config"
+	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+)
+
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Manage session images built by devx",
+	Long: `Manage the container images devx builds for sessions.
+
+Projects with docker.build configured get images tagged with a hash of their
+Dockerfile, build args and build context as committed in the project checkout,
+so a changed input produces a new image on the next session create and old
+images can be pruned.`,
+}
+
+func init() {
+	rootCmd.AddCommand(imageCmd)
+}
+
+// imageProjectName is the name a project's images are tagged with: its
+// registry alias, or the checkout directory name for unregistered projects.
+func imageProjectName(projectAlias, projectPath string) string {
+	if projectAlias != "" {
+		return projectAlias
+	}
+	return filepath.Base(projectPath)
+}
+
+// projectImageSpec resolves a project's docker.build settings against the
+// project checkout. Session worktrees are never used: the files devx
+// generates in them would give every session its own image.
+func projectImageSpec(project, projectPath string, build config.DockerBuildConfig) (target.ImageBuildSpec, error) {
+	return target.NewImageBuildSpec(project, projectPath, build.Context, build.Dockerfile, build.Args)
+}
+
+// currentProjectImage returns the tag a project's current build inputs hash
+// to.
+func currentProjectImage(project, projectPath string, build config.DockerBuildConfig) (string, error) {
+	spec, err := projectImageSpec(project, projectPath, build)
+	if err != nil {
+		return "", err
+	}
+	hash, err := target.ImageInputsHash(spec)
+	if err != nil {
+		return "", err
+	}
+	return target.ProjectImageTag(project, hash), nil
+}
+
+// projectOrGlobalConfig loads a project's .devx/config.yaml, falling back to
+// the global config like session create does.
+func projectOrGlobalConfig(projectPath string) (*config.Config, error) {
+	if projectPath != "" {
+		cfg, err := config.GetProjectConfig(projectPath)
+		if err != nil {
+			return nil, err
+		}
+		if cfg != nil {
+			return cfg, nil
+		}
+	}
+	return config.LoadConfig()
+}
+
+// currentProjectImages returns, for every registered project with
+// docker.build configured, the tag its current build inputs hash to.
+func currentProjectImages(registry *config.ProjectRegistry) map[string]string {
+	current := make(map[string]string)
+	if registry == nil {
+		return current
+	}
+	for alias, project := range registry.Projects {
+		cfg, err := projectOrGlobalConfig(project.Path)
+		if err != nil || !cfg.Docker.Build.Enabled() {
+			continue
+		}
+		tag, err := currentProjectImage(alias, project.Path, cfg.Docker.Build)
+		if err != nil {
+			continue
+		}
+		current[alias] = tag
+	}
+	return current
+}
+
+// sessionsByImage maps each image reference to the sessions running it.
+func sessionsByImage(store *session.SessionStore) map[string][]string {
+	users := make(map[string][]string)
+	for name, sess := range store.Sessions {
+		if sess.Target.Image != "" {
+			users[sess.Target.Image] = append(users[sess.Target.Image], name)
+		}
+	}
+	for _, names := range users {
+		sort.Strings(names)
+	}
+	return users
+}
+
+// sessionImageOutdated reports whether a session runs a project image other
+// than current, the tag of its project's current build inputs.
+func sessionImageOutdated(sess *session.Session, project, current string) bool {
+	if current == "" || sess.Target.Image == "" {
+		return false
+	}
+	repo := target.ProjectImageRepo(project)
+	if !strings.HasPrefix(sess.Target.Image, repo+":") {
+		return false // not a project image (explicit --image or devcontainer)
+	}
+	return sess.Target.Image != current
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jfox85/devx/config"
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+)
+
+var imageBuildProjectFlag string
+
+var imageBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build the project's session image if its inputs changed",
+	Long: `Build the session image described by the project's docker.build config.
+
+The image is tagged with a hash of the Dockerfile, build args and build
+context as committed in the project checkout. If an image with that tag
+already exists nothing is rebuilt.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		alias, projectPath, err := resolveImageProject(imageBuildProjectFlag)
+		if err != nil {
+			return err
+		}
+		cfg, err := projectOrGlobalConfig(projectPath)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if !cfg.Docker.Build.Enabled() {
+			return fmt.Errorf("no docker.build configured for %s", projectPath)
+		}
+		if err := target.CheckAvailable(); err != nil {
+			return err
+		}
+		spec, err := projectImageSpec(imageProjectName(alias, projectPath), projectPath, cfg.Docker.Build)
+		if err != nil {
+			return err
+		}
+		tag, built, err := target.EnsureProjectImage(context.Background(), spec)
+		if err != nil {
+			return err
+		}
+		if built {
+			fmt.Printf("Built %s\n", tag)
+		} else {
+			fmt.Printf("%s is up to date\n", tag)
+		}
+		return nil
+	},
+}
+
+func init() {
+	imageBuildCmd.Flags().StringVarP(&imageBuildProjectFlag, "project", "p", "", "Project alias (defaults to current directory's project)")
+	imageCmd.AddCommand(imageBuildCmd)
+}
+
+// resolveImageProject finds the project to build for: the --project alias, or
+// the registered project containing the current directory, or the current
+// directory itself.
+func resolveImageProject(alias string) (string, string, error) {
+	registry, err := config.LoadProjectRegistry()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load project registry: %w", err)
+	}
+	if alias != "" {
+		project, err := registry.GetProject(alias)
+		if err != nil {
+			return "", "", fmt.Errorf("project '%s' not found", alias)
+		}
+		return alias, project.Path, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	for a, project := range registry.Projects {
+		if strings.HasPrefix(cwd, project.Path) {
+			return a, project.Path, nil
+		}
+	}
+	return "", cwd, nil
+}
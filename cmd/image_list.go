@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jfox85/devx/config"
+	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+)
+
+var imageListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List images built by devx and the sessions using them",
+	Aliases: []string{"ls"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := target.CheckAvailable(); err != nil {
+			return err
+		}
+		images, err := target.ListDevxImages(context.Background())
+		if err != nil {
+			return err
+		}
+		if len(images) == 0 {
+			fmt.Println("No devx images found.")
+			return nil
+		}
+		store, err := session.LoadSessions()
+		if err != nil {
+			return fmt.Errorf("failed to load sessions: %w", err)
+		}
+		registry, _ := config.LoadProjectRegistry()
+		current := currentProjectImages(registry)
+		users := sessionsByImage(store)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "IMAGE\tKIND\tPROJECT\tSTATUS\tSIZE\tCREATED\tSESSIONS\n")
+		fmt.Fprintf(w, "-----\t----\t-------\t------\t----\t-------\t--------\n")
+		for _, img := range images {
+			sessions := "-"
+			if names := users[img.Ref]; len(names) > 0 {
+				sessions = strings.Join(names, ",")
+			}
+			project := img.Project
+			if project == "" {
+				project = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				img.Ref, img.Kind, project, imageStatus(img, current), img.Size, img.CreatedSince, sessions)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	imageCmd.AddCommand(imageListCmd)
+}
+
+// imageStatus is "current" for the image matching a project's present build
+// inputs, "outdated" for older project images and "-" otherwise.
+func imageStatus(img target.DevxImage, current map[string]string) string {
+	if img.Kind != "project" {
+		return "-"
+	}
+	tag, ok := current[img.Project]
+	if !ok {
+		return "-"
+	}
+	if tag == img.Ref {
+		return "current"
+	}
+	return "outdated"
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jfox85/devx/config"
+	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+)
+
+var imagePruneDryRun bool
+
+var imagePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove devx images no session uses",
+	Long: `Remove images built by devx that no session is running and that are not
+the current image of a registered project.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := target.CheckAvailable(); err != nil {
+			return err
+		}
+		ctx := context.Background()
+		images, err := target.ListDevxImages(ctx)
+		if err != nil {
+			return err
+		}
+		store, err := session.LoadSessions()
+		if err != nil {
+			return fmt.Errorf("failed to load sessions: %w", err)
+		}
+		registry, _ := config.LoadProjectRegistry()
+
+		candidates := pruneCandidates(images, sessionsByImage(store), currentProjectImages(registry))
+		if len(candidates) == 0 {
+			fmt.Println("No unused devx images.")
+			return nil
+		}
+		var failed int
+		for _, img := range candidates {
+			if imagePruneDryRun {
+				fmt.Printf("Would remove %s (%s)\n", img.Ref, img.Size)
+				continue
+			}
+			if err := target.RemoveImage(ctx, img.Ref); err != nil {
+				fmt.Printf("Warning: failed to remove %s: %v\n", img.Ref, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Removed %s (%s)\n", img.Ref, img.Size)
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to remove %d image(s)", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	imagePruneCmd.Flags().BoolVar(&imagePruneDryRun, "dry-run", false, "List the images that would be removed")
+	imageCmd.AddCommand(imagePruneCmd)
+}
+
+// pruneCandidates returns the images that are neither used by a session nor
+// a project's current image.
+func pruneCandidates(images []target.DevxImage, users map[string][]string, current map[string]string) []target.DevxImage {
+	keep := make(map[string]bool)
+	for _, tag := range current {
+		keep[tag] = true
+	}
+	var out []target.DevxImage
+	for _, img := range images {
+		if len(users[img.Ref]) > 0 || keep[img.Ref] {
+			continue
+		}
+		out = append(out, img)
+	}
+	return out
+}
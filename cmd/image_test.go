@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfox85/devx/config"
+	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
+)
+
+func TestPruneCandidatesKeepsUsedAndCurrentImages(t *testing.T) {
+	images := []target.DevxImage{
+		{Ref: "devx-app:old", Kind: "project", Project: "app"},
+		{Ref: "devx-app:inuse", Kind: "project", Project: "app"},
+		{Ref: "devx-app:current", Kind: "project", Project: "app"},
+		{Ref: "devx-devcontainer:abc", Kind: "devcontainer"},
+	}
+	users := map[string][]string{"devx-app:inuse": {"feat"}}
+	current := map[string]string{"app": "devx-app:current"}
+
+	got := pruneCandidates(images, users, current)
+	if len(got) != 2 || got[0].Ref != "devx-app:old" || got[1].Ref != "devx-devcontainer:abc" {
+		t.Fatalf("prune candidates = %+v", got)
+	}
+	if s := imageStatus(images[0], current); s != "outdated" {
+		t.Errorf("status of old image = %q", s)
+	}
+	if s := imageStatus(images[2], current); s != "current" {
+		t.Errorf("status of current image = %q", s)
+	}
+	if s := imageStatus(images[3], current); s != "-" {
+		t.Errorf("status of devcontainer image = %q", s)
+	}
+}
+
+func TestSessionImageOutdated(t *testing.T) {
+	project := initTempRepo(t)
+	build := config.DockerBuildConfig{Context: "."}
+	commit := func(dockerfile string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(project, "Dockerfile"), []byte(dockerfile), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{{"add", "Dockerfile"}, {"commit", "-m", "Dockerfile"}} {
+			if out, err := exec.Command("git", append([]string{"-C", project}, args...)...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+		}
+	}
+	commit("FROM alpine\n")
+	current, err := currentProjectImage("app", project, build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess := &session.Session{Path: t.TempDir(), ProjectPath: project, Target: session.TargetMeta{Type: "docker", Image: current}}
+	if sessionImageOutdated(sess, "app", current) {
+		t.Fatal("image matching the project should be current")
+	}
+
+	// Uncommitted edits do not change the project's image.
+	if err := os.WriteFile(filepath.Join(project, "Dockerfile"), []byte("FROM debian\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := currentProjectImage("app", project, build); err != nil || got != current {
+		t.Fatalf("current image after an uncommitted edit = %s, %v; want %s", got, err, current)
+	}
+
+	commit("FROM debian\n")
+	next, err := currentProjectImage("app", project, build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sessionImageOutdated(sess, "app", next) {
+		t.Fatal("image should be outdated after a Dockerfile change was committed")
+	}
+	sess.Target.Image = "custom:latest"
+	if sessionImageOutdated(sess, "app", next) {
+		t.Fatal("explicit images are never reported outdated")
+	}
+}
@@ -39,7 +39,7 @@ func Execute() {
 }
 
 func init() {
-	cobra.OnInitialize(initConfig, checkForUpdatesBackground)
+	cobra.OnInitialize(initConfig, checkForUpdatesBackground, enforceExpiredGatepostBypasses, enforceExpiredGatepostPhases)
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
@@ -100,6 +100,8 @@ func initConfig() {
 	viper.SetDefault("ports", []string{"ui", "api"})
 	viper.SetDefault("editor", "")
 	viper.SetDefault("bootstrap_files", []string{})
+	viper.SetDefault("bootstrap_dirs", []string{})
+	viper.SetDefault("bootstrap_dirs_method", "auto")
 	viper.SetDefault("cleanup_command", "")
 	viper.SetDefault("auto_check_updates", true)
 	viper.SetDefault("update_check_interval", "24h")
@@ -121,6 +123,7 @@ func initConfig() {
 	viper.SetDefault("agent_responder.args", []string{"--print", "--no-session", "@{{.PromptFile}}"})
 	viper.SetDefault("agent_responder.timeout", "5m")
 	viper.SetDefault("agent_responder.read_only", true)
+	viper.SetDefault("agent_responder.parallel", 4)
 
 	// Read primary config (project-level if found, otherwise global)
 	_ = viper.ReadInConfig()
@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/jfox85/devx/caddy"
@@ -26,6 +30,15 @@ var (
 	createDisplayNameFlag string
 	targetFlag            string
 	imageFlag             string
+	sparseFlag            []string
+	sparsePresetFlag      string
+	skipLFSFlag           bool
+	submodulesFlag        bool
+	remoteFlag            string
+	devcontainerFlag      bool
+	memoryFlag            string
+	networkFlag           string
+	cpusFlag              string
 )
 
 func expandUserPath(path string) string {
@@ -39,30 +52,306 @@ func expandUserPath(path string) string {
 	return filepath.Join(home, path[1:])
 }
 
-func trustedGatepostRuntimeConfig() target.GatepostRuntimeConfig {
-	cfg := target.GatepostRuntimeConfig{}
+// trustedConfig returns the configuration that host-side security settings
+// may be read from: the explicit --config file, or the user-global config.
+// Project .devx/config.yaml files are never consulted. It returns nil when no
+// trusted config is available.
+func trustedConfig() *viper.Viper {
 	if cfgFile != "" {
-		cfg.Root = expandUserPath(viper.GetString("gatepost.root"))
-		cfg.LogsCommand = viper.GetString("gatepost.logs_command")
-		cfg.ProviderBootstrapCommand = viper.GetString("gatepost.provider_bootstrap_command")
-		cfg.AuthHome = expandUserPath(viper.GetString("gatepost.auth_home"))
-		cfg.RequiredProviders = viper.GetString("gatepost.required_providers")
-		return cfg
+		return viper.GetViper()
 	}
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return cfg
+		return nil
 	}
 	global := viper.New()
 	global.SetConfigFile(filepath.Join(home, ".config", "devx", "config.yaml"))
 	if err := global.ReadInConfig(); err != nil {
+		return nil
+	}
+	return global
+}
+
+// resolveSecurityOpts layers container limits: the built-in defaults, the
+// selected preset (from security_presets or built in), the inline
+// docker.security settings, then --memory/--cpus.
+func resolveSecurityOpts(sec config.SecurityConfig, presets map[string]config.SecurityConfig, memory, cpus string) (target.SecurityOpts, error) {
+	opts := target.DefaultSecurityOpts()
+	if sec.Preset != "" {
+		if custom, ok := presets[sec.Preset]; ok {
+			if custom.Preset != "" {
+				base, ok := target.SecurityPreset(custom.Preset)
+				if !ok {
+					return opts, fmt.Errorf("security preset %q: unknown base preset %q", sec.Preset, custom.Preset)
+				}
+				opts = base
+			}
+			opts = applySecurityConfig(opts, custom)
+		} else if builtin, ok := target.SecurityPreset(sec.Preset); ok {
+			opts = builtin
+		} else {
+			return opts, fmt.Errorf("unknown security preset %q (built in: default, small, large, strict)", sec.Preset)
+		}
+	}
+	opts = applySecurityConfig(opts, sec)
+	if memory != "" {
+		opts.MemoryLimit = memory
+	}
+	if cpus != "" {
+		opts.CPULimit = cpus
+	}
+	if err := opts.Validate(); err != nil {
+		return opts, fmt.Errorf("docker.security: %w", err)
+	}
+	return opts, nil
+}
+
+// resolveSessionSecurityOpts resolves the container limits of a session.
+// Gatepost sessions take docker.security and docker.security_presets from
+// trusted config only, so a project cannot loosen its agent container.
+func resolveSessionSecurityOpts(targetType string, cfg *config.Config, memory, cpus string) (target.SecurityOpts, error) {
+	sec, presets := cfg.Docker.Security, cfg.Docker.SecurityPresets
+	if targetType == "gatepost" {
+		var err error
+		if sec, presets, err = trustedSecurityConfig(); err != nil {
+			return target.DefaultSecurityOpts(), err
+		}
+	}
+	return resolveSecurityOpts(sec, presets, memory, cpus)
+}
+
+// trustedSecurityConfig reads docker.security and docker.security_presets
+// from trusted config.
+func trustedSecurityConfig() (config.SecurityConfig, map[string]config.SecurityConfig, error) {
+	var sec config.SecurityConfig
+	var presets map[string]config.SecurityConfig
+	v := trustedConfig()
+	if v == nil {
+		return sec, presets, nil
+	}
+	if err := v.UnmarshalKey("docker.security", &sec); err != nil {
+		return sec, presets, fmt.Errorf("docker.security: %w", err)
+	}
+	if err := v.UnmarshalKey("docker.security_presets", &presets); err != nil {
+		return sec, presets, fmt.Errorf("docker.security_presets: %w", err)
+	}
+	return sec, presets, nil
+}
+
+func applySecurityConfig(opts target.SecurityOpts, sec config.SecurityConfig) target.SecurityOpts {
+	if sec.Memory != "" {
+		opts.MemoryLimit = sec.Memory
+	}
+	if sec.CPUs != "" {
+		opts.CPULimit = sec.CPUs
+	}
+	if sec.Pids > 0 {
+		opts.PidsLimit = sec.Pids
+	}
+	if sec.ReadOnlyRoot != nil {
+		opts.ReadOnlyRoot = *sec.ReadOnlyRoot
+	}
+	if len(sec.Tmpfs) > 0 {
+		opts.TmpfsMounts = sec.Tmpfs
+	}
+	if len(sec.CapAdd) > 0 {
+		opts.CapAdd = sec.CapAdd
+	}
+	return opts
+}
+
+// orderedServices lists the allocated services in configured order, followed
+// by any others (e.g. the legacy ui/api ports) sorted by name.
+func orderedServices(configured []string, ports map[string]int) []string {
+	var services []string
+	seen := make(map[string]bool)
+	for _, svc := range configured {
+		if _, ok := ports[svc]; ok && !seen[svc] {
+			services = append(services, svc)
+			seen[svc] = true
+		}
+	}
+	var rest []string
+	for svc := range ports {
+		if !seen[svc] {
+			rest = append(rest, svc)
+		}
+	}
+	sort.Strings(rest)
+	return append(services, rest...)
+}
+
+func trustedGatepostRuntimeConfig() (target.GatepostRuntimeConfig, error) {
+	cfg := target.GatepostRuntimeConfig{}
+	v := trustedConfig()
+	if v == nil {
+		return cfg, nil
+	}
+	cfg.Root = expandUserPath(v.GetString("gatepost.root"))
+	cfg.LogsCommand = v.GetString("gatepost.logs_command")
+	cfg.ProviderBootstrapCommand = v.GetString("gatepost.provider_bootstrap_command")
+	cfg.AuthHome = expandUserPath(v.GetString("gatepost.auth_home"))
+	cfg.RequiredProviders = v.GetString("gatepost.required_providers")
+	secrets, err := trustedGatepostSecrets(v)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Secrets = secrets
+	return cfg, nil
+}
+
+// trustedGatepostSecrets reads and validates gatepost.secrets from trusted
+// config.
+func trustedGatepostSecrets(v *viper.Viper) ([]target.GatepostSecretSpec, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var entries []config.GatepostSecretConfig
+	if err := v.UnmarshalKey("gatepost.secrets", &entries); err != nil {
+		return nil, fmt.Errorf("gatepost.secrets: %w", err)
+	}
+	specs := make([]target.GatepostSecretSpec, 0, len(entries))
+	for _, e := range entries {
+		specs = append(specs, target.GatepostSecretSpec{
+			Name:     e.Name,
+			Env:      e.Env,
+			File:     expandUserPath(e.File),
+			Command:  e.Command,
+			Host:     e.Host,
+			Scheme:   e.Scheme,
+			Header:   e.Header,
+			Username: e.Username,
+			Projects: e.Projects,
+			Presets:  e.Presets,
+		})
+	}
+	if err := target.ValidateGatepostSecrets(specs); err != nil {
+		return nil, fmt.Errorf("gatepost.secrets: %w", err)
+	}
+	return specs, nil
+}
+
+// sessionGatepostRuntimeConfig is the trusted Gatepost runtime config with
+// the secrets scoped to the session's project and to the security preset
+// from trusted config, the one Gatepost sessions run with.
+func sessionGatepostRuntimeConfig(projectAlias string) (target.GatepostRuntimeConfig, error) {
+	cfg, err := trustedGatepostRuntimeConfig()
+	if err != nil {
+		return cfg, err
+	}
+	sec, _, err := trustedSecurityConfig()
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Secrets = target.SelectGatepostSecrets(cfg.Secrets, projectAlias, sec.Preset)
+	return cfg, nil
+}
+
+// trustedRemoteRuntimeConfig resolves the SSH host and remote paths for a
+// remote session from trusted config. With a single configured remote the
+// name may be omitted.
+func trustedRemoteRuntimeConfig(name, projectKey, sessionName string) (target.RemoteRuntimeConfig, error) {
+	cfg := target.RemoteRuntimeConfig{}
+	v := trustedConfig()
+	var names []string
+	if v != nil {
+		for n := range v.GetStringMap("remotes") {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return cfg, fmt.Errorf("no remotes configured; add a remotes: entry to ~/.config/devx/config.yaml")
+	}
+	if name == "" {
+		if len(names) > 1 {
+			return cfg, fmt.Errorf("multiple remotes configured; pass --remote (one of: %s)", strings.Join(names, ", "))
+		}
+		name = names[0]
+	}
+	key := "remotes." + strings.ToLower(name)
+	if !v.IsSet(key) {
+		return cfg, fmt.Errorf("remote '%s' not found (configured: %s)", name, strings.Join(names, ", "))
+	}
+
+	cfg.Name = name
+	cfg.Host = v.GetString(key + ".host")
+	cfg.Port = v.GetInt(key + ".port")
+	cfg.IdentityFile = expandUserPath(v.GetString(key + ".identity_file"))
+	if cfg.Host == "" {
+		return cfg, fmt.Errorf("remote '%s' has no host", name)
+	}
+	cfg.RemoteRepo = v.GetStringMapString(key + ".projects")[strings.ToLower(projectKey)]
+	if cfg.RemoteRepo == "" {
+		return cfg, fmt.Errorf("remote '%s' has no checkout for project '%s'; set %s.projects.%s", name, projectKey, key, projectKey)
+	}
+	root := v.GetString(key + ".worktree_root")
+	if root == "" {
+		root = "~/.devx/worktrees"
+	}
+	cfg.RemotePath = path.Join(root, projectKey, sessionName)
+	return cfg, nil
+}
+
+// resolveDockerNetwork picks a docker session's network mode: --network when
+// given, otherwise the stricter of the trusted and merged project settings,
+// so a project can fence its sessions in but not out. The allowlist proxy's
+// policy comes from the Gatepost policy overlays and its binary from trusted
+// config.
+func resolveDockerNetwork(flag, configured, projectPath string) (target.NetworkRuntimeConfig, error) {
+	var trusted, proxyBinary string
+	if v := trustedConfig(); v != nil {
+		trusted = v.GetString("docker.network")
+		proxyBinary = expandUserPath(v.GetString("docker.egress_proxy_binary"))
+	}
+	mode := flag
+	if mode == "" {
+		mode = trusted
+		if target.NetworkStrictness(configured) > target.NetworkStrictness(mode) {
+			mode = configured
+		}
+	}
+	for _, m := range []string{mode, trusted, configured} {
+		if m != "" && !slices.Contains(target.NetworkModes, m) {
+			return target.NetworkRuntimeConfig{}, fmt.Errorf("docker.network: unknown mode %q (valid: %s)", m, strings.Join(target.NetworkModes, ", "))
+		}
+	}
+	cfg := target.NetworkRuntimeConfig{Mode: mode}
+	if mode != target.NetworkAllowlist {
+		return cfg, nil
+	}
+	policy, err := gatepostPolicyYAML(projectPath)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Policy = policy
+	if proxyBinary == "" && runtime.GOOS == "linux" {
+		if self, err := os.Executable(); err == nil {
+			proxyBinary = self
+		}
+	}
+	if proxyBinary == "" {
+		return cfg, fmt.Errorf("docker.network allowlist needs a Linux devx binary for the proxy container on %s; download one from the releases and set docker.egress_proxy_binary", runtime.GOOS)
+	}
+	cfg.ProxyBinary = proxyBinary
+	return cfg, nil
+}
+
+// trustedSandboxRuntimeConfig reads the sandbox layout from trusted config so
+// a project cannot bind extra host paths into its own sandbox.
+func trustedSandboxRuntimeConfig() target.SandboxRuntimeConfig {
+	cfg := target.SandboxRuntimeConfig{}
+	v := trustedConfig()
+	if v == nil {
 		return cfg
 	}
-	cfg.Root = expandUserPath(global.GetString("gatepost.root"))
-	cfg.LogsCommand = global.GetString("gatepost.logs_command")
-	cfg.ProviderBootstrapCommand = global.GetString("gatepost.provider_bootstrap_command")
-	cfg.AuthHome = expandUserPath(global.GetString("gatepost.auth_home"))
-	cfg.RequiredProviders = global.GetString("gatepost.required_providers")
+	cfg.NoNetwork = v.GetBool("sandbox.no_network")
+	for _, p := range v.GetStringSlice("sandbox.read_only") {
+		cfg.ReadOnly = append(cfg.ReadOnly, expandUserPath(p))
+	}
+	for _, p := range v.GetStringSlice("sandbox.hide") {
+		cfg.Hide = append(cfg.Hide, expandUserPath(p))
+	}
 	return cfg
 }
 
@@ -84,8 +373,17 @@ func init() {
 	sessionCreateCmd.Flags().StringVarP(&projectFlag, "project", "p", "", "Project alias (defaults to current directory's project)")
 	sessionCreateCmd.Flags().StringVar(&createColorFlag, "color", "", "Session color (auto-assigned if not specified)")
 	sessionCreateCmd.Flags().StringVar(&createDisplayNameFlag, "display-name", "", "Display name for the session")
-	sessionCreateCmd.Flags().StringVar(&targetFlag, "target", "", "Execution target: host, docker, or gatepost (default from config)")
+	sessionCreateCmd.Flags().StringVar(&targetFlag, "target", "", "Execution target: host, docker, podman, compose, gatepost, sandbox, remote, or a devx-target-<name> plugin (default from config)")
+	sessionCreateCmd.Flags().StringVar(&remoteFlag, "remote", "", "Remote host from remotes: in config (for --target remote)")
 	sessionCreateCmd.Flags().StringVar(&imageFlag, "image", "", "Docker image for container sessions")
+	sessionCreateCmd.Flags().StringVar(&memoryFlag, "memory", "", "Container memory limit, e.g. 8g (overrides docker.security)")
+	sessionCreateCmd.Flags().StringVar(&cpusFlag, "cpus", "", "Container CPU limit, e.g. 2.5 (overrides docker.security)")
+	sessionCreateCmd.Flags().StringVar(&networkFlag, "network", "", "Docker session egress: full, none or allowlist (overrides docker.network)")
+	sessionCreateCmd.Flags().BoolVar(&devcontainerFlag, "devcontainer", false, "Build the docker session from the worktree's devcontainer.json")
+	sessionCreateCmd.Flags().StringSliceVar(&sparseFlag, "sparse", nil, "Comma-separated sparse-checkout cone paths (e.g. apps/web,libs/ui)")
+	sessionCreateCmd.Flags().StringVar(&sparsePresetFlag, "sparse-preset", "", "Named sparse-checkout preset from worktree.sparse_presets")
+	sessionCreateCmd.Flags().BoolVar(&skipLFSFlag, "skip-lfs", false, "Skip Git LFS smudge when checking out the worktree")
+	sessionCreateCmd.Flags().BoolVar(&submodulesFlag, "submodules", false, "Initialize and update git submodules in the worktree")
 }
 
 func runSessionCreate(cmd *cobra.Command, args []string) error {
@@ -120,11 +418,31 @@ func runSessionCreate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Check Docker availability before any side effects
-	if targetType == "docker" || targetType == "gatepost" {
+	if devcontainerFlag && targetType != "docker" {
+		return fmt.Errorf("--devcontainer requires --target docker")
+	}
+	if devcontainerFlag && imageFlag != "" {
+		return fmt.Errorf("--devcontainer and --image cannot be used together")
+	}
+
+	// Check container runtime availability before any side effects
+	switch targetType {
+	case "docker", "gatepost":
 		if err := target.CheckAvailable(); err != nil {
 			return err
 		}
+	case "podman":
+		if err := target.CheckPodmanAvailable(); err != nil {
+			return err
+		}
+	case "compose":
+		if err := target.CheckComposeAvailable(); err != nil {
+			return err
+		}
+	case "sandbox":
+		if err := target.CheckSandboxAvailable(); err != nil {
+			return err
+		}
 	}
 
 	// Load project registry
@@ -173,6 +491,24 @@ func runSessionCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Resolve and reach the remote host before any side effects
+	var remoteConfig target.RemoteRuntimeConfig
+	if targetType == "remote" {
+		projectKey := projectAlias
+		if projectKey == "" {
+			projectKey = filepath.Base(projectPath)
+		}
+		remoteConfig, err = trustedRemoteRuntimeConfig(remoteFlag, projectKey, name)
+		if err != nil {
+			return err
+		}
+		if err := target.CheckRemoteAvailable(context.Background(), &remoteConfig); err != nil {
+			return err
+		}
+	} else if remoteFlag != "" {
+		return fmt.Errorf("--remote requires --target remote")
+	}
+
 	// Load existing sessions
 	store, err := session.LoadSessions()
 	if err != nil {
@@ -188,7 +524,7 @@ func runSessionCreate(cmd *cobra.Command, args []string) error {
 	// If reuse flag is set and session exists, verify the worktree is still valid
 	if reuseFlag && sessionExists {
 		// Check if the worktree still exists
-		worktreeExists, err := session.WorktreeExists(projectPath, existingSession.Path)
+		worktreeExists, err := session.WorkspaceExists(session.VCSForSession(existingSession), projectPath, existingSession.Path)
 		if err != nil {
 			return fmt.Errorf("failed to check worktree existence: %w", err)
 		}
@@ -243,6 +579,30 @@ func runSessionCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Resolve container limits before creating the worktree so bad config
+	// fails fast.
+	securityOpts, err := resolveSessionSecurityOpts(targetType, cfg, memoryFlag, cpusFlag)
+	if err != nil {
+		return err
+	}
+
+	// Merge the egress policy overlays now so an invalid policy fails before
+	// the worktree exists.
+	var gatepostPolicy []byte
+	if targetType == "gatepost" {
+		if gatepostPolicy, err = gatepostPolicyYAML(projectPath); err != nil {
+			return err
+		}
+	}
+	var dockerNetwork target.NetworkRuntimeConfig
+	if targetType == "docker" {
+		if dockerNetwork, err = resolveDockerNetwork(networkFlag, cfg.Docker.Network, projectPath); err != nil {
+			return err
+		}
+	} else if networkFlag != "" {
+		return fmt.Errorf("--network only applies to --target docker")
+	}
+
 	// Check if auto-pull is enabled for this project
 	if project != nil && project.AutoPullOnCreate {
 		fmt.Printf("Pulling latest changes from origin/%s...\n", project.DefaultBranch)
@@ -290,13 +650,39 @@ func runSessionCreate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create the worktree (or adopt an existing one if the branch is already checked out)
-	worktreePath, err := session.CreateWorktree(projectPath, name, detachFlag)
+	sparsePaths, err := cfg.Worktree.ResolveSparse(sparsePresetFlag, session.ParseSparsePaths(sparseFlag))
 	if err != nil {
 		return err
 	}
+	backend, err := session.ResolveVCS(cfg.VCS, projectPath)
+	if err != nil {
+		return err
+	}
+	worktreePath, err := backend.CreateWorkspace(projectPath, name, detachFlag, session.WorktreeOptions{
+		SparsePaths: sparsePaths,
+		SkipLFS:     skipLFSFlag || cfg.Worktree.SkipLFS,
+		Filter:      cfg.Worktree.Filter,
+		Submodules:  submodulesFlag || cfg.Worktree.Submodules,
+	})
+	if err != nil {
+		return err
+	}
+	if len(sparsePaths) > 0 {
+		fmt.Printf("Sparse checkout: %s\n", strings.Join(sparsePaths, ", "))
+	}
 	if err := session.CopyBootstrapFiles(projectPath, worktreePath, cfg.BootstrapFiles); err != nil {
 		return fmt.Errorf("failed to copy bootstrap files: %w", err)
 	}
+	if len(cfg.BootstrapDirs) > 0 {
+		if _, err := session.SeedBootstrapDirs(worktreePath, session.SeedOptions{
+			Dirs:      cfg.BootstrapDirs,
+			Lockfiles: cfg.BootstrapDirLockfiles,
+			Method:    cfg.BootstrapDirsMethod,
+			Sources:   session.SeedSources(store, projectPath, name),
+		}); err != nil {
+			return fmt.Errorf("failed to seed bootstrap dirs: %w", err)
+		}
+	}
 
 	// Add session to metadata with project information
 	// Get the branch name for the session
@@ -313,6 +699,14 @@ func runSessionCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to save session metadata: %w", err)
 	}
 
+	if backend.Name() != session.VCSGit {
+		if err := store.UpdateSession(name, func(s *session.Session) {
+			s.VCS = backend.Name()
+		}); err != nil {
+			return fmt.Errorf("failed to save session metadata: %w", err)
+		}
+	}
+
 	// Override color and display name if flags were provided
 	if createColorFlag != "" || createDisplayNameFlag != "" {
 		if err := store.UpdateSession(name, func(s *session.Session) {
@@ -360,12 +754,10 @@ func runSessionCreate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Generate tmuxp config
-	// For Docker sessions, use /workspace as the path inside the container.
-	// The file is written to the host worktree (mounted at /workspace).
-	tmuxpPath := worktreePath
-	if targetType == "docker" || targetType == "gatepost" {
-		tmuxpPath = "/workspace"
-	}
+	// For container sessions, use /workspace as the path inside the container;
+	// for remote sessions, the remote worktree. The file is written to the
+	// local worktree (mounted at /workspace, or synced to the remote).
+	tmuxpPath := sessionRuntimePath(targetType, worktreePath, remoteConfig.RemotePath)
 	tmuxpData := session.TmuxpData{
 		Name:           name,
 		Path:           tmuxpPath,
@@ -386,13 +778,18 @@ func runSessionCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	templateData := bootstrapTemplateData(name, worktreePath, remoteConfig.RemotePath, projectAlias, projectPath, targetType, portAllocation.Ports, hostnames)
+	if err := session.RenderBootstrapTemplates(projectPath, worktreePath, cfg.BootstrapTemplates, templateData); err != nil {
+		return fmt.Errorf("failed to render bootstrap templates: %w", err)
+	}
+
 	// Start the target (container for docker, no-op for host)
 	tgt, _ := target.Resolve(targetType) // already validated above
 	ctx := context.Background()
 
 	// For container targets: ensure the image exists, start the container(s)
 	var targetMeta session.TargetMeta
-	if targetType == "docker" || targetType == "gatepost" {
+	if targetType == "docker" || targetType == "podman" || targetType == "compose" || targetType == "gatepost" {
 		dockerImage := imageFlag
 		if targetType == "gatepost" {
 			if dockerImage == "" {
@@ -409,23 +806,76 @@ func runSessionCreate(cmd *cobra.Command, args []string) error {
 				dockerImage = "devx-session-base:latest"
 			}
 		}
-		if targetType == "docker" && dockerImage == "devx-session-base:latest" && !target.ImageExists(dockerImage) {
+
+		// devcontainer.json replaces the image and adds env, mounts, port
+		// mappings and lifecycle commands. An explicit --image wins over config.
+		var devcontainer *target.Devcontainer
+		if targetType == "docker" && (devcontainerFlag || (cfg.Docker.Devcontainer && imageFlag == "")) {
+			dc, err := target.PrepareDevcontainer(ctx, worktreePath, orderedServices(cfg.Ports, portAllocation.Ports))
+			if err != nil {
+				return fmt.Errorf("failed to prepare devcontainer: %w", err)
+			}
+			if dc == nil && devcontainerFlag {
+				return fmt.Errorf("--devcontainer: no .devcontainer/devcontainer.json or .devcontainer.json in %s", worktreePath)
+			}
+			if dc != nil {
+				for _, w := range dc.Warnings {
+					fmt.Printf("Warning: devcontainer: %s\n", w)
+				}
+				fmt.Printf("Using devcontainer image %s\n", dc.Image)
+				dockerImage = dc.Image
+				devcontainer = dc
+			}
+		}
+		// A configured docker.build produces a content-addressed project image,
+		// rebuilt here whenever its Dockerfile, args or context change.
+		if devcontainer == nil && imageFlag == "" && (targetType == "docker" || targetType == "compose") && cfg.Docker.Build.Enabled() {
+			spec, err := projectImageSpec(imageProjectName(projectAlias, projectPath), projectPath, cfg.Docker.Build)
+			if err != nil {
+				return err
+			}
+			tag, built, err := target.EnsureProjectImage(ctx, spec)
+			if err != nil {
+				return fmt.Errorf("failed to build project image: %w", err)
+			}
+			if built {
+				fmt.Printf("Built project image %s\n", tag)
+			}
+			dockerImage = tag
+		}
+		if (targetType == "docker" || targetType == "compose") && dockerImage == "devx-session-base:latest" && !target.ImageExists(dockerImage) {
 			return fmt.Errorf("devx-session-base image not found. Build it first:\n  docker build -t devx-session-base:latest docker/")
 		}
+		if targetType == "podman" && dockerImage == "devx-session-base:latest" && !target.PodmanImageExists(dockerImage) {
+			return fmt.Errorf("devx-session-base image not found in podman storage. Build it first:\n  podman build -t devx-session-base:latest docker/")
+		}
 
 		// Build env map for the container
-		containerEnv := make(map[string]string)
-		for svc, port := range portAllocation.Ports {
-			containerEnv[strings.ToUpper(svc)+"_PORT"] = fmt.Sprintf("%d", port)
+		containerEnv := containerSessionEnv(name, portAllocation.Ports, hostnames)
+		if devcontainer != nil {
+			// Services in a devcontainer listen on their forwardPorts.
+			for svc, port := range devcontainer.Ports {
+				containerEnv[strings.ToUpper(svc)+"_PORT"] = fmt.Sprintf("%d", port)
+			}
 		}
-		for svc, hostname := range hostnames {
-			containerEnv[strings.ToUpper(strings.ReplaceAll(svc, "-", "_"))+"_HOST"] = "http://" + hostname
+
+		// Shared caches live in per-project volumes reused by every session.
+		var cacheUser string
+		if devcontainer != nil {
+			cacheUser = devcontainer.User
+		}
+		caches, err := target.ResolveCacheMounts(imageProjectName(projectAlias, projectPath), targetType, cacheUser, cacheSpecs(cfg.Docker.Caches))
+		if err != nil {
+			return fmt.Errorf("docker.caches: %w", err)
 		}
-		containerEnv["SESSION_NAME"] = name
 
 		gatepostConfig := target.GatepostRuntimeConfig{}
 		if targetType == "gatepost" {
-			gatepostConfig = trustedGatepostRuntimeConfig()
+			if gatepostConfig, err = sessionGatepostRuntimeConfig(projectAlias); err != nil {
+				return err
+			}
+			gatepostConfig.Policy = gatepostPolicy
+			gatepostConfig.InstallCommands = cfg.Gatepost.InstallCommands
 		}
 
 		result, err := tgt.Start(ctx, target.StartOpts{
@@ -438,14 +888,25 @@ func runSessionCreate(cmd *cobra.Command, args []string) error {
 				"devx.session": name,
 				"devx.project": projectAlias,
 			},
-			Security:       target.DefaultSecurityOpts(),
+			Security:       securityOpts,
 			GatepostConfig: gatepostConfig,
+			Devcontainer:   devcontainer,
+			Caches:         caches,
+			Network:        dockerNetwork,
+			Compose: target.ComposeRuntimeConfig{
+				Files:         cfg.Compose.Files,
+				Network:       cfg.Compose.Network,
+				RemoveVolumes: cfg.Compose.RemoveVolumes,
+			},
 		})
 		if err != nil {
 			return fmt.Errorf("failed to start docker target: %w", err)
 		}
 
 		targetMeta = result.Meta
+		// Retargeting restarts the container with these limits.
+		targetMeta.MemoryLimit = securityOpts.MemoryLimit
+		targetMeta.CPULimit = securityOpts.CPULimit
 
 		// Save target metadata
 		if err := store.UpdateSession(name, func(s *session.Session) {
@@ -458,6 +919,75 @@ func runSessionCreate(cmd *cobra.Command, args []string) error {
 		if targetMeta.Gatepost.Enabled && targetMeta.Gatepost.LogsURL != "" {
 			fmt.Printf("Gatepost Logs: open DevX web and use /api/gatepost/logs?session=%s\n", name)
 		}
+	} else if targetType == "sandbox" {
+		sandboxEnv := map[string]string{"SESSION_NAME": name}
+		for svc, port := range portAllocation.Ports {
+			sandboxEnv[strings.ToUpper(svc)+"_PORT"] = fmt.Sprintf("%d", port)
+		}
+		result, err := tgt.Start(ctx, target.StartOpts{
+			SessionName:  name,
+			WorktreePath: worktreePath,
+			HostPorts:    portAllocation.Ports,
+			Env:          sandboxEnv,
+			Sandbox:      trustedSandboxRuntimeConfig(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to start sandbox target: %w", err)
+		}
+		targetMeta = result.Meta
+		if err := store.UpdateSession(name, func(s *session.Session) {
+			s.Target = targetMeta
+		}); err != nil {
+			fmt.Printf("Warning: failed to save target metadata: %v\n", err)
+		}
+		fmt.Printf("Prepared bubblewrap sandbox for '%s'\n", name)
+	} else if targetType == "remote" {
+		remoteConfig.SyncFiles = append([]string{".envrc", ".tmuxp.yaml"}, cfg.BootstrapFiles...)
+		remoteConfig.SyncFiles = append(remoteConfig.SyncFiles, bootstrapTemplateFiles(cfg.BootstrapTemplates)...)
+		result, err := tgt.Start(ctx, target.StartOpts{
+			SessionName:  name,
+			WorktreePath: worktreePath,
+			HostPorts:    portAllocation.Ports,
+			Remote:       remoteConfig,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to start remote target: %w", err)
+		}
+		targetMeta = result.Meta
+		if err := store.UpdateSession(name, func(s *session.Session) {
+			s.Target = targetMeta
+		}); err != nil {
+			fmt.Printf("Warning: failed to save target metadata: %v\n", err)
+		}
+		fmt.Printf("Created remote worktree %s:%s\n", remoteConfig.Host, remoteConfig.RemotePath)
+	} else if _, ok := tgt.(*target.PluginTarget); ok {
+		caches, err := target.ResolveCacheMounts(imageProjectName(projectAlias, projectPath), targetType, "", cacheSpecs(cfg.Docker.Caches))
+		if err != nil {
+			return fmt.Errorf("docker.caches: %w", err)
+		}
+		result, err := tgt.Start(ctx, target.StartOpts{
+			SessionName:  name,
+			WorktreePath: worktreePath,
+			HostPorts:    portAllocation.Ports,
+			Image:        imageFlag,
+			Env:          containerSessionEnv(name, portAllocation.Ports, hostnames),
+			Labels: map[string]string{
+				"devx.session": name,
+				"devx.project": projectAlias,
+			},
+			Security: securityOpts,
+			Caches:   caches,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to start %s target: %w", targetType, err)
+		}
+		targetMeta = result.Meta
+		if err := store.UpdateSession(name, func(s *session.Session) {
+			s.Target = targetMeta
+		}); err != nil {
+			fmt.Printf("Warning: failed to save target metadata: %v\n", err)
+		}
+		fmt.Printf("Started target plugin '%s'\n", targetType)
 	}
 
 	// Sync all Caddy routes (writes config file + reloads)
@@ -488,6 +1018,20 @@ func runSessionCreate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// containerSessionEnv is the environment container sessions start with: the
+// allocated *_PORT values, *_HOST routes and SESSION_NAME.
+func containerSessionEnv(name string, ports map[string]int, hostnames map[string]string) map[string]string {
+	env := make(map[string]string)
+	for svc, port := range ports {
+		env[strings.ToUpper(svc)+"_PORT"] = fmt.Sprintf("%d", port)
+	}
+	for svc, hostname := range hostnames {
+		env[strings.ToUpper(strings.ReplaceAll(svc, "-", "_"))+"_HOST"] = "http://" + hostname
+	}
+	env["SESSION_NAME"] = name
+	return env
+}
+
 func launchExistingSessionTmux(name string, sess *session.Session) {
 	launchSessionTmuxHandoff(name, sess, "exists")
 }
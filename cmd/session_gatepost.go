@@ -3,18 +3,32 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
 
-	"github.com/jfox85/devx/session"
-	"github.com/jfox85/devx/target"
+	"github.com/jfox85/devx/egress"
+	"github.com/jfox85/devx/internal/duration"
 	"github.com/spf13/cobra"
 )
 
+var (
+	gatepostBypassForFlag    time.Duration
+	gatepostBypassReasonFlag string
+)
+
 var sessionGatepostCmd = &cobra.Command{Use: "gatepost", Short: "Manage Gatepost-backed sessions"}
 
 var sessionGatepostBypassCmd = &cobra.Command{
 	Use:   "bypass <session>",
 	Short: "Host-side emergency bypass for a Gatepost session",
-	Args:  cobra.ExactArgs(1),
+	Long: `Let a Gatepost session reach the network directly, bypassing the proxy.
+
+  devx session gatepost bypass feat --for 15m --reason "debugging registry auth"
+
+With --for enforcement is restored automatically when the time is up, by
+devx web or the next devx command. Bypass start and stop are recorded in the
+session's companion audit log.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return setGatepostBypass(args[0], true)
 	},
@@ -31,30 +45,36 @@ var sessionGatepostEnforceCmd = &cobra.Command{
 
 func init() {
 	sessionCmd.AddCommand(sessionGatepostCmd)
+	sessionGatepostBypassCmd.Flags().Var(duration.NewFlag(&gatepostBypassForFlag), "for", "Restore enforcement after this long, e.g. 15m or 1d")
+	sessionGatepostBypassCmd.Flags().StringVar(&gatepostBypassReasonFlag, "reason", "", "Why the bypass is needed; recorded in the audit log")
 	sessionGatepostCmd.AddCommand(sessionGatepostBypassCmd)
 	sessionGatepostCmd.AddCommand(sessionGatepostEnforceCmd)
 }
 
 func setGatepostBypass(name string, bypass bool) error {
-	store, err := session.LoadSessions()
-	if err != nil {
-		return err
+	gp, err := egress.SetBypass(context.Background(), name, bypass, gatepostBypassForFlag, gatepostBypassReasonFlag, egress.TriggerManual)
+	if gp.Enabled {
+		switch {
+		case bypass && gp.BypassUntil != nil:
+			fmt.Printf("Gatepost bypass enabled for %s until %s. New traffic may use direct egress.\n", name, gp.BypassUntil.Local().Format(time.Kitchen))
+		case bypass:
+			fmt.Printf("Gatepost bypass enabled for %s. New traffic may use direct egress.\n", name)
+		default:
+			fmt.Printf("Gatepost enforcement restored for %s.\n", name)
+		}
 	}
-	sess, ok := store.GetSession(name)
-	if !ok {
-		return fmt.Errorf("session %q not found", name)
-	}
-	if sess.Target.Type != "gatepost" || !sess.Target.Gatepost.Enabled {
-		return fmt.Errorf("session %q is not a gatepost session", name)
-	}
-	ctx := context.Background()
-	if err := target.SetGatepostBypass(ctx, sess.Target, bypass); err != nil {
-		return err
+	return err
+}
+
+// enforceExpiredGatepostBypasses restores enforcement for sessions whose
+// time-boxed bypass ran out while devx web was not running. It runs before
+// every command and only reports problems.
+func enforceExpiredGatepostBypasses() {
+	expired, err := egress.ExpireBypasses(context.Background(), time.Now())
+	for _, name := range expired {
+		fmt.Fprintf(os.Stderr, "Gatepost bypass for %s expired; enforcement restored.\n", name)
 	}
-	if bypass {
-		fmt.Printf("Gatepost bypass enabled for %s. New traffic may use direct egress.\n", name)
-	} else {
-		fmt.Printf("Gatepost enforcement restored for %s.\n", name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to restore Gatepost enforcement: %v\n", err)
 	}
-	return store.UpdateSession(name, func(s *session.Session) { s.Target.Gatepost.Bypass = bypass })
 }
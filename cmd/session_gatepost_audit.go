@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gatepostAuditSinceFlag    string
+	gatepostAuditHostFlag     string
+	gatepostAuditDecisionFlag string
+	gatepostAuditLimitFlag    int
+	gatepostAuditJSONFlag     bool
+)
+
+var sessionGatepostAuditCmd = &cobra.Command{
+	Use:   "audit <session>",
+	Short: "Query a Gatepost session's egress audit log",
+	Long: `Read the session's audit.jsonl and print the most recent egress decisions
+followed by the busiest and most-denied hosts.
+
+  devx session gatepost audit feat --since 1h --host '*.github.com' --decision denied`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionGatepostAudit,
+}
+
+func init() {
+	sessionGatepostAuditCmd.Flags().StringVar(&gatepostAuditSinceFlag, "since", "", "Only entries newer than a duration (30m, 1h, 2d) or an RFC 3339 time")
+	sessionGatepostAuditCmd.Flags().StringVar(&gatepostAuditHostFlag, "host", "", "Only this host, or subdomains of a '*.example.com' pattern")
+	sessionGatepostAuditCmd.Flags().StringVar(&gatepostAuditDecisionFlag, "decision", "", "Only allowed or denied requests")
+	sessionGatepostAuditCmd.Flags().IntVar(&gatepostAuditLimitFlag, "limit", 50, "Number of recent entries to list (0 for all)")
+	sessionGatepostAuditCmd.Flags().BoolVar(&gatepostAuditJSONFlag, "json", false, "Print entries and summary as JSON")
+	sessionGatepostCmd.AddCommand(sessionGatepostAuditCmd)
+}
+
+func runSessionGatepostAudit(cmd *cobra.Command, args []string) error {
+	switch gatepostAuditDecisionFlag {
+	case "", "allowed", "denied":
+	default:
+		return fmt.Errorf("--decision must be allowed or denied")
+	}
+	since, err := target.ParseAuditSince(gatepostAuditSinceFlag, time.Now())
+	if err != nil {
+		return err
+	}
+	sess, err := loadGatepostSession(args[0])
+	if err != nil {
+		return err
+	}
+	path := sess.Target.Gatepost.AuditLog
+	if path == "" {
+		return fmt.Errorf("session %q has no audit log", args[0])
+	}
+	entries, err := target.ReadGatepostAudit(path, target.GatepostAuditFilter{
+		Since:    since,
+		Host:     gatepostAuditHostFlag,
+		Decision: gatepostAuditDecisionFlag,
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	summary := target.SummarizeGatepostAudit(entries, 10)
+	if n := gatepostAuditLimitFlag; n > 0 && len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	// Bypass events concern the whole session, not a host or decision.
+	var bypassEvents []target.GatepostBypassEvent
+	if gatepostAuditHostFlag == "" && gatepostAuditDecisionFlag == "" {
+		if bypassEvents, err = target.ReadGatepostBypassEvents(sess.Target.Gatepost, since); err != nil {
+			return fmt.Errorf("failed to read bypass events: %w", err)
+		}
+	}
+
+	if gatepostAuditJSONFlag {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]any{"entries": entries, "summary": summary, "bypass_events": bypassEvents})
+	}
+	printGatepostAudit(cmd.OutOrStdout(), entries, summary)
+	printGatepostBypassEvents(cmd.OutOrStdout(), bypassEvents)
+	return nil
+}
+
+func printGatepostBypassEvents(out io.Writer, events []target.GatepostBypassEvent) {
+	if len(events) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "\nBypass:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, ev := range events {
+		detail := ev.Trigger
+		if ev.Event == target.GatepostBypassStart {
+			detail = ev.Reason
+			if ev.Until != nil {
+				detail = strings.TrimSpace(fmt.Sprintf("%s (until %s)", detail, ev.Until.Local().Format(time.DateTime)))
+			}
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", ev.Time.Local().Format(time.DateTime), strings.TrimPrefix(ev.Event, "bypass_"), detail)
+	}
+	w.Flush()
+}
+
+func printGatepostAudit(out io.Writer, entries []target.GatepostAuditEntry, summary target.GatepostAuditSummary) {
+	if summary.Total == 0 {
+		fmt.Fprintln(out, "No matching audit entries.")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tDECISION\tHOST\tMETHOD\tREASON")
+	for _, e := range entries {
+		ts := "-"
+		if !e.Time.IsZero() {
+			ts = e.Time.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", ts, e.Decision, e.Host, e.Method, e.Reason)
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "\n%d requests: %d allowed, %d denied\n", summary.Total, summary.Allowed, summary.Denied)
+	printHostCounts(out, "Top hosts", summary.TopHosts)
+	printHostCounts(out, "Top denied hosts", summary.TopDenied)
+}
+
+func printHostCounts(out io.Writer, title string, counts []target.GatepostHostCount) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\n%s:\n", title)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, c := range counts {
+		fmt.Fprintf(w, "  %s\t%d\n", c.Host, c.Count)
+	}
+	w.Flush()
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jfox85/devx/egress"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gatepostPendingJSONFlag  bool
+	gatepostPendingScopeFlag string
+)
+
+var sessionGatepostPendingCmd = &cobra.Command{
+	Use:   "pending [session]",
+	Short: "List unknown hosts Gatepost blocked that are waiting for approval",
+	Long: `List blocked egress destinations waiting for a decision. The audit logs are
+read first, so this works without devx web running.
+
+Allow a host with "pending allow <id> --scope once|session|project" or
+dismiss it with "pending deny <id>". The same requests appear in the TUI and
+the web UI.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store := egress.NewStore()
+		if _, err := store.SyncSessions(); err != nil {
+			return err
+		}
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		reqs, err := store.Pending(name)
+		if err != nil {
+			return err
+		}
+		if gatepostPendingJSONFlag {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if reqs == nil {
+				reqs = []*egress.Request{}
+			}
+			return enc.Encode(reqs)
+		}
+		if len(reqs) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No pending egress approvals.")
+			return nil
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSESSION\tHOST\tPHASE\tBLOCKED\tLAST SEEN")
+		for _, r := range reqs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", r.ID, r.Session, r.Host, r.Phase, r.Count, r.LastSeen.Local().Format(time.DateTime))
+		}
+		return w.Flush()
+	},
+}
+
+var sessionGatepostPendingAllowCmd = &cobra.Command{
+	Use:   "allow <id>",
+	Short: "Allow a blocked host once, for the session, or in the project policy",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := egress.NewStore().Allow(context.Background(), args[0], gatepostPendingScopeFlag)
+		if err != nil {
+			return err
+		}
+		switch req.Scope {
+		case egress.ScopeOnce:
+			fmt.Printf("Allowed the next request from '%s' to %s\n", req.Session, req.Host)
+		case egress.ScopeSession:
+			fmt.Printf("Allowed %s for session '%s' (%s phase)\n", req.Host, req.Session, req.Phase)
+		case egress.ScopeProject:
+			fmt.Printf("Added %s to the project policy (%s phase) and reloaded session '%s'\n", req.Host, req.Phase, req.Session)
+		}
+		return nil
+	},
+}
+
+var sessionGatepostPendingDenyCmd = &cobra.Command{
+	Use:   "deny <id>",
+	Short: "Dismiss a blocked host; the proxy keeps blocking it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := egress.NewStore().Deny(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Dismissed %s for session '%s'\n", req.Host, req.Session)
+		return nil
+	},
+}
+
+func init() {
+	sessionGatepostPendingCmd.Flags().BoolVar(&gatepostPendingJSONFlag, "json", false, "Print requests as JSON")
+	sessionGatepostPendingAllowCmd.Flags().StringVar(&gatepostPendingScopeFlag, "scope", egress.ScopeSession, "once, session or project")
+	sessionGatepostPendingCmd.AddCommand(sessionGatepostPendingAllowCmd)
+	sessionGatepostPendingCmd.AddCommand(sessionGatepostPendingDenyCmd)
+	sessionGatepostCmd.AddCommand(sessionGatepostPendingCmd)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jfox85/devx/egress"
+	"github.com/jfox85/devx/internal/duration"
+	"github.com/jfox85/devx/session"
+	"github.com/spf13/cobra"
+)
+
+var gatepostPhaseForFlag time.Duration
+
+var sessionGatepostPhaseCmd = &cobra.Command{
+	Use:   "phase <session> [install|run]",
+	Short: "Show or switch a Gatepost session's policy phase",
+	Long: `Show the policy phase a Gatepost session enforces, or switch it. The install
+phase allows package registries; run is the normal working phase.
+
+  devx session gatepost phase feat install --for 10m
+
+With --for devx returns the proxy to the run phase when the time is up, on the
+next devx command or from the devx web daemon.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runSessionGatepostPhase,
+}
+
+func init() {
+	sessionGatepostPhaseCmd.Flags().Var(duration.NewFlag(&gatepostPhaseForFlag), "for", "Revert to the run phase after this long, e.g. 10m or 1d")
+	sessionGatepostCmd.AddCommand(sessionGatepostPhaseCmd)
+}
+
+func runSessionGatepostPhase(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	sess, err := loadGatepostSession(name)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if len(args) == 1 {
+		if gatepostPhaseForFlag != 0 {
+			return fmt.Errorf("--for needs a phase to switch to")
+		}
+		fmt.Println(gatepostPhaseDescription(sess.Target.Gatepost, now))
+		return nil
+	}
+
+	phase := args[1]
+	if gatepostPhaseForFlag > 0 && phase == "run" {
+		return fmt.Errorf("--for reverts to the run phase; switch to install instead")
+	}
+	gp, err := egress.SetPhase(context.Background(), name, phase, gatepostPhaseForFlag)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Session '%s': %s\n", name, gatepostPhaseDescription(gp, now))
+	return nil
+}
+
+// gatepostPhaseDescription describes the phase in effect, e.g.
+// "install phase (reverts to run in 9m59s)".
+func gatepostPhaseDescription(gp session.GatepostMeta, now time.Time) string {
+	phase := gp.CurrentPhase()
+	if gp.PhaseRevertDue(now) {
+		return phase + " phase (revert to run pending)"
+	}
+	if phase != "run" && gp.PhaseRevertAt != nil {
+		return fmt.Sprintf("%s phase (reverts to run in %s)", phase, gp.PhaseRevertAt.Sub(now).Round(time.Second))
+	}
+	return phase + " phase"
+}
+
+// enforceExpiredGatepostPhases returns sessions whose timed phase switch ran
+// out while devx web was not running to the run phase. It runs before every
+// command and only reports problems.
+func enforceExpiredGatepostPhases() {
+	reverted, err := egress.ExpirePhases(context.Background(), time.Now())
+	for _, name := range reverted {
+		fmt.Fprintf(os.Stderr, "Gatepost phase for %s expired; back to the run phase.\n", name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to revert Gatepost phase: %v\n", err)
+	}
+}
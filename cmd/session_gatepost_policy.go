@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+)
+
+var gatepostPolicyProjectFlag string
+
+var sessionGatepostPolicyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Inspect and reload Gatepost egress policy",
+	Long: `Gatepost sessions start from the built-in egress policy, edited by
+~/.config/devx/gatepost-policy.yaml and then the project's
+.devx/gatepost-policy.yaml:
+
+  version: 1
+  defaults:
+    unknown_action: smart     # allow, deny or smart
+  phases:
+    run:
+      add: [npm.internal.example.com, "*.corp.example.com"]
+      remove: [r.jina.ai]
+      unknown_action: deny`,
+}
+
+var sessionGatepostPolicyValidateCmd = &cobra.Command{
+	Use:   "validate [file...]",
+	Short: "Validate gatepost-policy.yaml files",
+	Long: `Validate the given policy overlays, or with no arguments the global overlay
+and the overlay of the current (or --project) project, and print the
+resulting effective policy.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			for _, path := range args {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				if _, err := target.ParseGatepostPolicyOverlay(data); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				fmt.Printf("%s: ok\n", path)
+			}
+			return nil
+		}
+		_, projectPath, err := resolveImageProject(gatepostPolicyProjectFlag)
+		if err != nil {
+			return err
+		}
+		policy, err := gatepostPolicyYAML(projectPath)
+		if err != nil {
+			return err
+		}
+		for _, path := range target.GatepostPolicyPaths(projectPath) {
+			if _, err := os.Stat(path); err == nil {
+				fmt.Printf("%s: ok\n", path)
+			}
+		}
+		fmt.Printf("\nEffective policy:\n%s", policy)
+		return nil
+	},
+}
+
+var sessionGatepostPolicyShowCmd = &cobra.Command{
+	Use:   "show <session>",
+	Short: "Show the egress policy a Gatepost session is enforcing",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sess, err := loadGatepostSession(args[0])
+		if err != nil {
+			return err
+		}
+		path, err := target.GatepostPolicyPath(sess.Target)
+		if err != nil {
+			return err
+		}
+		running, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read session policy: %w", err)
+		}
+		fmt.Print(string(running))
+		if current, err := sessionGatepostPolicyYAML(sess); err == nil && !bytes.Equal(current, running) {
+			fmt.Fprintf(os.Stderr, "\nNote: policy files changed since this session loaded its policy. Run 'devx session gatepost policy reload %s' to apply them.\n", sess.Name)
+		}
+		return nil
+	},
+}
+
+var sessionGatepostPolicyReloadCmd = &cobra.Command{
+	Use:   "reload <session>",
+	Short: "Apply the current policy files to a running Gatepost session",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sess, err := loadGatepostSession(args[0])
+		if err != nil {
+			return err
+		}
+		policy, err := sessionGatepostPolicyYAML(sess)
+		if err != nil {
+			return err
+		}
+		if err := target.ReloadGatepostPolicy(context.Background(), sess.Target, policy); err != nil {
+			return err
+		}
+		fmt.Printf("Reloaded Gatepost policy for %s.\n", sess.Name)
+		return nil
+	},
+}
+
+func init() {
+	sessionGatepostPolicyValidateCmd.Flags().StringVarP(&gatepostPolicyProjectFlag, "project", "p", "", "Project whose overlay to validate (default: current directory)")
+	sessionGatepostCmd.AddCommand(sessionGatepostPolicyCmd)
+	sessionGatepostPolicyCmd.AddCommand(sessionGatepostPolicyValidateCmd)
+	sessionGatepostPolicyCmd.AddCommand(sessionGatepostPolicyShowCmd)
+	sessionGatepostPolicyCmd.AddCommand(sessionGatepostPolicyReloadCmd)
+}
+
+// gatepostPolicyYAML renders a project's effective Gatepost policy, printing
+// warnings for removals that matched nothing.
+func gatepostPolicyYAML(projectPath string) ([]byte, error) {
+	return renderGatepostPolicy(projectPath, nil)
+}
+
+// sessionGatepostPolicyYAML renders a session's effective Gatepost policy:
+// its project's policy plus the hosts allowed for the session only.
+func sessionGatepostPolicyYAML(sess *session.Session) ([]byte, error) {
+	return renderGatepostPolicy(sess.ProjectPath, target.SessionGatepostOverlay(sess.Target.Gatepost))
+}
+
+func renderGatepostPolicy(projectPath string, sessionOverlay *target.GatepostPolicyOverlay) ([]byte, error) {
+	policy, warnings, err := target.EffectiveGatepostPolicy(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("gatepost policy: %w", err)
+	}
+	if sessionOverlay != nil {
+		policy.Apply(sessionOverlay)
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	return policy.Marshal()
+}
+
+func loadGatepostSession(name string) (*session.Session, error) {
+	store, err := session.LoadSessions()
+	if err != nil {
+		return nil, err
+	}
+	sess, ok := store.GetSession(name)
+	if !ok {
+		return nil, fmt.Errorf("session %q not found", name)
+	}
+	if sess.Target.Type != "gatepost" || !sess.Target.Gatepost.Enabled {
+		return nil, fmt.Errorf("session %q is not a gatepost session", name)
+	}
+	return sess, nil
+}
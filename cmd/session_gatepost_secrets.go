@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gatepostSecretsJSONFlag    bool
+	gatepostSecretsRefreshFlag bool
+)
+
+var sessionGatepostSecretsCmd = &cobra.Command{
+	Use:   "secrets <session>",
+	Short: "List the secrets registered with a Gatepost session's proxy",
+	Long: `List the names of the secrets the session's proxy injects into outgoing
+requests. Values are never shown.
+
+With --refresh the session's gatepost.secrets are read again and registered,
+e.g. after rotating a token.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionGatepostSecrets,
+}
+
+func init() {
+	sessionGatepostSecretsCmd.Flags().BoolVar(&gatepostSecretsJSONFlag, "json", false, "Print secrets as JSON")
+	sessionGatepostSecretsCmd.Flags().BoolVar(&gatepostSecretsRefreshFlag, "refresh", false, "Re-read configured secrets and register them again")
+	sessionGatepostCmd.AddCommand(sessionGatepostSecretsCmd)
+}
+
+type gatepostSecretListing struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Host   string `json:"host,omitempty"`
+}
+
+func runSessionGatepostSecrets(cmd *cobra.Command, args []string) error {
+	sess, err := loadGatepostSession(args[0])
+	if err != nil {
+		return err
+	}
+	specs, err := trustedGatepostSecrets(trustedConfig())
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if gatepostSecretsRefreshFlag {
+		refreshed, err := target.RefreshGatepostSecrets(ctx, sess.Target, specs, true)
+		if err != nil {
+			return err
+		}
+		if len(refreshed) == 0 {
+			fmt.Fprintln(cmd.ErrOrStderr(), "No configured secrets to refresh.")
+		} else {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Re-registered %s\n", strings.Join(refreshed, ", "))
+		}
+	}
+	names, err := target.ListGatepostSecrets(ctx, sess.Target)
+	if err != nil {
+		return err
+	}
+
+	configured := map[string]target.GatepostSecretSpec{}
+	for _, spec := range specs {
+		if slices.Contains(sess.Target.Gatepost.ConfiguredSecrets, spec.Name) {
+			configured[spec.Name] = spec
+		}
+	}
+	listing := make([]gatepostSecretListing, 0, len(names))
+	for _, name := range names {
+		entry := gatepostSecretListing{Name: name, Source: "provider bootstrap"}
+		if spec, ok := configured[name]; ok {
+			entry.Source = "config (" + spec.Source() + ")"
+			entry.Host = spec.Host
+		}
+		listing = append(listing, entry)
+	}
+
+	if gatepostSecretsJSONFlag {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(listing)
+	}
+	if len(listing) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No secrets registered.")
+		return nil
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tHOST\tSOURCE")
+	for _, entry := range listing {
+		host := entry.Host
+		if host == "" {
+			host = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Name, host, entry.Source)
+	}
+	return w.Flush()
+}
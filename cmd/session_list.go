@@ -1,20 +1,25 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/jfox85/devx/caddy"
 	"github.com/jfox85/devx/config"
 	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+var sessionListStatsFlag bool
+
 var sessionListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all development sessions",
@@ -23,6 +28,7 @@ var sessionListCmd = &cobra.Command{
 }
 
 func init() {
+	sessionListCmd.Flags().BoolVar(&sessionListStatsFlag, "stats", false, "Show live CPU, memory and PIDs of container sessions")
 	sessionCmd.AddCommand(sessionListCmd)
 }
 
@@ -39,6 +45,10 @@ type SessionStatus struct {
 	Path           string
 	GatepostLogs   string
 	GatepostBypass bool
+	GatepostPhase  string
+	Services       []target.ComposeService // compose sessions only
+	ImageOutdated  bool                    // project image no longer matches the project's committed build inputs
+	Stats          *target.ResourceStats   // with --stats, for running containers
 }
 
 func runSessionList(cmd *cobra.Command, args []string) error {
@@ -62,6 +72,7 @@ func runSessionList(cmd *cobra.Command, args []string) error {
 
 	// Collect session statuses
 	var statuses []SessionStatus
+	currentImages := make(map[string]string) // project path -> current project image
 	for name, sess := range store.Sessions {
 		status := SessionStatus{
 			Name:         name,
@@ -77,6 +88,23 @@ func runSessionList(cmd *cobra.Command, args []string) error {
 		if sess.Target.Gatepost.Enabled {
 			status.GatepostLogs = sess.Target.Gatepost.LogsURL
 			status.GatepostBypass = sess.Target.Gatepost.Bypass
+			status.GatepostPhase = sess.Target.Gatepost.CurrentPhase()
+		}
+		if sess.IsContainerized() && sess.ProjectPath != "" {
+			project := imageProjectName(status.ProjectAlias, sess.ProjectPath)
+			current, ok := currentImages[sess.ProjectPath]
+			if !ok {
+				if cfg, err := projectOrGlobalConfig(sess.ProjectPath); err == nil && cfg.Docker.Build.Enabled() {
+					current, _ = currentProjectImage(project, sess.ProjectPath, cfg.Docker.Build)
+				}
+				currentImages[sess.ProjectPath] = current
+			}
+			status.ImageOutdated = sessionImageOutdated(sess, project, current)
+		}
+		if sess.TargetType() == "compose" {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			status.Services, _ = target.ComposeServices(ctx, sess.Target)
+			cancel()
 		}
 
 		// Check tmux status
@@ -96,13 +124,37 @@ func runSessionList(cmd *cobra.Command, args []string) error {
 		statuses = append(statuses, status)
 	}
 
+	if sessionListStatsFlag {
+		metas := make(map[string]session.TargetMeta, len(store.Sessions))
+		for name, sess := range store.Sessions {
+			metas[name] = sess.Target
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		stats := target.CollectResourceStats(ctx, metas)
+		cancel()
+		target.RecordOOMKills(store, stats)
+		for i := range statuses {
+			statuses[i].Stats = stats[statuses[i].Name]
+		}
+	}
+
 	// Sort by name
 	sort.Slice(statuses, func(i, j int) bool {
 		return statuses[i].Name < statuses[j].Name
 	})
 
 	// Display results
-	displaySessionList(statuses, caddyRoutes)
+	displaySessionList(statuses, caddyRoutes, sessionListStatsFlag)
+
+	var outdated []string
+	for _, status := range statuses {
+		if status.ImageOutdated {
+			outdated = append(outdated, status.Name)
+		}
+	}
+	if len(outdated) > 0 {
+		fmt.Printf("\nWarning: %s running an outdated image; recreate to pick up Dockerfile changes (see 'devx image list')\n", strings.Join(outdated, ", "))
+	}
 	return nil
 }
 
@@ -209,13 +261,18 @@ func hasActiveCaddyRoute(status SessionStatus, caddyRoutes map[string]bool) bool
 	return false
 }
 
-func displaySessionList(statuses []SessionStatus, caddyRoutes map[string]bool) {
+func displaySessionList(statuses []SessionStatus, caddyRoutes map[string]bool, showStats bool) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	defer w.Flush()
 
 	// Header
-	fmt.Fprintln(w, "  NAME\tBRANCH\tPORTS\tHOSTS\tSTATUS")
-	fmt.Fprintln(w, "  ----\t------\t-----\t-----\t------")
+	if showStats {
+		fmt.Fprintln(w, "  NAME\tBRANCH\tPORTS\tHOSTS\tSTATUS\tRESOURCES")
+		fmt.Fprintln(w, "  ----\t------\t-----\t-----\t------\t---------")
+	} else {
+		fmt.Fprintln(w, "  NAME\tBRANCH\tPORTS\tHOSTS\tSTATUS")
+		fmt.Fprintln(w, "  ----\t------\t-----\t-----\t------")
+	}
 
 	for _, status := range statuses {
 		// Format ports
@@ -265,11 +322,25 @@ func displaySessionList(statuses []SessionStatus, caddyRoutes map[string]bool) {
 		if status.GatepostLogs != "" {
 			if status.GatepostBypass {
 				statusParts = append(statusParts, "gatepost:bypass")
+			} else if status.GatepostPhase != "run" {
+				statusParts = append(statusParts, "gatepost:"+status.GatepostPhase)
 			} else {
 				statusParts = append(statusParts, "gatepost:on")
 			}
 		}
 
+		// Compose service states
+		for _, svc := range status.Services {
+			statusParts = append(statusParts, "compose:"+composeServiceStatus(svc))
+		}
+
+		if status.ImageOutdated {
+			statusParts = append(statusParts, "image:outdated")
+		}
+		if status.Stats.AtMemoryLimit() {
+			statusParts = append(statusParts, "mem:limit")
+		}
+
 		// Caddy status
 		if hasActiveCaddyRoute(status, caddyRoutes) {
 			statusParts = append(statusParts, "caddy:active")
@@ -289,6 +360,18 @@ func displaySessionList(statuses []SessionStatus, caddyRoutes map[string]bool) {
 			nameDisplay = fmt.Sprintf("%s (%s)", status.DisplayName, status.Name)
 		}
 
+		if showStats {
+			fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\t%s\t%s\n",
+				dot,
+				nameDisplay,
+				status.Branch,
+				portsStr,
+				hostsStr,
+				statusStr,
+				status.Stats.String(),
+			)
+			continue
+		}
 		fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\t%s\n",
 			dot,
 			nameDisplay,
@@ -299,3 +382,13 @@ func displaySessionList(statuses []SessionStatus, caddyRoutes map[string]bool) {
 		)
 	}
 }
+
+// composeServiceStatus renders a compose service as "db=running", preferring
+// the health check result when the service has one.
+func composeServiceStatus(svc target.ComposeService) string {
+	state := svc.State
+	if svc.Health != "" {
+		state = svc.Health
+	}
+	return svc.Service + "=" + state
+}
@@ -1,6 +1,10 @@
 package cmd
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/jfox85/devx/target"
+)
 
 func TestHasActiveCaddyRouteWithProjectPrefixedRouteID(t *testing.T) {
 	status := SessionStatus{
@@ -36,3 +40,12 @@ func TestHasActiveCaddyRouteRejectsWrongProjectPrefix(t *testing.T) {
 		t.Fatal("wrong project-prefixed route ID should not be active")
 	}
 }
+
+func TestComposeServiceStatusPrefersHealth(t *testing.T) {
+	if got := composeServiceStatus(target.ComposeService{Service: "db", State: "running", Health: "healthy"}); got != "db=healthy" {
+		t.Fatalf("got %q, want db=healthy", got)
+	}
+	if got := composeServiceStatus(target.ComposeService{Service: "redis", State: "exited"}); got != "redis=exited" {
+		t.Fatalf("got %q, want redis=exited", got)
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jfox85/devx/caddy"
+	"github.com/jfox85/devx/config"
+	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var sessionRefreshCmd = &cobra.Command{
+	Use:   "refresh <session-name>",
+	Short: "Re-render a session's bootstrap templates",
+	Long: `Re-render the project's bootstrap_templates into an existing session
+worktree using the session's current ports, hostnames and target.
+
+Rendered files are overwritten, so run this after changing a template or
+the external domain instead of recreating the session.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionRefresh,
+}
+
+func init() {
+	sessionCmd.AddCommand(sessionRefreshCmd)
+}
+
+func runSessionRefresh(cmd *cobra.Command, args []string) error {
+	sessionName := args[0]
+
+	store, err := session.LoadSessions()
+	if err != nil {
+		return fmt.Errorf("failed to load sessions: %w", err)
+	}
+	sess, exists := store.GetSession(sessionName)
+	if !exists {
+		return fmt.Errorf("session '%s' not found", sessionName)
+	}
+
+	projectPath := sess.ProjectPath
+	if projectPath == "" {
+		return fmt.Errorf("session '%s' has no project path; recreate it to use bootstrap templates", sessionName)
+	}
+	cfg, err := config.GetProjectConfig(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to load project config: %w", err)
+	}
+	if cfg == nil {
+		if cfg, err = config.LoadConfig(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+	}
+	if len(cfg.BootstrapTemplates) == 0 {
+		fmt.Printf("No bootstrap_templates configured for session '%s'\n", sessionName)
+		return nil
+	}
+
+	data := bootstrapTemplateData(sess.Name, sess.Path, sess.Target.Remote.RemotePath, sess.ProjectAlias, projectPath, sess.Target.Type, sess.Ports, sess.Routes)
+	if err := session.RenderBootstrapTemplates(projectPath, sess.Path, cfg.BootstrapTemplates, data); err != nil {
+		return err
+	}
+	if sess.TargetType() == "remote" {
+		files := bootstrapTemplateFiles(cfg.BootstrapTemplates)
+		if err := target.SyncRemoteFiles(context.Background(), sess.Target, sess.Path, files); err != nil {
+			return fmt.Errorf("failed to sync rendered templates to remote: %w", err)
+		}
+	}
+	return nil
+}
+
+// bootstrapTemplateFiles lists the worktree files bootstrap templates render
+// to, for syncing them to a remote checkout.
+func bootstrapTemplateFiles(templates []config.BootstrapTemplate) []string {
+	files := make([]string, 0, len(templates))
+	for _, tmpl := range templates {
+		files = append(files, tmpl.Destination())
+	}
+	return files
+}
+
+// sessionRuntimePath returns where a session's worktree appears to the
+// processes running in it: /workspace inside containers, the remote checkout
+// for remote sessions, and the worktree itself otherwise.
+func sessionRuntimePath(targetType, worktreePath, remotePath string) string {
+	switch targetType {
+	case "docker", "podman", "compose", "gatepost":
+		return "/workspace"
+	case "remote":
+		return remotePath
+	default:
+		return worktreePath
+	}
+}
+
+// bootstrapTemplateData assembles the template data for a session. Path is
+// the worktree as seen by the session's processes, matching the tmuxp config.
+func bootstrapTemplateData(name, worktreePath, remotePath, projectAlias, projectPath, targetType string, ports map[string]int, routes map[string]string) session.BootstrapTemplateData {
+	if targetType == "" {
+		targetType = "host"
+	}
+	sessionPath := sessionRuntimePath(targetType, worktreePath, remotePath)
+
+	externalRoutes := make(map[string]string)
+	if domain := viper.GetString("external_domain"); domain != "" {
+		for serviceName := range ports {
+			if h := caddy.BuildExternalHostname(name, serviceName, projectAlias, domain); h != "" {
+				externalRoutes[serviceName] = h
+			}
+		}
+	}
+	if routes == nil {
+		routes = make(map[string]string)
+	}
+
+	return session.BootstrapTemplateData{
+		Name:           name,
+		Path:           sessionPath,
+		HostPath:       worktreePath,
+		Ports:          ports,
+		Routes:         routes,
+		ExternalRoutes: externalRoutes,
+		ProjectAlias:   projectAlias,
+		ProjectPath:    projectPath,
+		Target:         targetType,
+		Env: session.EnvrcVars(session.EnvrcData{
+			Ports:          ports,
+			Routes:         routes,
+			ExternalRoutes: externalRoutes,
+			Name:           name,
+		}),
+	}
+}
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jfox85/devx/config"
+	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	retargetTargetFlag string
+	retargetImageFlag  string
+	retargetNoTmuxFlag bool
+)
+
+var sessionRetargetCmd = &cobra.Command{
+	Use:   "retarget <session-name> --target host|docker|podman|gatepost",
+	Short: "Move a session to another target without recreating it",
+	Long: `Stop a session's current target and start a new one on the same worktree,
+ports and environment. The tmuxp config is regenerated for the new target and
+the tmux session is recreated; hostnames and routes are unchanged. Memory
+and CPU limits and a devcontainer the session was created with carry over.
+
+Processes running in the old target's panes are stopped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionRetarget,
+}
+
+func init() {
+	sessionRetargetCmd.Flags().StringVar(&retargetTargetFlag, "target", "", "New target: host, docker, podman or gatepost")
+	sessionRetargetCmd.Flags().StringVar(&retargetImageFlag, "image", "", "Container image for docker, podman or gatepost targets")
+	sessionRetargetCmd.Flags().BoolVar(&retargetNoTmuxFlag, "no-tmux", false, "Don't recreate the tmux session")
+	_ = sessionRetargetCmd.MarkFlagRequired("target")
+	sessionCmd.AddCommand(sessionRetargetCmd)
+}
+
+// retargetable reports whether a session can move between two targets.
+// Compose, sandbox and remote sessions need state (a compose stack, a
+// trusted sandbox layout, a remote checkout) that retargeting cannot carry
+// over, so only host, docker, podman and gatepost are supported.
+func retargetable(from, to string) error {
+	switch to {
+	case "host", "docker", "podman", "gatepost":
+	default:
+		return fmt.Errorf("cannot retarget to %q (supported: host, docker, podman, gatepost)", to)
+	}
+	switch from {
+	case "host", "docker", "podman", "gatepost":
+	default:
+		return fmt.Errorf("cannot retarget a %s session (supported: host, docker, podman, gatepost)", from)
+	}
+	if from == to {
+		return fmt.Errorf("session already uses the %s target", to)
+	}
+	return nil
+}
+
+func runSessionRetarget(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	newType := retargetTargetFlag
+
+	store, err := session.LoadSessions()
+	if err != nil {
+		return fmt.Errorf("failed to load sessions: %w", err)
+	}
+	sess, ok := store.GetSession(name)
+	if !ok {
+		return fmt.Errorf("session '%s' not found", name)
+	}
+	oldType := sess.TargetType()
+	if err := retargetable(oldType, newType); err != nil {
+		return err
+	}
+	if retargetImageFlag != "" && newType == "host" {
+		return fmt.Errorf("--image requires a container target")
+	}
+	switch newType {
+	case "docker", "gatepost":
+		if err := target.CheckAvailable(); err != nil {
+			return err
+		}
+	case "podman":
+		if err := target.CheckPodmanAvailable(); err != nil {
+			return err
+		}
+	}
+	newTgt, err := target.Resolve(newType)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := projectOrGlobalConfig(sess.ProjectPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	opts := target.StartOpts{
+		SessionName:  name,
+		WorktreePath: sess.Path,
+		HostPorts:    sess.Ports,
+	}
+	if newType != "host" {
+		if opts, err = retargetContainerOpts(opts, sess, cfg, newType); err != nil {
+			return err
+		}
+	}
+
+	// Tear down the old runtime and its tmux sessions.
+	ctx := context.Background()
+	if err := target.KillTmuxServer(sess.Target); err != nil {
+		fmt.Printf("Warning: failed to kill target tmux server: %v\n", err)
+	}
+	if err := killTmuxSession(name); err != nil {
+		fmt.Printf("Warning: failed to kill tmux session: %v\n", err)
+	}
+	if err := killTmuxSession(name + "-web"); err != nil {
+		fmt.Printf("Warning: failed to kill web tmux session: %v\n", err)
+	}
+	if oldTgt, err := target.Resolve(oldType); err == nil {
+		if err := oldTgt.Stop(ctx, sess.Target); err != nil {
+			return fmt.Errorf("failed to stop %s target: %w", oldType, err)
+		}
+		if oldType != "host" {
+			fmt.Printf("Stopped target runtime '%s'\n", target.RuntimeName(sess.Target))
+		}
+	}
+
+	result, err := newTgt.Start(ctx, opts)
+	if err != nil {
+		// The old runtime is gone; fall back to host so the session stays usable.
+		_ = store.UpdateSession(name, func(s *session.Session) {
+			s.Target = retargetMeta(session.TargetMeta{Type: "host"}, sess.Target, target.StartOpts{})
+		})
+		if !retargetNoTmuxFlag {
+			if updated, ok := store.GetSession(name); ok {
+				if err := target.EnsureTmuxSession(name, updated); err != nil {
+					fmt.Printf("Warning: failed to recreate tmux session: %v\n", err)
+				}
+			}
+		}
+		return fmt.Errorf("failed to start %s target (session left on host): %w", newType, err)
+	}
+	if err := store.UpdateSession(name, func(s *session.Session) {
+		s.Target = retargetMeta(result.Meta, sess.Target, opts)
+	}); err != nil {
+		return fmt.Errorf("failed to save target metadata: %w", err)
+	}
+
+	// Panes run in the worktree on the host or at /workspace in a container;
+	// container panes get their exec wrapping when tmux launches them.
+	data := bootstrapTemplateData(name, sess.Path, "", sess.ProjectAlias, sess.ProjectPath, newType, sess.Ports, sess.Routes)
+	tmuxpData := session.TmuxpData{
+		Name:           name,
+		Path:           data.Path,
+		Ports:          sess.Ports,
+		Routes:         data.Routes,
+		ExternalRoutes: data.ExternalRoutes,
+	}
+	if err := session.GenerateTmuxpConfig(sess.Path, tmuxpData, sess.ProjectPath); err != nil {
+		return fmt.Errorf("failed to generate tmuxp config: %w", err)
+	}
+	if len(cfg.BootstrapTemplates) > 0 && sess.ProjectPath != "" {
+		if err := session.RenderBootstrapTemplates(sess.ProjectPath, sess.Path, cfg.BootstrapTemplates, data); err != nil {
+			fmt.Printf("Warning: failed to re-render bootstrap templates: %v\n", err)
+		}
+	}
+
+	fmt.Printf("Retargeted session '%s' from %s to %s\n", name, oldType, target.RuntimeName(result.Meta))
+	if retargetNoTmuxFlag {
+		return nil
+	}
+	updated, ok := store.GetSession(name)
+	if !ok {
+		return nil
+	}
+	if err := target.EnsureTmuxSession(name, updated); err != nil {
+		fmt.Printf("Warning: failed to recreate tmux session: %v\n", err)
+		fmt.Printf("You can start it with: devx session attach %s\n", name)
+	}
+	return nil
+}
+
+// retargetMeta carries the limits and devcontainer a session was created
+// with, and the hosts allowed for it, onto the metadata of its new target, so
+// they survive retargeting through a target that doesn't use them.
+func retargetMeta(meta, old session.TargetMeta, opts target.StartOpts) session.TargetMeta {
+	meta.MemoryLimit, meta.CPULimit = old.MemoryLimit, old.CPULimit
+	if opts.Security.MemoryLimit != "" {
+		meta.MemoryLimit = opts.Security.MemoryLimit
+	}
+	if opts.Security.CPULimit != "" {
+		meta.CPULimit = opts.Security.CPULimit
+	}
+	if meta.Devcontainer.Config == "" {
+		meta.Devcontainer = old.Devcontainer
+	}
+	if meta.Gatepost.AllowedHosts == nil {
+		meta.Gatepost.AllowedHosts = old.Gatepost.AllowedHosts
+	}
+	return meta
+}
+
+// retargetContainerOpts fills in the container settings the session was
+// created with: image, env, limits, caches and its devcontainer, if any.
+func retargetContainerOpts(opts target.StartOpts, sess *session.Session, cfg *config.Config, targetType string) (target.StartOpts, error) {
+	ctx := context.Background()
+	image := retargetImageFlag
+	if image == "" {
+		if targetType == "gatepost" {
+			image = viper.GetString("gatepost.agent_image")
+			if image == "" {
+				image = "gatepost-pi-agent:latest"
+			}
+		} else {
+			image = viper.GetString("docker.image")
+			if image == "" {
+				image = "devx-session-base:latest"
+			}
+		}
+	}
+	// A session created from a devcontainer keeps using it; an explicit
+	// --image wins, as it does at create.
+	var devcontainer *target.Devcontainer
+	if retargetImageFlag == "" && targetType == "docker" && sess.Target.Devcontainer.Config != "" {
+		dc, err := target.PrepareDevcontainer(ctx, sess.Path, orderedServices(cfg.Ports, sess.Ports))
+		if err != nil {
+			return opts, fmt.Errorf("failed to prepare devcontainer: %w", err)
+		}
+		if dc == nil {
+			return opts, fmt.Errorf("session was created from %s, which no longer exists", sess.Target.Devcontainer.Config)
+		}
+		for _, w := range dc.Warnings {
+			fmt.Printf("Warning: devcontainer: %s\n", w)
+		}
+		image = dc.Image
+		devcontainer = dc
+	}
+	project := imageProjectName(sess.ProjectAlias, sess.ProjectPath)
+	if devcontainer == nil && retargetImageFlag == "" && targetType == "docker" && cfg.Docker.Build.Enabled() {
+		spec, err := projectImageSpec(project, sess.ProjectPath, cfg.Docker.Build)
+		if err != nil {
+			return opts, err
+		}
+		tag, built, err := target.EnsureProjectImage(ctx, spec)
+		if err != nil {
+			return opts, fmt.Errorf("failed to build project image: %w", err)
+		}
+		if built {
+			fmt.Printf("Built project image %s\n", tag)
+		}
+		image = tag
+	}
+	if image == "devx-session-base:latest" {
+		if targetType == "docker" && !target.ImageExists(image) {
+			return opts, fmt.Errorf("devx-session-base image not found. Build it first:\n  docker build -t devx-session-base:latest docker/")
+		}
+		if targetType == "podman" && !target.PodmanImageExists(image) {
+			return opts, fmt.Errorf("devx-session-base image not found in podman storage. Build it first:\n  podman build -t devx-session-base:latest docker/")
+		}
+	}
+
+	security, err := resolveSessionSecurityOpts(targetType, cfg, sess.Target.MemoryLimit, sess.Target.CPULimit)
+	if err != nil {
+		return opts, err
+	}
+	var cacheUser string
+	if devcontainer != nil {
+		cacheUser = devcontainer.User
+	}
+	caches, err := target.ResolveCacheMounts(project, targetType, cacheUser, cacheSpecs(cfg.Docker.Caches))
+	if err != nil {
+		return opts, fmt.Errorf("docker.caches: %w", err)
+	}
+
+	opts.Image = image
+	opts.Env = containerSessionEnv(sess.Name, sess.Ports, sess.Routes)
+	if devcontainer != nil {
+		for svc, port := range devcontainer.Ports {
+			opts.Env[strings.ToUpper(svc)+"_PORT"] = fmt.Sprintf("%d", port)
+		}
+	}
+	opts.Devcontainer = devcontainer
+	opts.Labels = map[string]string{
+		"devx.session": sess.Name,
+		"devx.project": sess.ProjectAlias,
+	}
+	opts.Security = security
+	opts.Caches = caches
+	if targetType == "gatepost" {
+		if opts.GatepostConfig, err = sessionGatepostRuntimeConfig(sess.ProjectAlias); err != nil {
+			return opts, err
+		}
+		if opts.GatepostConfig.Policy, err = sessionGatepostPolicyYAML(sess); err != nil {
+			return opts, err
+		}
+		opts.GatepostConfig.InstallCommands = cfg.Gatepost.InstallCommands
+	}
+	if targetType == "docker" {
+		// Keep a network mode chosen when the session was created.
+		if opts.Network, err = resolveDockerNetwork(sess.Target.Network.Mode, cfg.Docker.Network, sess.ProjectPath); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
@@ -33,7 +33,7 @@ never deletes sessions or modifies worktree contents.`,
 
 func init() {
 	sessionCmd.AddCommand(sessionReviewCmd)
-	sessionReviewCmd.Flags().StringVar(&reviewBaseFlag, "base", "", "Base branch/ref to compare against (default: origin/main, main, origin/master, master)")
+	sessionReviewCmd.Flags().StringVar(&reviewBaseFlag, "base", "", "Base branch/ref (or jj revset) to compare against (default: origin/main, main, origin/master, master; trunk() for jj)")
 	sessionReviewCmd.Flags().BoolVar(&reviewJSONFlag, "json", false, "Print review as JSON")
 	sessionReviewCmd.Flags().BoolVar(&reviewNoPersistFlag, "no-persist", false, "Do not save the review result to session metadata")
 	sessionReviewCmd.Flags().StringVar(&reviewHarnessFlag, "harness", "", "Name of agent harness used for review output")
@@ -34,16 +34,18 @@ func init() {
 
 func runSessionRm(cmd *cobra.Command, args []string) error {
 	return removeSessionByName(args[0], removeSessionOptions{
-		SkipConfirm:      forceFlag,
-		DiscardArtifacts: forceFlag,
-		SyncRoutes:       true,
+		SkipConfirm:             forceFlag,
+		DiscardArtifacts:        forceFlag,
+		DiscardSubmoduleCommits: forceFlag,
+		SyncRoutes:              true,
 	})
 }
 
 type removeSessionOptions struct {
-	SkipConfirm      bool
-	DiscardArtifacts bool
-	SyncRoutes       bool
+	SkipConfirm             bool
+	DiscardArtifacts        bool
+	DiscardSubmoduleCommits bool
+	SyncRoutes              bool
 }
 
 func removeSessionByName(name string, opts removeSessionOptions) error {
@@ -59,6 +61,14 @@ func removeSessionByName(name string, opts removeSessionOptions) error {
 		return fmt.Errorf("session '%s' not found", name)
 	}
 
+	// Submodule commits live only in the worktree's module directories, so
+	// removing the worktree would lose any that were never pushed.
+	if !opts.DiscardSubmoduleCommits {
+		if err := checkSubmoduleCommits(sess); err != nil {
+			return err
+		}
+	}
+
 	// Confirm deletion unless force flag is used
 	if !opts.SkipConfirm {
 		fmt.Printf("This will remove session '%s' and its worktree at %s\n", name, sess.Path)
@@ -179,6 +189,25 @@ func removeSessionByName(name string, opts removeSessionOptions) error {
 	return nil
 }
 
+func checkSubmoduleCommits(sess *session.Session) error {
+	if _, err := os.Stat(sess.Path); err != nil {
+		return nil
+	}
+	subs, err := session.InspectSubmodules(sess.Path)
+	if err != nil {
+		return fmt.Errorf("refusing to remove session '%s': %w; rerun with --force to remove anyway", sess.Name, err)
+	}
+	unpushed := session.SubmodulesWithUnpushedCommits(subs)
+	if len(unpushed) == 0 {
+		return nil
+	}
+	var paths []string
+	for _, sub := range unpushed {
+		paths = append(paths, sub.Path)
+	}
+	return fmt.Errorf("refusing to remove session '%s': submodule(s) %s hold commits not pushed to any remote; push them or rerun with --force to discard", sess.Name, strings.Join(paths, ", "))
+}
+
 func removeGatepostStateDir(sess *session.Session) error {
 	dir := sess.Target.Gatepost.SessionDir
 	if dir == "" {
@@ -309,26 +338,24 @@ func removeGitWorktree(sess *session.Session) error {
 		return nil // already removed
 	}
 
-	// Use git worktree remove command with --force flag as specified in requirements.
-	// This is allowed for paths registered with git; if it fails, manual fallback
-	// is gated by validateManualWorktreeRemoval below.
-	cmd := exec.Command("git", "worktree", "remove", "--force", worktreePath)
-	if sess.ProjectPath != "" {
-		cmd.Dir = sess.ProjectPath
-	}
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// If git command fails, try manual removal only for canonical managed paths.
+	// Remove through the VCS (git worktree remove --force, or jj workspace
+	// forget). This is allowed for paths registered with the repository; if it
+	// fails, manual fallback is gated by validateManualWorktreeRemoval below.
+	backend := session.VCSForSession(sess)
+	if vcsErr := backend.RemoveWorkspace(sess.ProjectPath, sess.Name, worktreePath); vcsErr != nil {
+		// If the VCS command fails, try manual removal only for canonical managed paths.
 		if err := validateManualWorktreeRemoval(sess); err != nil {
-			return fmt.Errorf("refusing manual worktree removal after git error %q: %w", strings.TrimSpace(string(output)), err)
+			return fmt.Errorf("refusing manual worktree removal after %s error %q: %w", backend.Name(), vcsErr.Error(), err)
 		}
 		if removeErr := os.RemoveAll(worktreePath); removeErr != nil {
-			return fmt.Errorf("failed to remove worktree: git error: %v; manual removal error: %v",
-				string(output), removeErr)
+			return fmt.Errorf("failed to remove worktree: %s error: %v; manual removal error: %v",
+				backend.Name(), vcsErr, removeErr)
 		}
 		fmt.Printf("Manually removed worktree directory\n")
-	} else {
+	} else if backend.Name() == session.VCSGit {
 		fmt.Printf("Removed git worktree\n")
+	} else {
+		fmt.Printf("Removed %s workspace\n", backend.Name())
 	}
 
 	return nil
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jfox85/devx/session"
+	"github.com/spf13/cobra"
+)
+
+var sessionSparseCmd = &cobra.Command{
+	Use:   "sparse <session-name> <list|add|remove> [path...]",
+	Short: "Inspect or adjust a session's sparse checkout",
+	Long: `Inspect or adjust the cone-mode sparse checkout of a session worktree.
+
+  devx session sparse my-session list
+  devx session sparse my-session add apps/api libs/shared
+  devx session sparse my-session remove libs/shared
+
+Adding paths to a full checkout converts it into a sparse checkout.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runSessionSparse,
+}
+
+func init() {
+	sessionCmd.AddCommand(sessionSparseCmd)
+}
+
+func runSessionSparse(cmd *cobra.Command, args []string) error {
+	sessionName, action := args[0], args[1]
+	paths := session.ParseSparsePaths(args[2:])
+
+	store, err := session.LoadSessions()
+	if err != nil {
+		return fmt.Errorf("failed to load sessions: %w", err)
+	}
+	sess, exists := store.GetSession(sessionName)
+	if !exists {
+		return fmt.Errorf("session '%s' not found", sessionName)
+	}
+
+	switch action {
+	case "list":
+		current, err := session.SparseCheckoutList(sess.Path)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			fmt.Printf("Session '%s' has a full checkout\n", sessionName)
+			return nil
+		}
+		for _, p := range current {
+			fmt.Println(p)
+		}
+		return nil
+	case "add", "remove":
+		if len(paths) == 0 {
+			return fmt.Errorf("specify at least one path to %s", action)
+		}
+		if action == "add" {
+			err = session.SparseCheckoutAdd(sess.Path, paths)
+		} else {
+			err = session.SparseCheckoutRemove(sess.Path, paths)
+		}
+		if err != nil {
+			return err
+		}
+		current, err := session.SparseCheckoutList(sess.Path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Sparse checkout for '%s': %s\n", sessionName, strings.Join(current, ", "))
+		return nil
+	default:
+		return fmt.Errorf("unknown sparse action %q (valid: list, add, remove)", action)
+	}
+}
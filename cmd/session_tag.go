@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jfox85/devx/session"
+	"github.com/spf13/cobra"
+)
+
+var sessionTagRemove bool
+
+var sessionTagCmd = &cobra.Command{
+	Use:   "tag <session-name> [tag...]",
+	Short: "Show, add or remove a session's tags",
+	Long: `Show, add or remove a session's tags. Tags address groups of sessions,
+e.g. devx ask --to-tag backend "question".`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSessionTag,
+}
+
+func init() {
+	sessionTagCmd.Flags().BoolVar(&sessionTagRemove, "remove", false, "Remove the given tags instead of adding them")
+	sessionCmd.AddCommand(sessionTagCmd)
+}
+
+func runSessionTag(cmd *cobra.Command, args []string) error {
+	sessionName := args[0]
+	store, err := session.LoadSessions()
+	if err != nil {
+		return fmt.Errorf("failed to load sessions: %w", err)
+	}
+	sess, exists := store.GetSession(sessionName)
+	if !exists {
+		return fmt.Errorf("session '%s' not found", sessionName)
+	}
+	if len(args) == 1 {
+		fmt.Fprintln(cmd.OutOrStdout(), strings.Join(sess.Tags, " "))
+		return nil
+	}
+	for _, tag := range args[1:] {
+		if strings.TrimSpace(tag) == "" || strings.ContainsAny(tag, " \t:,") {
+			return fmt.Errorf("invalid tag %q: tags cannot be empty or contain spaces, commas or colons", tag)
+		}
+	}
+
+	var tags []string
+	if err := store.UpdateSession(sessionName, func(s *session.Session) {
+		tags = applyTags(s.Tags, args[1:], sessionTagRemove)
+		s.Tags = tags
+	}); err != nil {
+		return fmt.Errorf("failed to update tags: %w", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Tags for session '%s': %s\n", sessionName, strings.Join(tags, " "))
+	notifySessionUpdated(sessionName)
+	return nil
+}
+
+// applyTags adds or removes tags, matching case-insensitively and keeping the
+// result sorted.
+func applyTags(current, changes []string, remove bool) []string {
+	has := func(list []string, tag string) bool {
+		return slices.ContainsFunc(list, func(t string) bool { return strings.EqualFold(t, tag) })
+	}
+	var out []string
+	for _, tag := range current {
+		if !remove || !has(changes, tag) {
+			out = append(out, tag)
+		}
+	}
+	if !remove {
+		for _, tag := range changes {
+			if !has(out, tag) {
+				out = append(out, tag)
+			}
+		}
+	}
+	slices.Sort(out)
+	return out
+}
@@ -6,6 +6,10 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/jfox85/devx/config"
+	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
 )
 
 func initTempRepo(t *testing.T) string {
@@ -192,3 +196,201 @@ func TestTmuxpConfigGeneration(t *testing.T) {
 		t.Error("tmuxp config should contain windows section")
 	}
 }
+
+func TestResolveSecurityOptsLayering(t *testing.T) {
+	readOnly := true
+	presets := map[string]config.SecurityConfig{
+		"ci": {Preset: "small", Pids: 256, CapAdd: []string{"NET_BIND_SERVICE"}},
+	}
+
+	opts, err := resolveSecurityOpts(config.SecurityConfig{Preset: "ci", CPUs: "3", ReadOnlyRoot: &readOnly}, presets, "6g", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.MemoryLimit != "6g" || opts.CPULimit != "3" || opts.PidsLimit != 256 || !opts.ReadOnlyRoot {
+		t.Errorf("layered opts = %+v", opts)
+	}
+	if len(opts.CapAdd) != 1 || opts.CapAdd[0] != "NET_BIND_SERVICE" {
+		t.Errorf("cap_add from preset lost: %v", opts.CapAdd)
+	}
+
+	opts, err = resolveSecurityOpts(config.SecurityConfig{Preset: "large"}, nil, "", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.MemoryLimit != "16g" || opts.CPULimit != "1" {
+		t.Errorf("built-in preset with --cpus = %+v", opts)
+	}
+
+	if _, err := resolveSecurityOpts(config.SecurityConfig{Preset: "nope"}, nil, "", ""); err == nil {
+		t.Error("unknown preset should fail")
+	}
+	if _, err := resolveSecurityOpts(config.SecurityConfig{CapAdd: []string{"SYS_ADMIN"}}, nil, "", ""); err == nil {
+		t.Error("SYS_ADMIN should be rejected")
+	}
+}
+
+func TestRetargetable(t *testing.T) {
+	for _, tc := range []struct {
+		from, to string
+		ok       bool
+	}{
+		{"host", "docker", true},
+		{"docker", "gatepost", true},
+		{"gatepost", "host", true},
+		{"podman", "docker", true},
+		{"docker", "docker", false},
+		{"host", "compose", false},
+		{"remote", "host", false},
+		{"sandbox", "docker", false},
+	} {
+		if err := retargetable(tc.from, tc.to); (err == nil) != tc.ok {
+			t.Errorf("retargetable(%s, %s) = %v, want ok=%v", tc.from, tc.to, err, tc.ok)
+		}
+	}
+}
+
+func TestRetargetMetaKeepsCreateSettings(t *testing.T) {
+	old := session.TargetMeta{
+		Type:         "docker",
+		MemoryLimit:  "8g",
+		CPULimit:     "2",
+		Devcontainer: session.DevcontainerMeta{Config: "/wt/.devcontainer/devcontainer.json", User: "node"},
+	}
+
+	// Through host, which runs without limits or a devcontainer.
+	host := retargetMeta(session.TargetMeta{Type: "host"}, old, target.StartOpts{})
+	if host.Type != "host" || host.MemoryLimit != "8g" || host.CPULimit != "2" || host.Devcontainer != old.Devcontainer {
+		t.Fatalf("host meta = %+v", host)
+	}
+
+	// Back to a container, which reports the limits it was started with.
+	opts := target.StartOpts{Security: target.SecurityOpts{MemoryLimit: "8g", CPULimit: "2"}}
+	podman := retargetMeta(session.TargetMeta{Type: "podman", ContainerName: "devx-feat"}, host, opts)
+	if podman.ContainerName != "devx-feat" || podman.MemoryLimit != "8g" || podman.CPULimit != "2" || podman.Devcontainer != old.Devcontainer {
+		t.Fatalf("podman meta = %+v", podman)
+	}
+}
+
+func TestContainerSessionEnv(t *testing.T) {
+	env := containerSessionEnv("feat", map[string]int{"web": 41000}, map[string]string{"my-api": "feat-my-api.localhost"})
+	if env["WEB_PORT"] != "41000" || env["MY_API_HOST"] != "http://feat-my-api.localhost" || env["SESSION_NAME"] != "feat" {
+		t.Errorf("env = %v", env)
+	}
+}
+
+func TestApplyTags(t *testing.T) {
+	tags := applyTags([]string{"ui"}, []string{"backend", "UI"}, false)
+	if strings.Join(tags, ",") != "backend,ui" {
+		t.Errorf("add = %v", tags)
+	}
+	tags = applyTags(tags, []string{"Backend"}, true)
+	if strings.Join(tags, ",") != "ui" {
+		t.Errorf("remove = %v", tags)
+	}
+}
+
+func TestResolveSessionSecurityOptsGatepostIgnoresProject(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".config", "devx")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	global := "docker:\n  security:\n    preset: strict\n    cpus: \"2\"\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(global), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loose := false
+	cfg := &config.Config{}
+	cfg.Docker.Security = config.SecurityConfig{Preset: "large", ReadOnlyRoot: &loose, CapAdd: []string{"SYS_PTRACE"}}
+
+	opts, err := resolveSessionSecurityOpts("gatepost", cfg, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict, _ := target.SecurityPreset("strict")
+	if opts.CPULimit != "2" || opts.MemoryLimit != strict.MemoryLimit || opts.ReadOnlyRoot != strict.ReadOnlyRoot || len(opts.CapAdd) != 0 {
+		t.Errorf("gatepost opts = %+v, want the trusted strict preset", opts)
+	}
+
+	opts, err = resolveSessionSecurityOpts("docker", cfg, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.CapAdd) != 1 || opts.ReadOnlyRoot {
+		t.Errorf("docker opts = %+v, want the project settings", opts)
+	}
+}
+
+func TestSessionGatepostRuntimeConfigUsesTrustedPreset(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".config", "devx")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	global := `docker:
+  security:
+    preset: small
+gatepost:
+  secrets:
+    - name: small-token
+      env: SMALL_TOKEN
+      host: small.example.com
+      presets: [small]
+    - name: strict-token
+      env: STRICT_TOKEN
+      host: strict.example.com
+      presets: [strict]
+`
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(global), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := sessionGatepostRuntimeConfig("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Secrets) != 1 || cfg.Secrets[0].Name != "small-token" {
+		t.Errorf("secrets = %+v, want only small-token", cfg.Secrets)
+	}
+
+	bad := global + "    - name: broken\n      host: broken.example.com\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(bad), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := trustedGatepostRuntimeConfig(); err == nil {
+		t.Error("a secret without a source should fail validation")
+	}
+}
+
+func TestBootstrapTemplateFilesDefaultsToSrc(t *testing.T) {
+	files := bootstrapTemplateFiles([]config.BootstrapTemplate{
+		{Src: "templates/env.tmpl", Dst: ".env"},
+		{Src: "config/app.json"},
+	})
+	if len(files) != 2 || files[0] != ".env" || files[1] != "config/app.json" {
+		t.Fatalf("bootstrap template files = %q", files)
+	}
+}
+
+func TestSessionGatepostPolicyKeepsSessionHosts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sess := &session.Session{Name: "gp", ProjectPath: t.TempDir()}
+	sess.Target.Gatepost.AllowedHosts = map[string][]string{"run": {"api.example.com"}}
+
+	policy, err := sessionGatepostPolicyYAML(sess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(policy), "api.example.com") {
+		t.Fatalf("session host dropped from policy:\n%s", policy)
+	}
+	project, err := gatepostPolicyYAML(sess.ProjectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(project), "api.example.com") {
+		t.Fatalf("session host leaked into project policy:\n%s", project)
+	}
+}
@@ -69,7 +69,11 @@ func runWeb(cmd *cobra.Command, args []string) error {
 		return startWebDaemon(port)
 	}
 
-	srv, err := web.NewWithBind(token, port, bind, trustedGatepostRuntimeConfig())
+	gatepostCfg, err := trustedGatepostRuntimeConfig()
+	if err != nil {
+		return err
+	}
+	srv, err := web.NewWithBind(token, port, bind, gatepostCfg)
 	if err != nil {
 		return err
 	}
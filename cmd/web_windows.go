@@ -59,7 +59,11 @@ func runWeb(cmd *cobra.Command, args []string) error {
 	port := viper.GetInt("web_port")
 	bind := viper.GetString("web_bind")
 
-	srv, err := web.NewWithBind(token, port, bind, trustedGatepostRuntimeConfig())
+	gatepostCfg, err := trustedGatepostRuntimeConfig()
+	if err != nil {
+		return err
+	}
+	srv, err := web.NewWithBind(token, port, bind, gatepostCfg)
 	if err != nil {
 		return err
 	}
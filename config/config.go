@@ -4,35 +4,65 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Target                 string               `mapstructure:"target"`
-	BaseDomain             string               `mapstructure:"basedomain"`
-	CaddyAPI               string               `mapstructure:"caddy_api"`
-	TmuxpTemplate          string               `mapstructure:"tmuxp_template"`
-	Ports                  []string             `mapstructure:"ports"`
-	BootstrapFiles         []string             `mapstructure:"bootstrap_files"`
-	ExternalDomain         string               `mapstructure:"external_domain"`
-	CloudflareTunnelID     string               `mapstructure:"cloudflare_tunnel_id"`
-	CloudflareTunnelConfig string               `mapstructure:"cloudflare_tunnel_config"`
-	WebSecretToken         string               `mapstructure:"web_secret_token"`
-	WebPort                int                  `mapstructure:"web_port"`
-	WebAutostart           bool                 `mapstructure:"web_autostart"`
-	ArtifactTriggerKey     string               `mapstructure:"artifact_trigger_key"`
-	AgentResponder         AgentResponderConfig `mapstructure:"agent_responder"`
+	Target                 string                  `mapstructure:"target"`
+	VCS                    string                  `mapstructure:"vcs"`
+	BaseDomain             string                  `mapstructure:"basedomain"`
+	CaddyAPI               string                  `mapstructure:"caddy_api"`
+	TmuxpTemplate          string                  `mapstructure:"tmuxp_template"`
+	Ports                  []string                `mapstructure:"ports"`
+	BootstrapFiles         []string                `mapstructure:"bootstrap_files"`
+	BootstrapDirs          []string                `mapstructure:"bootstrap_dirs"`
+	BootstrapDirsMethod    string                  `mapstructure:"bootstrap_dirs_method"`
+	BootstrapDirLockfiles  map[string][]string     `mapstructure:"bootstrap_dir_lockfiles"`
+	BootstrapTemplates     []BootstrapTemplate     `mapstructure:"bootstrap_templates"`
+	ExternalDomain         string                  `mapstructure:"external_domain"`
+	CloudflareTunnelID     string                  `mapstructure:"cloudflare_tunnel_id"`
+	CloudflareTunnelConfig string                  `mapstructure:"cloudflare_tunnel_config"`
+	WebSecretToken         string                  `mapstructure:"web_secret_token"`
+	WebPort                int                     `mapstructure:"web_port"`
+	WebAutostart           bool                    `mapstructure:"web_autostart"`
+	ArtifactTriggerKey     string                  `mapstructure:"artifact_trigger_key"`
+	AgentResponder         AgentResponderConfig    `mapstructure:"agent_responder"`
+	Worktree               WorktreeConfig          `mapstructure:"worktree"`
+	Sandbox                SandboxConfig           `mapstructure:"sandbox"`
+	Remotes                map[string]RemoteConfig `mapstructure:"remotes"`
+	Compose                ComposeConfig           `mapstructure:"compose"`
+	Docker                 DockerConfig            `mapstructure:"docker"`
 	Gatepost               struct {
-		Root                     string `mapstructure:"root"`
-		AgentImage               string `mapstructure:"agent_image"`
-		LogsCommand              string `mapstructure:"logs_command"`
-		ProviderBootstrapCommand string `mapstructure:"provider_bootstrap_command"`
-		AuthHome                 string `mapstructure:"auth_home"`
-		RequiredProviders        string `mapstructure:"required_providers"`
+		Root                     string   `mapstructure:"root"`
+		AgentImage               string   `mapstructure:"agent_image"`
+		LogsCommand              string   `mapstructure:"logs_command"`
+		ProviderBootstrapCommand string   `mapstructure:"provider_bootstrap_command"`
+		AuthHome                 string   `mapstructure:"auth_home"`
+		RequiredProviders        string   `mapstructure:"required_providers"`
+		InstallCommands          []string `mapstructure:"install_commands"` // run under the install phase at session create
+		// Secrets are read from the global config only; a project config
+		// cannot point host credentials at arbitrary hosts.
+		Secrets []GatepostSecretConfig `mapstructure:"secrets"`
 	} `mapstructure:"gatepost"`
 }
 
+// GatepostSecretConfig is a credential the Gatepost proxy injects into
+// requests to Host. Exactly one of Env, File and Command supplies the value.
+type GatepostSecretConfig struct {
+	Name     string   `mapstructure:"name"`
+	Env      string   `mapstructure:"env"`      // host environment variable
+	File     string   `mapstructure:"file"`     // file holding the value; "~" is expanded
+	Command  string   `mapstructure:"command"`  // shell command printing the value
+	Host     string   `mapstructure:"host"`     // destination host pattern, e.g. *.example.com
+	Scheme   string   `mapstructure:"scheme"`   // bearer (default), header or basic
+	Header   string   `mapstructure:"header"`   // header name; Authorization for bearer and basic
+	Username string   `mapstructure:"username"` // basic auth user
+	Projects []string `mapstructure:"projects"` // limit to these project aliases
+	Presets  []string `mapstructure:"presets"`  // limit to these docker.security presets
+}
+
 type AgentResponderConfig struct {
 	Enabled  bool     `mapstructure:"enabled"`
 	Mode     string   `mapstructure:"mode"`
@@ -40,6 +70,130 @@ type AgentResponderConfig struct {
 	Args     []string `mapstructure:"args"`
 	Timeout  string   `mapstructure:"timeout"`
 	ReadOnly bool     `mapstructure:"read_only"`
+	Parallel int      `mapstructure:"parallel"` // responders a broadcast runs at once
+}
+
+// BootstrapTemplate is a project file rendered as a Go template with session
+// data and written into each new worktree.
+type BootstrapTemplate struct {
+	Src string `mapstructure:"src"`
+	Dst string `mapstructure:"dst"` // defaults to Src
+}
+
+// Destination returns the worktree-relative path the template is rendered
+// to: Dst, or Src when Dst is unset.
+func (t BootstrapTemplate) Destination() string {
+	if strings.TrimSpace(t.Dst) != "" {
+		return t.Dst
+	}
+	return t.Src
+}
+
+// WorktreeConfig controls how session worktrees are materialized, mainly to
+// keep monorepo worktrees small and fast to create.
+type WorktreeConfig struct {
+	Sparse        []string            `mapstructure:"sparse"`         // default cone-mode sparse paths
+	SparsePresets map[string][]string `mapstructure:"sparse_presets"` // named cone path sets
+	SkipLFS       bool                `mapstructure:"skip_lfs"`       // skip LFS smudge on checkout
+	Filter        string              `mapstructure:"filter"`         // partial-clone filter, e.g. "blob:none"
+	Submodules    bool                `mapstructure:"submodules"`     // init/update submodules in new worktrees
+}
+
+// SandboxConfig describes the bubblewrap sandbox layout for --target sandbox.
+// It is only honored from the user-global config.
+type SandboxConfig struct {
+	NoNetwork bool     `mapstructure:"no_network"` // run sandboxed sessions without network access
+	ReadOnly  []string `mapstructure:"read_only"`  // extra toolchain paths bound read-only, e.g. ~/.cargo
+	Hide      []string `mapstructure:"hide"`       // host directories masked with an empty tmpfs
+}
+
+// DockerConfig controls the image used by --target docker.
+type DockerConfig struct {
+	Image        string            `mapstructure:"image"`        // session image; default devx-session-base:latest
+	Devcontainer bool              `mapstructure:"devcontainer"` // build sessions from the worktree's devcontainer.json when present
+	Build        DockerBuildConfig `mapstructure:"build"`        // build a per-project session image
+	Security     SecurityConfig    `mapstructure:"security"`     // container resource limits and hardening
+	// SecurityPresets are named SecurityConfigs selectable with security.preset,
+	// in addition to the built-in default, small, large and strict presets.
+	SecurityPresets map[string]SecurityConfig `mapstructure:"security_presets"`
+	// Caches are directories shared by all of a project's container sessions
+	// through named volumes, e.g. the Go module or npm cache.
+	Caches []CacheConfig `mapstructure:"caches"`
+	// Network is the session's egress: full (default), none, or allowlist
+	// through a filtering proxy using the Gatepost policy files. A project
+	// can make it stricter than the global setting, never looser.
+	Network string `mapstructure:"network"`
+	// EgressProxyBinary is a Linux devx binary for the allowlist proxy
+	// container; read from the global config only. On Linux hosts devx uses
+	// itself when unset.
+	EgressProxyBinary string `mapstructure:"egress_proxy_binary"`
+}
+
+// CacheConfig is a shared cache mount for container sessions.
+type CacheConfig struct {
+	Name     string `mapstructure:"name"`     // volume suffix, e.g. "gomod"
+	Path     string `mapstructure:"path"`     // container path; "~" is the container user's home
+	Gatepost string `mapstructure:"gatepost"` // gatepost access: read-write (default), read-only or none
+}
+
+// SecurityConfig overrides container limits. Unset fields keep the preset's
+// values.
+type SecurityConfig struct {
+	Preset       string   `mapstructure:"preset"`         // built-in or security_presets name
+	Memory       string   `mapstructure:"memory"`         // e.g. "8g"
+	CPUs         string   `mapstructure:"cpus"`           // e.g. "2.5"
+	Pids         int      `mapstructure:"pids"`           // max processes
+	ReadOnlyRoot *bool    `mapstructure:"read_only_root"` // mount the image read-only
+	Tmpfs        []string `mapstructure:"tmpfs"`          // writable tmpfs paths with a read-only root
+	CapAdd       []string `mapstructure:"cap_add"`        // capabilities to add back, from a safe list
+}
+
+// DockerBuildConfig describes a project's session image. Images are tagged
+// with a hash of the Dockerfile, build args and context, and rebuilt when
+// those change.
+type DockerBuildConfig struct {
+	Context    string            `mapstructure:"context"`    // build context relative to the project root; default "."
+	Dockerfile string            `mapstructure:"dockerfile"` // relative to the project root; default <context>/Dockerfile
+	Args       map[string]string `mapstructure:"args"`       // --build-arg values
+}
+
+// Enabled reports whether a project image build is configured.
+func (b DockerBuildConfig) Enabled() bool {
+	return b.Context != "" || b.Dockerfile != ""
+}
+
+// ComposeConfig selects the Docker Compose stack started for --target compose.
+type ComposeConfig struct {
+	Files         []string `mapstructure:"files"`          // compose files relative to the project root; auto-detected when empty
+	Network       string   `mapstructure:"network"`        // network the dev container joins; default <project>_default
+	RemoveVolumes bool     `mapstructure:"remove_volumes"` // remove named volumes when the session is removed
+}
+
+// RemoteConfig describes an SSH build host for --target remote. Like the
+// sandbox layout it is only honored from the user-global config.
+type RemoteConfig struct {
+	Host         string            `mapstructure:"host"`          // ssh destination, e.g. dev@buildbox
+	Port         int               `mapstructure:"port"`          // ssh port; 0 uses the ssh default
+	IdentityFile string            `mapstructure:"identity_file"` // optional private key
+	WorktreeRoot string            `mapstructure:"worktree_root"` // where session worktrees go; default ~/.devx/worktrees
+	Projects     map[string]string `mapstructure:"projects"`      // project alias -> checkout path on the remote
+}
+
+// ResolveSparse returns the sparse paths for a session: explicit paths win,
+// then the named preset, then the configured default. An unknown preset is an
+// error so typos do not silently produce a full checkout.
+func (w WorktreeConfig) ResolveSparse(preset string, explicit []string) ([]string, error) {
+	if len(explicit) > 0 {
+		return explicit, nil
+	}
+	if preset != "" {
+		paths, ok := w.SparsePresets[preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown sparse preset %q", preset)
+		}
+		return paths, nil
+	}
+	return w.Sparse, nil
 }
 
 func LoadConfig() (*Config, error) {
@@ -112,6 +266,7 @@ func SaveConfig(cfg *Config) error {
 	viper.Set("agent_responder.args", cfg.AgentResponder.Args)
 	viper.Set("agent_responder.timeout", cfg.AgentResponder.Timeout)
 	viper.Set("agent_responder.read_only", cfg.AgentResponder.ReadOnly)
+	viper.Set("agent_responder.parallel", cfg.AgentResponder.Parallel)
 	viper.Set("gatepost.root", cfg.Gatepost.Root)
 	viper.Set("gatepost.agent_image", cfg.Gatepost.AgentImage)
 	viper.Set("gatepost.logs_command", cfg.Gatepost.LogsCommand)
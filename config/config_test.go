@@ -133,3 +133,31 @@ func TestLoadConfigDefaults(t *testing.T) {
 		t.Errorf("expected default caddy_api 'http://localhost:2019', got %s", cfg.CaddyAPI)
 	}
 }
+
+func TestWorktreeConfigResolveSparse(t *testing.T) {
+	w := WorktreeConfig{
+		Sparse:        []string{"apps/web"},
+		SparsePresets: map[string][]string{"api": {"apps/api", "libs/shared"}},
+	}
+	if got, _ := w.ResolveSparse("", nil); len(got) != 1 || got[0] != "apps/web" {
+		t.Fatalf("default sparse = %v", got)
+	}
+	if got, _ := w.ResolveSparse("api", nil); len(got) != 2 || got[0] != "apps/api" {
+		t.Fatalf("preset sparse = %v", got)
+	}
+	if got, _ := w.ResolveSparse("api", []string{"tools"}); len(got) != 1 || got[0] != "tools" {
+		t.Fatalf("explicit sparse = %v", got)
+	}
+	if _, err := w.ResolveSparse("missing", nil); err == nil {
+		t.Fatal("expected unknown preset error")
+	}
+}
+
+func TestBootstrapTemplateDestination(t *testing.T) {
+	if got := (BootstrapTemplate{Src: "env.tmpl", Dst: ".env"}).Destination(); got != ".env" {
+		t.Errorf("Destination with dst = %q", got)
+	}
+	if got := (BootstrapTemplate{Src: "config/app.json", Dst: "  "}).Destination(); got != "config/app.json" {
+		t.Errorf("Destination without dst = %q, want the src", got)
+	}
+}
@@ -63,6 +63,13 @@ func GetDependencies() []Dependency {
 			Description: "Environment variable management (recommended)",
 			InstallHint: "Install with: brew install direnv",
 		},
+		{
+			Name:        "Podman",
+			Command:     "podman",
+			Required:    false,
+			Description: "Rootless container runtime for --target podman sessions",
+			InstallHint: "Install with: brew install podman",
+		},
 	}
 }
 
@@ -12,8 +12,8 @@ func TestGetDependencies(t *testing.T) {
 	deps := GetDependencies()
 
 	// Check we have the expected number of dependencies
-	if len(deps) != 5 {
-		t.Errorf("expected 5 dependencies, got %d", len(deps))
+	if len(deps) != 6 {
+		t.Errorf("expected 6 dependencies, got %d", len(deps))
 	}
 
 	// Check required dependencies
@@ -26,6 +26,7 @@ func TestGetDependencies(t *testing.T) {
 
 	optionalCommands := map[string]bool{
 		"direnv": false,
+		"podman": false,
 	}
 
 	for _, dep := range deps {
@@ -0,0 +1,93 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
+)
+
+// Bypass triggers recorded in the companion log.
+const (
+	TriggerManual  = "manual"
+	TriggerExpired = "expired"
+)
+
+// SetBypass enables the emergency egress bypass of a Gatepost session, or
+// restores enforcement. A positive d time-boxes the bypass; ExpireBypasses
+// restores enforcement once it runs out. The change is recorded in the
+// session's companion log.
+func SetBypass(ctx context.Context, name string, bypass bool, d time.Duration, reason, trigger string) (session.GatepostMeta, error) {
+	store, err := session.LoadSessions()
+	if err != nil {
+		return session.GatepostMeta{}, err
+	}
+	sess, ok := store.GetSession(name)
+	if !ok {
+		return session.GatepostMeta{}, fmt.Errorf("session %q not found", name)
+	}
+	if sess.Target.Type != "gatepost" || !sess.Target.Gatepost.Enabled {
+		return session.GatepostMeta{}, fmt.Errorf("session %q is not a gatepost session", name)
+	}
+	if err := target.SetGatepostBypass(ctx, sess.Target, bypass); err != nil {
+		return session.GatepostMeta{}, err
+	}
+
+	now := time.Now().UTC()
+	ev := target.GatepostBypassEvent{Time: now, Event: target.GatepostBypassStop, Trigger: trigger}
+	var until *time.Time
+	if bypass {
+		ev.Event = target.GatepostBypassStart
+		ev.Reason = reason
+		if d > 0 {
+			t := now.Add(d)
+			until = &t
+			ev.Until = until
+		}
+	}
+	changed := false
+	var gp session.GatepostMeta
+	if err := store.UpdateSession(name, func(s *session.Session) {
+		changed = bypass || s.Target.Gatepost.Bypass
+		s.Target.Gatepost.Bypass = bypass
+		s.Target.Gatepost.BypassUntil = until
+		s.Target.Gatepost.BypassReason = ""
+		if bypass {
+			s.Target.Gatepost.BypassReason = reason
+		}
+		gp = s.Target.Gatepost
+	}); err != nil {
+		return session.GatepostMeta{}, err
+	}
+	if changed {
+		if err := target.RecordGatepostBypassEvent(gp, ev); err != nil {
+			return gp, fmt.Errorf("record bypass event: %w", err)
+		}
+	}
+	return gp, nil
+}
+
+// ExpireBypasses restores enforcement for every Gatepost session whose
+// time-boxed bypass has run out. It returns the sessions it re-enforced.
+func ExpireBypasses(ctx context.Context, now time.Time) ([]string, error) {
+	store, err := session.LoadSessions()
+	if err != nil {
+		return nil, err
+	}
+	var expired []string
+	var errs []error
+	for _, name := range sortedSessionNames(store.Sessions) {
+		if !store.Sessions[name].Target.Gatepost.BypassExpired(now) {
+			continue
+		}
+		if _, err := SetBypass(ctx, name, false, 0, "", TriggerExpired); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		expired = append(expired, name)
+	}
+	return expired, errors.Join(errs...)
+}
@@ -0,0 +1,67 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
+)
+
+// SetPhase switches the policy phase of a Gatepost session. A positive d
+// makes the switch timed; ExpirePhases returns the session to run once it
+// runs out.
+func SetPhase(ctx context.Context, name, phase string, d time.Duration) (session.GatepostMeta, error) {
+	store, err := session.LoadSessions()
+	if err != nil {
+		return session.GatepostMeta{}, err
+	}
+	sess, ok := store.GetSession(name)
+	if !ok {
+		return session.GatepostMeta{}, fmt.Errorf("session %q not found", name)
+	}
+	if sess.Target.Type != "gatepost" || !sess.Target.Gatepost.Enabled {
+		return session.GatepostMeta{}, fmt.Errorf("session %q is not a gatepost session", name)
+	}
+	if err := target.SetGatepostPhase(ctx, sess.Target, phase, d); err != nil {
+		return session.GatepostMeta{}, err
+	}
+	var revertAt *time.Time
+	if d > 0 {
+		t := time.Now().Add(d).UTC()
+		revertAt = &t
+	}
+	var gp session.GatepostMeta
+	if err := store.UpdateSession(name, func(s *session.Session) {
+		s.Target.Gatepost.Phase = phase
+		s.Target.Gatepost.PhaseRevertAt = revertAt
+		gp = s.Target.Gatepost
+	}); err != nil {
+		return session.GatepostMeta{}, fmt.Errorf("failed to save phase: %w", err)
+	}
+	return gp, nil
+}
+
+// ExpirePhases returns every Gatepost session whose timed phase switch has
+// run out to the run phase. It returns the sessions it reverted.
+func ExpirePhases(ctx context.Context, now time.Time) ([]string, error) {
+	store, err := session.LoadSessions()
+	if err != nil {
+		return nil, err
+	}
+	var reverted []string
+	var errs []error
+	for _, name := range sortedSessionNames(store.Sessions) {
+		if !store.Sessions[name].Target.Gatepost.PhaseRevertDue(now) {
+			continue
+		}
+		if _, err := SetPhase(ctx, name, "run", 0); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		reverted = append(reverted, name)
+	}
+	return reverted, errors.Join(errs...)
+}
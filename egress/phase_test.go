@@ -0,0 +1,65 @@
+package egress
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jfox85/devx/session"
+)
+
+func TestExpirePhasesRevertsToRun(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".config", "devx"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var phases []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		phases = append(phases, body["phase"].(string))
+	}))
+	defer srv.Close()
+	configDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(configDir, "control.token"), []byte("tok\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	earlier, later := now.Add(-time.Minute), now.Add(time.Minute)
+	sessions := map[string]*session.Session{}
+	for name, revertAt := range map[string]*time.Time{"due": &earlier, "timed": &later, "held": nil} {
+		sessions[name] = &session.Session{Name: name, Target: session.TargetMeta{Type: "gatepost", Gatepost: session.GatepostMeta{
+			Enabled: true, ConfigDir: configDir, ControlURL: srv.URL, Phase: "install", PhaseRevertAt: revertAt,
+		}}}
+	}
+	if err := (&session.SessionStore{Sessions: sessions, NumberedSlots: map[int]string{}}).Overwrite(); err != nil {
+		t.Fatal(err)
+	}
+
+	reverted, err := ExpirePhases(context.Background(), now)
+	if err != nil || len(reverted) != 1 || reverted[0] != "due" {
+		t.Fatalf("ExpirePhases = %v, %v", reverted, err)
+	}
+	if len(phases) != 1 || phases[0] != "run" {
+		t.Fatalf("phase switches = %v", phases)
+	}
+	store, err := session.LoadSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gp := store.Sessions["due"].Target.Gatepost; gp.CurrentPhase() != "run" || gp.PhaseRevertAt != nil {
+		t.Errorf("reverted session = %+v", gp)
+	}
+	for _, name := range []string{"timed", "held"} {
+		if got := store.Sessions[name].Target.Gatepost.CurrentPhase(); got != "install" {
+			t.Errorf("%s phase = %q, want install", name, got)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package egress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfox85/devx/target"
+)
+
+// Proxy is the filtering forward proxy for docker sessions with
+// network: allowlist. It applies a Gatepost policy to the destination host of
+// every request and CONNECT tunnel, and writes each decision to Audit as a
+// JSON line in the Gatepost audit format.
+type Proxy struct {
+	Policy *target.GatepostPolicy
+	Phase  string
+	Audit  io.Writer
+
+	// Dial opens upstream connections; net.Dialer.DialContext when nil.
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	mu        sync.Mutex
+	transport *http.Transport
+}
+
+type proxyAuditLine struct {
+	Time     time.Time `json:"ts"`
+	Host     string    `json:"host"`
+	Method   string    `json:"method"`
+	URL      string    `json:"url,omitempty"`
+	Decision string    `json:"decision"`
+	Reason   string    `json:"reason"`
+	Phase    string    `json:"phase"`
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Hostname()
+	if r.Method == http.MethodConnect {
+		host, _, _ = strings.Cut(r.Host, ":")
+	}
+	if host == "" {
+		http.Error(w, "devx egress proxy: only proxy requests are served", http.StatusBadRequest)
+		return
+	}
+	allowed, reason := p.Policy.Decide(p.Phase, host)
+	p.audit(r, host, allowed, reason)
+	if !allowed {
+		http.Error(w, "devx egress proxy: "+host+" is blocked: "+reason, http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+	p.forward(w, r)
+}
+
+func (p *Proxy) audit(r *http.Request, host string, allowed bool, reason string) {
+	if p.Audit == nil {
+		return
+	}
+	line := proxyAuditLine{Time: time.Now().UTC(), Host: host, Method: r.Method, Decision: "deny", Reason: reason, Phase: p.Phase}
+	if allowed {
+		line.Decision = "allow"
+	}
+	if r.Method == http.MethodConnect {
+		line.URL = "https://" + r.Host
+	} else {
+		line.URL = r.URL.String()
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = p.Audit.Write(append(data, '\n'))
+}
+
+func (p *Proxy) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	if p.Dial != nil {
+		return p.Dial(ctx, network, addr)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, network, addr)
+}
+
+// tunnel splices a CONNECT request to its destination.
+func (p *Proxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	upstream, err := p.dial(ctx, "tcp", r.Host)
+	cancel()
+	if err != nil {
+		http.Error(w, "devx egress proxy: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "devx egress proxy: tunneling unsupported", http.StatusInternalServerError)
+		return
+	}
+	client, buf, err := hj.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		client.Close()
+		upstream.Close()
+		return
+	}
+	// Bytes the client sent after the CONNECT line are already buffered.
+	if n := buf.Reader.Buffered(); n > 0 {
+		pending, _ := buf.Reader.Peek(n)
+		if _, err := upstream.Write(pending); err != nil {
+			client.Close()
+			upstream.Close()
+			return
+		}
+	}
+	splice(client, upstream)
+}
+
+// forward relays a plain HTTP proxy request.
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	if p.transport == nil {
+		p.transport = &http.Transport{DialContext: p.dial, Proxy: nil, ResponseHeaderTimeout: time.Minute}
+	}
+	transport := p.transport
+	p.mu.Unlock()
+
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	for _, h := range []string{"Proxy-Connection", "Proxy-Authorization", "Connection", "Keep-Alive", "Te", "Trailer", "Upgrade"} {
+		out.Header.Del(h)
+	}
+	resp, err := transport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, "devx egress proxy: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for k, vs := range resp.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// Relay accepts connections on ln and splices each to addr until ctx ends.
+// The proxy uses it to publish session ports, since the session container
+// sits on an internal network Docker cannot publish from.
+func Relay(ctx context.Context, ln net.Listener, addr string) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			var d net.Dialer
+			upstream, err := d.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Printf("relay to %s: %v", addr, err)
+				conn.Close()
+				return
+			}
+			splice(conn, upstream)
+		}()
+	}
+}
+
+// splice copies in both directions and closes both connections when either
+// side is done.
+func splice(a, b net.Conn) {
+	var once sync.Once
+	closeBoth := func() {
+		a.Close()
+		b.Close()
+	}
+	go func() {
+		_, _ = io.Copy(a, b)
+		once.Do(closeBoth)
+	}()
+	_, _ = io.Copy(b, a)
+	once.Do(closeBoth)
+}
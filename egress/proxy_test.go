@@ -0,0 +1,179 @@
+package egress
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jfox85/devx/target"
+)
+
+// newTestProxy returns a proxy that allows only allowed.test in the run phase
+// and dials every allowed host at upstream.
+func newTestProxy(t *testing.T, upstream string, audit io.Writer) *httptest.Server {
+	t.Helper()
+	policy, err := target.ParseGatepostPolicy([]byte(`version: 1
+phases:
+  run:
+    unknown_action: deny
+    allow:
+      - host: allowed.test
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Proxy{
+		Policy: policy,
+		Phase:  "run",
+		Audit:  audit,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, upstream)
+		},
+	}
+	srv := httptest.NewServer(p)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProxyForwardsAllowedHTTP(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "hello from %s", r.Host)
+	}))
+	defer upstream.Close()
+	var audit bytes.Buffer
+	proxy := newTestProxy(t, upstream.Listener.Addr().String(), &audit)
+	proxyURL, _ := url.Parse(proxy.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, err := client.Get("http://allowed.test/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "hello from allowed.test" {
+		t.Fatalf("allowed = %d %q", resp.StatusCode, body)
+	}
+
+	resp, err = client.Get("http://blocked.test/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("blocked status = %d, want 403", resp.StatusCode)
+	}
+
+	var lines []proxyAuditLine
+	for _, raw := range strings.Split(strings.TrimSpace(audit.String()), "\n") {
+		var line proxyAuditLine
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			t.Fatalf("audit line %q: %v", raw, err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 || lines[0].Host != "allowed.test" || lines[0].Decision != "allow" ||
+		lines[1].Host != "blocked.test" || lines[1].Decision != "deny" || lines[1].Phase != "run" {
+		t.Errorf("audit = %+v", lines)
+	}
+}
+
+func TestProxyTunnelsAllowedConnect(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		conn, err := echo.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+	proxy := newTestProxy(t, echo.Addr().String(), nil)
+	addr := strings.TrimPrefix(proxy.URL, "http://")
+
+	connect := func(host string) (net.Conn, *bufio.Reader, *http.Response) {
+		t.Helper()
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(conn, "CONNECT %s:443 HTTP/1.1\r\nHost: %s:443\r\n\r\n", host, host)
+		br := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(br, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn, br, resp
+	}
+
+	conn, _, resp := connect("blocked.test")
+	conn.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("blocked CONNECT = %d, want 403", resp.StatusCode)
+	}
+
+	conn, br, resp := connect("allowed.test")
+	defer conn.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("allowed CONNECT = %d", resp.StatusCode)
+	}
+	if _, err := conn.Write([]byte("ping\n")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := br.ReadString('\n')
+	if err != nil || got != "ping\n" {
+		t.Fatalf("tunnel echo = %q, %v", got, err)
+	}
+}
+
+func TestRelay(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		conn, err := echo.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Relay(ctx, ln, echo.Addr().String()) }()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprint(conn, "hi\n")
+	got, err := bufio.NewReader(conn).ReadString('\n')
+	conn.Close()
+	if err != nil || got != "hi\n" {
+		t.Fatalf("relay echo = %q, %v", got, err)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Relay = %v after cancel", err)
+	}
+}
@@ -0,0 +1,45 @@
+package egress
+
+import "time"
+
+const (
+	StatusPending = "pending"
+	StatusAllowed = "allowed"
+	StatusDenied  = "denied"
+)
+
+// Approval scopes.
+const (
+	ScopeOnce    = "once"    // let the next request through
+	ScopeSession = "session" // add the host to the session's running policy
+	ScopeProject = "project" // also add it to the project's gatepost-policy.yaml
+)
+
+// Request is an unknown destination a Gatepost proxy blocked, waiting for a
+// human to allow or dismiss it.
+type Request struct {
+	ID        string    `json:"id"`
+	Session   string    `json:"session"`
+	Host      string    `json:"host"`
+	Phase     string    `json:"phase"`
+	Method    string    `json:"method,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Status    string    `json:"status"`
+	Scope     string    `json:"scope,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// cursor records how far a session's audit log has been read.
+type cursor struct {
+	AuditLog string `json:"audit_log"`
+	Offset   int64  `json:"offset"`
+}
+
+type state struct {
+	Cursors  map[string]cursor `json:"cursors"`
+	Requests []*Request        `json:"requests"`
+}
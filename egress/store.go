@@ -0,0 +1,343 @@
+package egress
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/jfox85/devx/config"
+	"github.com/jfox85/devx/internal/filelock"
+	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
+)
+
+// staleAfter bounds how old a denial can be and still become a request, so
+// the first sync of a long-running session does not replay its history.
+const staleAfter = time.Hour
+
+// Store holds pending egress approvals for all Gatepost sessions in one file.
+type Store struct {
+	path string
+}
+
+func NewStore() *Store {
+	return &Store{path: filepath.Join(filepath.Dir(config.GetSessionsPath()), "egress_approvals.json")}
+}
+
+func NewStoreAt(path string) *Store { return &Store{path: path} }
+
+// Sync reads new audit entries of every Gatepost session and records blocked
+// hosts as pending requests. It returns the requests it created.
+func (s *Store) Sync(sessions map[string]*session.Session) ([]*Request, error) {
+	var added []*Request
+	err := s.update(func(st *state) error {
+		now := time.Now().UTC()
+		for name := range st.Cursors {
+			if sess, ok := sessions[name]; !ok || !sess.Target.Gatepost.Enabled {
+				delete(st.Cursors, name)
+			}
+		}
+		st.Requests = slices.DeleteFunc(st.Requests, func(r *Request) bool {
+			sess, ok := sessions[r.Session]
+			return !ok || !sess.Target.Gatepost.Enabled
+		})
+
+		for _, name := range sortedSessionNames(sessions) {
+			gp := sessions[name].Target.Gatepost
+			if !gp.Enabled || gp.AuditLog == "" {
+				continue
+			}
+			cur := st.Cursors[name]
+			if cur.AuditLog != gp.AuditLog {
+				cur = cursor{AuditLog: gp.AuditLog}
+			}
+			entries, offset, err := target.ReadGatepostAuditFrom(gp.AuditLog, cur.Offset)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("read audit log of %s: %w", name, err)
+			}
+			cur.Offset = offset
+			st.Cursors[name] = cur
+			for _, e := range entries {
+				if e.Decision != "denied" || (!e.Time.IsZero() && now.Sub(e.Time) > staleAfter) {
+					continue
+				}
+				if r := st.record(name, e, now); r != nil {
+					added = append(added, r)
+				}
+			}
+		}
+		return nil
+	})
+	return added, err
+}
+
+// SyncSessions syncs against the current session store.
+func (s *Store) SyncSessions() ([]*Request, error) {
+	store, err := session.LoadSessions()
+	if err != nil {
+		return nil, err
+	}
+	return s.Sync(store.Sessions)
+}
+
+// record folds a denial into the session's latest request for the host. A
+// host that was dismissed or allowed beyond one request is not asked about
+// again.
+func (st *state) record(sessionName string, e target.GatepostAuditEntry, now time.Time) *Request {
+	seen := e.Time
+	if seen.IsZero() {
+		seen = now
+	}
+	if last := st.latest(sessionName, e.Host); last != nil {
+		switch {
+		case last.Status == StatusPending:
+			last.Count++
+			if seen.After(last.LastSeen) {
+				last.LastSeen = seen
+			}
+			last.UpdatedAt = now
+			return nil
+		case last.Status == StatusDenied, last.Scope != ScopeOnce:
+			return nil
+		}
+	}
+	phase := e.Phase
+	if !slices.Contains(target.GatepostPolicyPhases, phase) {
+		phase = "run"
+	}
+	r := &Request{
+		ID:        newID(),
+		Session:   sessionName,
+		Host:      e.Host,
+		Phase:     phase,
+		Method:    e.Method,
+		URL:       e.URL,
+		Reason:    e.Reason,
+		Count:     1,
+		FirstSeen: seen,
+		LastSeen:  seen,
+		Status:    StatusPending,
+		UpdatedAt: now,
+	}
+	st.Requests = append(st.Requests, r)
+	return r
+}
+
+func (st *state) latest(sessionName, host string) *Request {
+	for i := len(st.Requests) - 1; i >= 0; i-- {
+		if r := st.Requests[i]; r.Session == sessionName && r.Host == host {
+			return r
+		}
+	}
+	return nil
+}
+
+// Pending returns requests waiting for a decision, oldest first. An empty
+// session name returns requests of all sessions.
+func (s *Store) Pending(sessionName string) ([]*Request, error) {
+	st, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	var out []*Request
+	for _, r := range st.Requests {
+		if r.Status == StatusPending && (sessionName == "" || r.Session == sessionName) {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].FirstSeen.Before(out[j].FirstSeen) })
+	return out, nil
+}
+
+// Allow applies a pending request with the given scope through the
+// session's Gatepost control API and marks it allowed. The store lock is not
+// held while the host is allowed, so a slow control API does not block Sync
+// or other approvals.
+func (s *Store) Allow(ctx context.Context, id, scope string) (*Request, error) {
+	switch scope {
+	case ScopeOnce, ScopeSession, ScopeProject:
+	default:
+		return nil, fmt.Errorf("invalid scope %q (valid: once, session, project)", scope)
+	}
+	var req Request
+	if err := s.view(func(st *state) error {
+		r, err := st.pending(id)
+		if err != nil {
+			return err
+		}
+		req = *r
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sessions, err := session.LoadSessions()
+	if err != nil {
+		return nil, err
+	}
+	sess, ok := sessions.GetSession(req.Session)
+	if !ok {
+		return nil, fmt.Errorf("session %q not found", req.Session)
+	}
+	switch scope {
+	case ScopeOnce:
+		err = target.AllowGatepostHostOnce(ctx, sess.Target, req.Host)
+	case ScopeSession:
+		if err = target.AllowGatepostHost(ctx, sess.Target, req.Phase, req.Host); err == nil {
+			err = sessions.UpdateSession(req.Session, func(s *session.Session) {
+				allowed := s.Target.Gatepost.AllowedHosts
+				if allowed == nil {
+					allowed = map[string][]string{}
+				}
+				if !slices.Contains(allowed[req.Phase], req.Host) {
+					allowed[req.Phase] = append(allowed[req.Phase], req.Host)
+				}
+				s.Target.Gatepost.AllowedHosts = allowed
+			})
+		}
+	case ScopeProject:
+		if sess.ProjectPath == "" {
+			return nil, fmt.Errorf("session %q has no project to add the host to", req.Session)
+		}
+		path := filepath.Join(sess.ProjectPath, ".devx", target.GatepostPolicyFile)
+		if err = target.AddGatepostPolicyHost(path, req.Phase, req.Host); err == nil {
+			err = target.AllowGatepostHost(ctx, sess.Target, req.Phase, req.Host)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result *Request
+	err = s.update(func(st *state) error {
+		r, err := st.pending(id)
+		if err != nil {
+			return err
+		}
+		r.Status = StatusAllowed
+		r.Scope = scope
+		r.UpdatedAt = time.Now().UTC()
+		result = r
+		return nil
+	})
+	return result, err
+}
+
+// Deny dismisses a pending request. The proxy keeps blocking the host and
+// later denials of it in the session are not raised again.
+func (s *Store) Deny(id string) (*Request, error) {
+	var result *Request
+	err := s.update(func(st *state) error {
+		r, err := st.pending(id)
+		if err != nil {
+			return err
+		}
+		r.Status = StatusDenied
+		r.UpdatedAt = time.Now().UTC()
+		result = r
+		return nil
+	})
+	return result, err
+}
+
+func (st *state) pending(id string) (*Request, error) {
+	for _, r := range st.Requests {
+		if r.ID == id {
+			if r.Status != StatusPending {
+				return nil, fmt.Errorf("egress request %s is %s, not pending", id, r.Status)
+			}
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("egress request %s not found", id)
+}
+
+func (s *Store) load() (*state, error) {
+	st := &state{Cursors: map[string]cursor{}}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", s.path, err)
+	}
+	if st.Cursors == nil {
+		st.Cursors = map[string]cursor{}
+	}
+	return st, nil
+}
+
+// view runs fn on the stored state under the store lock without saving it.
+func (s *Store) view(fn func(*state) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	lock, err := filelock.Acquire(s.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+	st, err := s.load()
+	if err != nil {
+		return err
+	}
+	return fn(st)
+}
+
+// update runs fn on the stored state under the store lock and saves it when
+// fn succeeds.
+func (s *Store) update(fn func(*state) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	lock, err := filelock.Acquire(s.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+	st, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(st); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", s.path, os.Getpid())
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func sortedSessionNames(sessions map[string]*session.Session) []string {
+	names := make([]string, 0, len(sessions))
+	for name := range sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newID() string {
+	var b [6]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("egr_%d", time.Now().UnixNano())
+	}
+	return "egr_" + hex.EncodeToString(b[:])
+}
@@ -0,0 +1,225 @@
+package egress
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
+)
+
+func auditLine(ts time.Time, host, decision string) string {
+	return fmt.Sprintf(`{"ts":%q,"host":%q,"method":"GET","decision":%q,"phase":"install"}`+"\n", ts.Format(time.RFC3339), host, decision)
+}
+
+func appendAudit(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(strings.Join(lines, "")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func gatepostSessions(auditLog string) map[string]*session.Session {
+	return map[string]*session.Session{
+		"gp": {Name: "gp", Target: session.TargetMeta{Type: "gatepost", Gatepost: session.GatepostMeta{Enabled: true, AuditLog: auditLog}}},
+	}
+}
+
+func TestSyncRaisesEachBlockedHostOnce(t *testing.T) {
+	dir := t.TempDir()
+	auditLog := filepath.Join(dir, "audit.jsonl")
+	now := time.Now()
+	appendAudit(t, auditLog,
+		auditLine(now.Add(-2*time.Hour), "old.example.com", "deny"),
+		auditLine(now, "api.github.com", "allow"),
+		auditLine(now, "evil.example.com", "deny"),
+		auditLine(now, "evil.example.com", "deny"),
+		`{"ts":"partial`,
+	)
+	store := NewStoreAt(filepath.Join(dir, "egress.json"))
+	sessions := gatepostSessions(auditLog)
+
+	added, err := store.Sync(sessions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 1 || added[0].Host != "evil.example.com" || added[0].Phase != "install" {
+		t.Fatalf("added = %+v", added)
+	}
+	pending, err := store.Pending("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Count != 2 {
+		t.Fatalf("pending = %+v", pending)
+	}
+
+	// The partial line completes; a repeat denial only bumps the count.
+	appendAudit(t, auditLog, `"}`+"\n", auditLine(now, "evil.example.com", "deny"))
+	if added, err = store.Sync(sessions); err != nil || len(added) != 0 {
+		t.Fatalf("second sync added %+v, %v", added, err)
+	}
+	if pending, _ = store.Pending("gp"); len(pending) != 1 || pending[0].Count != 3 {
+		t.Fatalf("pending after repeat = %+v", pending)
+	}
+
+	// A dismissed host is not raised again.
+	if _, err := store.Deny(pending[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Deny(pending[0].ID); err == nil {
+		t.Fatal("denying a resolved request should fail")
+	}
+	appendAudit(t, auditLog, auditLine(now, "evil.example.com", "deny"))
+	if added, err = store.Sync(sessions); err != nil || len(added) != 0 {
+		t.Fatalf("dismissed host raised again: %+v, %v", added, err)
+	}
+
+	// Requests of removed sessions are dropped.
+	if _, err := store.Sync(map[string]*session.Session{}); err != nil {
+		t.Fatal(err)
+	}
+	st, err := store.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Requests) != 0 || len(st.Cursors) != 0 {
+		t.Fatalf("state not pruned: %+v", st)
+	}
+}
+
+func TestAllowAppliesScopes(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".config", "devx"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	var duringCall func()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.Path+" "+r.Header.Get("Authorization"))
+		if duringCall != nil {
+			duringCall()
+			duringCall = nil
+		}
+	}))
+	defer srv.Close()
+
+	configDir := t.TempDir()
+	policy, err := target.DefaultGatepostPolicy().Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "policy.gatepost.yaml"), policy, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "control.token"), []byte("tok\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	overlay := filepath.Join(project, ".devx", target.GatepostPolicyFile)
+	if err := os.MkdirAll(filepath.Dir(overlay), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(overlay, []byte("# team egress\nversion: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	auditLog := filepath.Join(t.TempDir(), "audit.jsonl")
+	sessions := gatepostSessions(auditLog)
+	sessions["gp"].ProjectPath = project
+	sessions["gp"].Target.Gatepost.ConfigDir = configDir
+	sessions["gp"].Target.Gatepost.ControlURL = srv.URL
+	if err := (&session.SessionStore{Sessions: sessions, NumberedSlots: map[int]string{}}).Overwrite(); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	appendAudit(t, auditLog,
+		auditLine(now, "a.example.com", "deny"),
+		auditLine(now, "b.example.com", "deny"),
+		auditLine(now, "c.example.com", "deny"),
+		auditLine(now, "d.example.com", "deny"),
+	)
+	store := NewStoreAt(filepath.Join(home, "egress.json"))
+	added, err := store.Sync(sessions)
+	if err != nil || len(added) != 4 {
+		t.Fatalf("sync = %+v, %v", added, err)
+	}
+	ctx := context.Background()
+
+	// The store stays usable while the control API is called.
+	duringCall = func() {
+		done := make(chan error, 1)
+		go func() {
+			_, err := store.Deny(added[3].ID)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Error(err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("store locked during the control API call")
+		}
+	}
+
+	if _, err := store.Allow(ctx, added[0].ID, ScopeOnce); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Allow(ctx, added[1].ID, ScopeSession); err != nil {
+		t.Fatal(err)
+	}
+	r, err := store.Allow(ctx, added[2].ID, ScopeProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != StatusAllowed || r.Scope != ScopeProject {
+		t.Fatalf("request = %+v", r)
+	}
+	if _, err := store.Allow(ctx, added[2].ID, ScopeOnce); err == nil {
+		t.Fatal("allowing a resolved request should fail")
+	}
+
+	want := []string{"/rules/allow-once Bearer tok", "/policy/reload Bearer tok", "/policy/reload Bearer tok"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Fatalf("control calls = %v, want %v", calls, want)
+	}
+	data, _ := os.ReadFile(filepath.Join(configDir, "policy.gatepost.yaml"))
+	if strings.Contains(string(data), "a.example.com") || !strings.Contains(string(data), "b.example.com") || !strings.Contains(string(data), "c.example.com") {
+		t.Fatalf("session policy:\n%s", data)
+	}
+	// Session-scoped hosts are recorded so that policy reloads keep them.
+	stored, err := session.LoadSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gp := stored.Sessions["gp"].Target.Gatepost; len(gp.AllowedHosts["install"]) != 1 || gp.AllowedHosts["install"][0] != "b.example.com" {
+		t.Fatalf("allowed hosts = %v", gp.AllowedHosts)
+	}
+	data, _ = os.ReadFile(overlay)
+	if !strings.Contains(string(data), "# team egress") || !strings.Contains(string(data), "c.example.com") {
+		t.Fatalf("project overlay:\n%s", data)
+	}
+	if o, err := target.LoadGatepostPolicyOverlay(overlay); err != nil || len(o.Phases["install"].Add) != 1 {
+		t.Fatalf("overlay = %+v, %v", o, err)
+	}
+
+	// Once-allowed hosts are raised again when blocked later.
+	appendAudit(t, auditLog, auditLine(now, "a.example.com", "deny"), auditLine(now, "b.example.com", "deny"))
+	if added, err = store.Sync(sessions); err != nil || len(added) != 1 || added[0].Host != "a.example.com" {
+		t.Fatalf("resync = %+v, %v", added, err)
+	}
+}
@@ -0,0 +1,51 @@
+// Package duration parses the durations devx commands take for --for and
+// --since: Go durations plus whole days such as "7d".
+package duration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse parses a positive Go duration or a whole number of days such as
+// "7d".
+func Parse(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q: use e.g. 12h or 7d", s)
+	}
+	return d, nil
+}
+
+// Flag is a command-line flag value that accepts what Parse does.
+type Flag struct {
+	d *time.Duration
+}
+
+// NewFlag returns a flag value that stores into d.
+func NewFlag(d *time.Duration) *Flag { return &Flag{d: d} }
+
+func (f *Flag) Set(s string) error {
+	d, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*f.d = d
+	return nil
+}
+
+func (f *Flag) String() string {
+	if f.d == nil || *f.d == 0 {
+		return ""
+	}
+	return f.d.String()
+}
+
+func (f *Flag) Type() string { return "duration" }
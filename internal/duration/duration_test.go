@@ -0,0 +1,30 @@
+package duration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for in, want := range map[string]time.Duration{"7d": 7 * 24 * time.Hour, "12h": 12 * time.Hour} {
+		if got, err := Parse(in); err != nil || got != want {
+			t.Errorf("Parse(%q) = %v, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "0d", "-1h", "soon"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) should fail", in)
+		}
+	}
+}
+
+func TestFlag(t *testing.T) {
+	var d time.Duration
+	f := NewFlag(&d)
+	if err := f.Set("2d"); err != nil || d != 48*time.Hour {
+		t.Fatalf("Set(2d) = %v, d = %v", err, d)
+	}
+	if err := f.Set("-5m"); err == nil {
+		t.Fatal("negative duration should be rejected")
+	}
+}
@@ -0,0 +1,99 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/jfox85/devx/config"
+)
+
+// BootstrapTemplateData is the data available to bootstrap templates. It is a
+// superset of TmuxpData so the same placeholders work in both.
+type BootstrapTemplateData struct {
+	Name           string
+	Path           string // worktree path as seen by the session (/workspace in containers)
+	HostPath       string // worktree path on the host
+	Ports          map[string]int
+	Routes         map[string]string
+	ExternalRoutes map[string]string
+	ProjectAlias   string
+	ProjectPath    string
+	Target         string
+	Env            map[string]string // variables exported by the session's .envrc
+}
+
+// RenderBootstrapTemplates renders each template from the project root into
+// the worktree. Missing keys are errors so a typo never produces a half-filled
+// config file. Existing destination files are overwritten, which is what lets
+// `devx session refresh` pick up changed ports or hostnames.
+func RenderBootstrapTemplates(projectRoot, worktreePath string, templates []config.BootstrapTemplate, data BootstrapTemplateData) error {
+	if len(templates) == 0 {
+		return nil
+	}
+
+	fmt.Printf("Rendering bootstrap templates...\n")
+
+	for _, t := range templates {
+		src, err := cleanBootstrapPath(t.Src)
+		if err != nil {
+			return err
+		}
+		dst, err := cleanBootstrapPath(t.Destination())
+		if err != nil {
+			return err
+		}
+
+		sourcePath := filepath.Join(projectRoot, src)
+		info, err := os.Stat(sourcePath)
+		if os.IsNotExist(err) {
+			fmt.Printf("  Warning: Bootstrap template not found: %s\n", t.Src)
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to stat bootstrap template %s: %w", t.Src, err)
+		}
+		content, err := os.ReadFile(sourcePath)
+		if err != nil {
+			return fmt.Errorf("failed to read bootstrap template %s: %w", t.Src, err)
+		}
+
+		tmpl, err := template.New(src).Funcs(templateFuncs()).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to parse bootstrap template %s: %w", t.Src, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("failed to render bootstrap template %s: %w", t.Src, err)
+		}
+
+		destPath := filepath.Join(worktreePath, dst)
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			return fmt.Errorf("failed to create destination directory: %w", err)
+		}
+		if err := os.WriteFile(destPath, buf.Bytes(), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", dst, err)
+		}
+
+		fmt.Printf("  Rendered: %s -> %s\n", src, dst)
+	}
+
+	return nil
+}
+
+func cleanBootstrapPath(relPath string) (string, error) {
+	relPath = strings.TrimSpace(relPath)
+	if relPath == "" {
+		return "", fmt.Errorf("bootstrap template path must not be empty")
+	}
+	if filepath.IsAbs(relPath) {
+		return "", fmt.Errorf("bootstrap template path must be relative: %s", relPath)
+	}
+	cleanPath := filepath.Clean(relPath)
+	if strings.HasPrefix(cleanPath, "..") {
+		return "", fmt.Errorf("bootstrap template path cannot escape project root: %s", relPath)
+	}
+	return cleanPath, nil
+}
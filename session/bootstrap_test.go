@@ -3,8 +3,10 @@ package session
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/jfox85/devx/config"
 	"github.com/spf13/viper"
 )
 
@@ -51,3 +53,69 @@ func TestCopyBootstrapFilesNilFallsBackToViper(t *testing.T) {
 		t.Fatalf("viper fallback did not copy file: %v", err)
 	}
 }
+
+func TestRenderBootstrapTemplates(t *testing.T) {
+	projectRoot := t.TempDir()
+	worktree := t.TempDir()
+
+	tmpl := `PORT={{.Ports.api}}
+API_URL=http://{{.Routes.api}}
+NAME={{.Name}} TARGET={{.Target}}
+ENV_PORT={{index .Env (toPortVar "api")}}
+`
+	if err := os.WriteFile(filepath.Join(projectRoot, ".env.tmpl"), []byte(tmpl), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	data := BootstrapTemplateData{
+		Name:   "feat",
+		Target: "host",
+		Ports:  map[string]int{"api": 4100},
+		Routes: map[string]string{"api": "feat-api.localhost"},
+		Env:    EnvrcVars(EnvrcData{Name: "feat", Ports: map[string]int{"api": 4100}}),
+	}
+	templates := []config.BootstrapTemplate{{Src: ".env.tmpl", Dst: ".env"}}
+	if err := RenderBootstrapTemplates(projectRoot, worktree, templates, data); err != nil {
+		t.Fatalf("RenderBootstrapTemplates: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(worktree, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "PORT=4100\nAPI_URL=http://feat-api.localhost\nNAME=feat TARGET=host\nENV_PORT=4100\n"
+	if string(got) != want {
+		t.Fatalf("rendered = %q, want %q", got, want)
+	}
+	if info, _ := os.Stat(filepath.Join(worktree, ".env")); info.Mode().Perm() != 0o600 {
+		t.Fatalf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// Re-rendering overwrites with the latest data.
+	data.Ports["api"] = 4200
+	data.Env = EnvrcVars(EnvrcData{Name: "feat", Ports: data.Ports})
+	if err := RenderBootstrapTemplates(projectRoot, worktree, templates, data); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(worktree, ".env")); !strings.HasPrefix(string(got), "PORT=4200\n") {
+		t.Fatalf("re-rendered = %q", got)
+	}
+}
+
+func TestRenderBootstrapTemplatesMissingKeyIsError(t *testing.T) {
+	projectRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectRoot, "cfg.tmpl"), []byte("{{.Ports.web}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := RenderBootstrapTemplates(projectRoot, t.TempDir(), []config.BootstrapTemplate{{Src: "cfg.tmpl", Dst: "cfg"}},
+		BootstrapTemplateData{Ports: map[string]int{"api": 1}})
+	if err == nil {
+		t.Fatal("expected missing key to fail rendering")
+	}
+}
+
+func TestRenderBootstrapTemplatesRejectsEscapingDestination(t *testing.T) {
+	err := RenderBootstrapTemplates(t.TempDir(), t.TempDir(), []config.BootstrapTemplate{{Src: "a.tmpl", Dst: "../a"}}, BootstrapTemplateData{})
+	if err == nil {
+		t.Fatal("expected escaping destination to be rejected")
+	}
+}
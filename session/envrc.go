@@ -16,68 +16,89 @@ type EnvrcData struct {
 	Name           string
 }
 
-// GenerateEnvrc creates an .envrc file in the worktree directory
-func GenerateEnvrc(worktreePath string, data EnvrcData) error {
-	// Generate the .envrc content dynamically
-	var lines []string
+// envrcVar is one variable exported by .envrc.
+type envrcVar struct {
+	name, value string
+}
 
-	// Add ports in sorted order for consistent output
-	var serviceNames []string
-	for name := range data.Ports {
-		serviceNames = append(serviceNames, name)
-	}
-	sort.Strings(serviceNames)
+// envrcSection is a group of variables in .envrc, written under an optional
+// comment.
+type envrcSection struct {
+	comment string
+	vars    []envrcVar
+}
 
-	for _, serviceName := range serviceNames {
-		port := data.Ports[serviceName]
-		// Convert service name to PORT environment variable (e.g., "ui" -> "UI_PORT")
-		portVar := strings.ToUpper(serviceName) + "_PORT"
-		lines = append(lines, fmt.Sprintf("export %s=%d", portVar, port))
+// envrcSections returns the variables .envrc exports, grouped and sorted the
+// way GenerateEnvrc writes them: ports, HTTP hostnames, external hostnames
+// and the session name.
+func envrcSections(data EnvrcData) []envrcSection {
+	sorted := func(m map[string]string, name func(string) string) []envrcVar {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		vars := make([]envrcVar, 0, len(keys))
+		for _, k := range keys {
+			vars = append(vars, envrcVar{name(k), m[k]})
+		}
+		return vars
 	}
-
-	// Add hostname environment variables if routes exist
-	if len(data.Routes) > 0 {
-		lines = append(lines, "")
-		lines = append(lines, "# HTTP hostnames")
-
-		// Sort route names for consistent output
-		var routeNames []string
-		for name := range data.Routes {
-			routeNames = append(routeNames, name)
+	hostVar := func(suffix string) func(string) string {
+		// e.g. "auth-service" -> "AUTH_SERVICE_HOST"
+		return func(service string) string {
+			return strings.ToUpper(strings.ReplaceAll(service, "-", "_")) + suffix
 		}
-		sort.Strings(routeNames)
+	}
+	ports := make(map[string]string, len(data.Ports))
+	for service, port := range data.Ports {
+		ports[service] = fmt.Sprintf("%d", port)
+	}
+	routes := make(map[string]string, len(data.Routes))
+	for service, hostname := range data.Routes {
+		routes[service] = "http://" + hostname
+	}
+	external := make(map[string]string, len(data.ExternalRoutes))
+	for service, hostname := range data.ExternalRoutes {
+		external[service] = "https://" + hostname
+	}
+	return []envrcSection{
+		{vars: sorted(ports, func(service string) string { return strings.ToUpper(service) + "_PORT" })},
+		{comment: "# HTTP hostnames", vars: sorted(routes, hostVar("_HOST"))},
+		{comment: "# External hostnames (Cloudflare tunnel)", vars: sorted(external, hostVar("_EXTERNAL_HOST"))},
+		{vars: []envrcVar{{"SESSION_NAME", data.Name}}},
+	}
+}
 
-		for _, serviceName := range routeNames {
-			hostname := data.Routes[serviceName]
-			// Convert service name to HOST environment variable
-			// e.g., "ui" -> "UI_HOST", "auth-service" -> "AUTH_SERVICE_HOST"
-			hostVar := strings.ToUpper(strings.ReplaceAll(serviceName, "-", "_")) + "_HOST"
-			lines = append(lines, fmt.Sprintf("export %s=http://%s", hostVar, hostname))
+// EnvrcVars returns the variables GenerateEnvrc exports for the given data.
+func EnvrcVars(data EnvrcData) map[string]string {
+	vars := make(map[string]string)
+	for _, section := range envrcSections(data) {
+		for _, v := range section.vars {
+			vars[v.name] = v.value
 		}
 	}
+	return vars
+}
 
-	// Add external hostname variables if CF tunnel is configured
-	if len(data.ExternalRoutes) > 0 {
-		lines = append(lines, "")
-		lines = append(lines, "# External hostnames (Cloudflare tunnel)")
-
-		var extRouteNames []string
-		for name := range data.ExternalRoutes {
-			extRouteNames = append(extRouteNames, name)
+// GenerateEnvrc creates an .envrc file in the worktree directory
+func GenerateEnvrc(worktreePath string, data EnvrcData) error {
+	var lines []string
+	for i, section := range envrcSections(data) {
+		if i > 0 {
+			if len(section.vars) == 0 {
+				continue
+			}
+			lines = append(lines, "")
+			if section.comment != "" {
+				lines = append(lines, section.comment)
+			}
 		}
-		sort.Strings(extRouteNames)
-
-		for _, serviceName := range extRouteNames {
-			hostname := data.ExternalRoutes[serviceName]
-			hostVar := strings.ToUpper(strings.ReplaceAll(serviceName, "-", "_")) + "_EXTERNAL_HOST"
-			lines = append(lines, fmt.Sprintf("export %s=https://%s", hostVar, hostname))
+		for _, v := range section.vars {
+			lines = append(lines, fmt.Sprintf("export %s=%s", v.name, v.value))
 		}
 	}
 
-	// Add session name
-	lines = append(lines, "")
-	lines = append(lines, fmt.Sprintf("export SESSION_NAME=%s", data.Name))
-
 	content := strings.Join(lines, "\n") + "\n"
 
 	envrcPath := filepath.Join(worktreePath, ".envrc")
@@ -0,0 +1,56 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateEnvrcMatchesEnvrcVars(t *testing.T) {
+	t.Setenv("PATH", "") // keep direnv out of the test
+	dir := t.TempDir()
+	data := EnvrcData{
+		Name:           "feat",
+		Ports:          map[string]int{"ui": 3000, "api": 3001},
+		Routes:         map[string]string{"auth-service": "feat-auth.localhost"},
+		ExternalRoutes: map[string]string{"ui": "feat.example.com"},
+	}
+	if err := GenerateEnvrc(dir, data); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, ".envrc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `export API_PORT=3001
+export UI_PORT=3000
+
+# HTTP hostnames
+export AUTH_SERVICE_HOST=http://feat-auth.localhost
+
+# External hostnames (Cloudflare tunnel)
+export UI_EXTERNAL_HOST=https://feat.example.com
+
+export SESSION_NAME=feat
+`
+	if string(content) != want {
+		t.Fatalf(".envrc =\n%s\nwant\n%s", content, want)
+	}
+
+	vars := EnvrcVars(data)
+	exported := 0
+	for _, line := range strings.Split(string(content), "\n") {
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		exported++
+		if vars[name] != value {
+			t.Errorf("EnvrcVars[%s] = %q, .envrc has %q", name, vars[name], value)
+		}
+	}
+	if exported != len(vars) {
+		t.Errorf(".envrc exports %d variables, EnvrcVars has %d", exported, len(vars))
+	}
+}
@@ -3,6 +3,7 @@ package session
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestGatepostMetaOmitsTokensFromJSON(t *testing.T) {
@@ -20,6 +21,29 @@ func TestGatepostMetaOmitsTokensFromJSON(t *testing.T) {
 	}
 }
 
+func TestGatepostMetaCurrentPhase(t *testing.T) {
+	now := time.Now()
+	later, earlier := now.Add(time.Minute), now.Add(-time.Minute)
+	for _, tc := range []struct {
+		meta GatepostMeta
+		want string
+		due  bool
+	}{
+		{GatepostMeta{}, "run", false},
+		{GatepostMeta{Phase: "install"}, "install", false},
+		{GatepostMeta{Phase: "install", PhaseRevertAt: &later}, "install", false},
+		{GatepostMeta{Phase: "install", PhaseRevertAt: &earlier}, "install", true},
+		{GatepostMeta{Phase: "run", PhaseRevertAt: &earlier}, "run", false},
+	} {
+		if got := tc.meta.CurrentPhase(); got != tc.want {
+			t.Errorf("CurrentPhase(%+v) = %q, want %q", tc.meta, got, tc.want)
+		}
+		if got := tc.meta.PhaseRevertDue(now); got != tc.due {
+			t.Errorf("PhaseRevertDue(%+v) = %v, want %v", tc.meta, got, tc.due)
+		}
+	}
+}
+
 func contains(s, sub string) bool {
 	for i := 0; i+len(sub) <= len(s); i++ {
 		if s[i:i+len(sub)] == sub {
@@ -28,3 +52,22 @@ func contains(s, sub string) bool {
 	}
 	return false
 }
+
+func TestGatepostMetaBypassExpired(t *testing.T) {
+	now := time.Now()
+	later, earlier := now.Add(time.Minute), now.Add(-time.Minute)
+	for _, tc := range []struct {
+		meta GatepostMeta
+		want bool
+	}{
+		{GatepostMeta{}, false},
+		{GatepostMeta{Bypass: true}, false},
+		{GatepostMeta{Bypass: true, BypassUntil: &later}, false},
+		{GatepostMeta{Bypass: true, BypassUntil: &earlier}, true},
+		{GatepostMeta{BypassUntil: &earlier}, false},
+	} {
+		if got := tc.meta.BypassExpired(now); got != tc.want {
+			t.Errorf("BypassExpired(%+v) = %v, want %v", tc.meta, got, tc.want)
+		}
+	}
+}
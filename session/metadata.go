@@ -38,46 +38,147 @@ type Session struct {
 	CreatedAt          time.Time         `json:"created_at"`
 	UpdatedAt          time.Time         `json:"updated_at"`
 	Target             TargetMeta        `json:"target,omitempty"`
+	VCS                string            `json:"vcs,omitempty"`  // "jj" for Jujutsu workspaces; empty means git
+	Tags               []string          `json:"tags,omitempty"` // free-form labels for addressing groups of sessions
 }
 
 // TargetMeta describes the execution environment for a session.
 // Zero value (empty Type) is treated as "host" everywhere.
 type TargetMeta struct {
-	Type          string       `json:"type,omitempty"`           // "host", "docker", future: "gatepost", "vm"
-	ContainerID   string       `json:"container_id,omitempty"`   // Docker container ID
-	ContainerName string       `json:"container_name,omitempty"` // Docker container name
-	NetworkName   string       `json:"network_name,omitempty"`   // Docker network name
-	Image         string       `json:"image,omitempty"`          // Image used
-	Gatepost      GatepostMeta `json:"gatepost,omitempty"`       // Gatepost runtime metadata when target is gatepost
+	Type          string           `json:"type,omitempty"`           // "host", "docker", "podman", "compose", "gatepost", "sandbox", "remote" or a plugin name
+	ContainerID   string           `json:"container_id,omitempty"`   // Docker container ID
+	ContainerName string           `json:"container_name,omitempty"` // Docker container name
+	NetworkName   string           `json:"network_name,omitempty"`   // Docker network name
+	Image         string           `json:"image,omitempty"`          // Image used
+	Gatepost      GatepostMeta     `json:"gatepost,omitempty"`       // Gatepost runtime metadata when target is gatepost
+	Sandbox       SandboxMeta      `json:"sandbox,omitempty"`        // Sandbox layout when target is sandbox
+	Remote        RemoteMeta       `json:"remote,omitempty"`         // SSH host details when target is remote
+	Compose       ComposeMeta      `json:"compose,omitempty"`        // Compose project when target is compose
+	Devcontainer  DevcontainerMeta `json:"devcontainer,omitempty"`   // devcontainer.json the docker container was built from
+	OOMKills      int              `json:"oom_kills,omitempty"`      // OOM kills already flagged for attention
+	Plugin        PluginMeta       `json:"plugin,omitempty"`         // external target plugin state when Type names a plugin
+	Network       NetworkMeta      `json:"network,omitempty"`        // egress fencing of a docker session
+	MemoryLimit   string           `json:"memory_limit,omitempty"`   // container memory limit resolved at create
+	CPULimit      string           `json:"cpu_limit,omitempty"`      // container CPU limit resolved at create
+}
+
+// NetworkMeta records how a docker session's egress is fenced. An empty Mode
+// is full network access.
+type NetworkMeta struct {
+	Mode               string `json:"mode,omitempty"`                 // full, none or allowlist
+	ProxyContainerName string `json:"proxy_container_name,omitempty"` // allowlist filtering proxy
+	EgressNetworkName  string `json:"egress_network_name,omitempty"`  // network the proxy reaches out on
+	Dir                string `json:"dir,omitempty"`                  // holds policy.yaml and audit.jsonl
+}
+
+// PluginMeta records a session run by an external devx-target-<name> plugin.
+// Data is opaque to DevX and handed back to the plugin on every operation.
+type PluginMeta struct {
+	Name    string          `json:"name,omitempty"`    // plugin name, e.g. "firecracker"
+	Session string          `json:"session,omitempty"` // session the runtime belongs to
+	Data    json.RawMessage `json:"data,omitempty"`    // plugin-defined metadata
+}
+
+// DevcontainerMeta records the devcontainer config a docker session was
+// created from.
+type DevcontainerMeta struct {
+	Config string `json:"config,omitempty"` // path of devcontainer.json
+	User   string `json:"user,omitempty"`   // remoteUser the container runs as
+}
+
+// ComposeMeta records the Docker Compose project that runs alongside a
+// compose session's dev container.
+type ComposeMeta struct {
+	Project       string   `json:"project,omitempty"`        // compose project name (-p)
+	Files         []string `json:"files,omitempty"`          // absolute compose file paths
+	RemoveVolumes bool     `json:"remove_volumes,omitempty"` // also remove named volumes on teardown
+}
+
+// RemoteMeta records where a remote session lives and how to reach it. All SSH
+// traffic for the session is multiplexed over ControlSocket, whose master also
+// forwards Ports back to local loopback.
+type RemoteMeta struct {
+	Name          string   `json:"name,omitempty"`           // key under remotes: in config
+	Host          string   `json:"host,omitempty"`           // ssh destination, e.g. dev@buildbox
+	Port          int      `json:"port,omitempty"`           // ssh port; 0 means the ssh default
+	IdentityFile  string   `json:"identity_file,omitempty"`  // optional ssh -i key
+	RemoteRepo    string   `json:"remote_repo,omitempty"`    // project checkout on the remote host
+	RemotePath    string   `json:"remote_path,omitempty"`    // session worktree on the remote host
+	ControlSocket string   `json:"control_socket,omitempty"` // local ssh control master socket
+	TmuxSession   string   `json:"tmux_session,omitempty"`   // tmux session name on the remote host
+	Ports         []int    `json:"ports,omitempty"`          // ports forwarded to 127.0.0.1
+	Synced        []string `json:"synced,omitempty"`         // devx-generated files copied into the remote worktree
+}
+
+// SandboxMeta records the filesystem and network layout of a sandbox session.
+// The sandbox has no long-running daemon, so every exec and tmux pane rebuilds
+// its bubblewrap invocation from this record.
+type SandboxMeta struct {
+	Runtime        string            `json:"runtime,omitempty"`          // "bwrap"
+	Home           string            `json:"home,omitempty"`             // host $HOME, masked by an empty tmpfs
+	Worktree       string            `json:"worktree,omitempty"`         // bound read-write
+	GitDir         string            `json:"git_dir,omitempty"`          // shared git dir of a linked worktree; only its object and ref stores are writable
+	WorktreeGitDir string            `json:"worktree_git_dir,omitempty"` // the worktree's own dir under GitDir/worktrees, bound read-write
+	NoNetwork      bool              `json:"no_network,omitempty"`       // run without network access
+	ReadOnly       []string          `json:"read_only,omitempty"`        // extra toolchain paths bound read-only
+	Hide           []string          `json:"hide,omitempty"`             // directories masked with an empty tmpfs
+	Env            map[string]string `json:"env,omitempty"`              // session env set inside the sandbox
 }
 
 // GatepostMeta describes the optional Gatepost capability attached to a session.
 // Runtime-specific details stay behind Runtime; DevX consumes this as a stable
 // contract for control, logs, and host-side bypass operations.
 type GatepostMeta struct {
-	Enabled             bool     `json:"enabled,omitempty"`
-	Runtime             string   `json:"runtime,omitempty"`
-	ProxyContainerName  string   `json:"proxy_container_name,omitempty"`
-	InternalNetworkName string   `json:"internal_network_name,omitempty"`
-	EgressNetworkName   string   `json:"egress_network_name,omitempty"`
-	PortsNetworkName    string   `json:"ports_network_name,omitempty"`
-	SessionDir          string   `json:"session_dir,omitempty"`
-	AuditDir            string   `json:"audit_dir,omitempty"`
-	ConfigDir           string   `json:"config_dir,omitempty"`
-	AgentHomeDir        string   `json:"agent_home_dir,omitempty"`
-	AuditLog            string   `json:"audit_log,omitempty"`
-	CompanionLog        string   `json:"companion_log,omitempty"`
-	ControlURL          string   `json:"control_url,omitempty"`
-	LogsURL             string   `json:"logs_url,omitempty"`
-	LogsTokenPath       string   `json:"logs_token_path,omitempty"`
-	LogsPID             int      `json:"logs_pid,omitempty"`
-	ProviderMode        string   `json:"provider_mode,omitempty"`
-	ProviderCommand     string   `json:"provider_command,omitempty"`
-	RegisteredProviders []string `json:"registered_providers,omitempty"`
-	ProviderWarnings    []string `json:"provider_warnings,omitempty"`
-	ControlToken        string   `json:"-"`
-	EventToken          string   `json:"-"`
-	Bypass              bool     `json:"bypass,omitempty"`
+	Enabled             bool                `json:"enabled,omitempty"`
+	Runtime             string              `json:"runtime,omitempty"`
+	ProxyContainerName  string              `json:"proxy_container_name,omitempty"`
+	InternalNetworkName string              `json:"internal_network_name,omitempty"`
+	EgressNetworkName   string              `json:"egress_network_name,omitempty"`
+	PortsNetworkName    string              `json:"ports_network_name,omitempty"`
+	SessionDir          string              `json:"session_dir,omitempty"`
+	AuditDir            string              `json:"audit_dir,omitempty"`
+	ConfigDir           string              `json:"config_dir,omitempty"`
+	AgentHomeDir        string              `json:"agent_home_dir,omitempty"`
+	AuditLog            string              `json:"audit_log,omitempty"`
+	CompanionLog        string              `json:"companion_log,omitempty"`
+	ControlURL          string              `json:"control_url,omitempty"`
+	LogsURL             string              `json:"logs_url,omitempty"`
+	LogsTokenPath       string              `json:"logs_token_path,omitempty"`
+	LogsPID             int                 `json:"logs_pid,omitempty"`
+	ProviderMode        string              `json:"provider_mode,omitempty"`
+	ProviderCommand     string              `json:"provider_command,omitempty"`
+	RegisteredProviders []string            `json:"registered_providers,omitempty"`
+	ProviderWarnings    []string            `json:"provider_warnings,omitempty"`
+	ControlToken        string              `json:"-"`
+	EventToken          string              `json:"-"`
+	Bypass              bool                `json:"bypass,omitempty"`
+	BypassUntil         *time.Time          `json:"bypass_until,omitempty"`       // when a time-boxed bypass is re-enforced
+	BypassReason        string              `json:"bypass_reason,omitempty"`      // why the bypass was enabled
+	Phase               string              `json:"phase,omitempty"`              // policy phase the proxy enforces; empty means run
+	PhaseRevertAt       *time.Time          `json:"phase_revert_at,omitempty"`    // when a timed phase switch returns to run
+	ConfiguredSecrets   []string            `json:"configured_secrets,omitempty"` // gatepost.secrets names selected for the session
+	AllowedHosts        map[string][]string `json:"allowed_hosts,omitempty"`      // hosts allowed for the session only, by phase
+}
+
+// CurrentPhase returns the policy phase the proxy was last switched to. A
+// timed switch keeps reporting its phase until devx has reverted it.
+func (g GatepostMeta) CurrentPhase() string {
+	if g.Phase == "" {
+		return "run"
+	}
+	return g.Phase
+}
+
+// PhaseRevertDue reports whether a timed phase switch has run out at now and
+// the proxy is due to be returned to the run phase.
+func (g GatepostMeta) PhaseRevertDue(now time.Time) bool {
+	return g.CurrentPhase() != "run" && g.PhaseRevertAt != nil && !now.Before(*g.PhaseRevertAt)
+}
+
+// BypassExpired reports whether a time-boxed bypass has run out at now and
+// enforcement is due to be restored.
+func (g GatepostMeta) BypassExpired(now time.Time) bool {
+	return g.Bypass && g.BypassUntil != nil && !now.Before(*g.BypassUntil)
 }
 
 // TargetType returns the effective target type, defaulting to "host".
@@ -477,8 +578,12 @@ func RemoveSession(name string, sess *Session) error {
 	// Kill tmux session if it exists
 	_ = killTmuxSession(name) // Don't fail on tmux errors
 
-	// Remove git worktree
-	_ = removeGitWorktree(sess.Path) // Don't fail on worktree errors
+	// Remove the workspace; jj workspaces must also be forgotten by the repo
+	if backend := VCSForSession(sess); backend.Name() != VCSGit {
+		_ = backend.RemoveWorkspace(sess.ProjectPath, name, sess.Path)
+	} else {
+		_ = removeGitWorktree(sess.Path) // Don't fail on worktree errors
+	}
 
 	return nil
 }
@@ -49,6 +49,8 @@ type SessionReview struct {
 	DirtyFiles     []string `json:"dirty_files,omitempty"`
 	UntrackedFiles []string `json:"untracked_files,omitempty"`
 
+	Submodules []SubmoduleStatus `json:"submodules,omitempty"`
+
 	UniqueCommitCount  int  `json:"unique_commit_count,omitempty"`
 	ChangedFileCount   int  `json:"changed_file_count,omitempty"`
 	DirtyFileCount     int  `json:"dirty_file_count,omitempty"`
@@ -97,7 +99,8 @@ func ReviewSession(sess *Session, opts ReviewOptions) (*SessionReview, error) {
 		return review, nil
 	}
 
-	base, err := ResolveReviewBase(sess.Path, opts.BaseBranch)
+	backend := VCSForSession(sess)
+	base, err := backend.ResolveBase(sess.Path, opts.BaseBranch)
 	if err != nil {
 		review.Error = err.Error()
 		review.Summary = "Unable to resolve a base branch for review."
@@ -105,10 +108,10 @@ func ReviewSession(sess *Session, opts ReviewOptions) (*SessionReview, error) {
 	}
 	review.BaseBranch = base
 
-	if head, err := gitOutput(sess.Path, "rev-parse", "HEAD"); err == nil {
-		review.HeadSHA = strings.TrimSpace(head)
+	if head, err := backend.Head(sess.Path); err == nil {
+		review.HeadSHA = head
 	}
-	status, err := gitOutput(sess.Path, "status", "--porcelain=v1")
+	status, err := backend.Status(sess.Path, false)
 	if err != nil {
 		review.Error = err.Error()
 		review.Summary = "Unable to read git status."
@@ -120,14 +123,14 @@ func ReviewSession(sess *Session, opts ReviewOptions) (*SessionReview, error) {
 	review.UntrackedFiles = untracked.files
 	review.Truncated = dirty.truncated || untracked.truncated
 
-	commits, err := gitOutput(sess.Path, "log", "--oneline", base+"..HEAD")
+	commits, err := backend.UniqueCommits(sess.Path, base)
 	if err != nil {
 		review.Error = err.Error()
 		review.Summary = "Unable to compare commits against base branch."
 		return review, nil
 	}
 	review.UniqueCommits = capLines(commits, maxFiles, &review.Truncated)
-	files, err := gitOutput(sess.Path, "diff", "--name-status", base+"...HEAD")
+	files, err := backend.ChangedFiles(sess.Path, base)
 	if err != nil {
 		review.Error = err.Error()
 		review.Summary = "Unable to compare changed files against base branch."
@@ -136,9 +139,24 @@ func ReviewSession(sess *Session, opts ReviewOptions) (*SessionReview, error) {
 	review.ChangedFiles = capLines(files, maxFiles, &review.Truncated)
 	review.setCounts()
 
+	var unpushedSubs []SubmoduleStatus
+	if backend.Name() == VCSGit {
+		subs, err := InspectSubmodules(sess.Path)
+		if err != nil {
+			review.Error = err.Error()
+			review.Summary = "Unable to inspect submodules."
+			return review, nil
+		}
+		review.Submodules = subs
+		unpushedSubs = SubmodulesWithUnpushedCommits(subs)
+	}
+
 	hasDirty := len(review.DirtyFiles) > 0 || len(review.UntrackedFiles) > 0
 	hasCommits := len(review.UniqueCommits) > 0
 	switch {
+	case len(unpushedSubs) > 0:
+		review.Classification = ReviewClassificationUniqueCommits
+		review.Summary = fmt.Sprintf("%d submodule(s) hold commits not pushed to any remote; review before cleanup.", len(unpushedSubs))
 	case hasCommits:
 		review.Classification = ReviewClassificationUniqueCommits
 		review.Summary = fmt.Sprintf("%d unique commit(s) outside %s; review before cleanup.", len(review.UniqueCommits), base)
@@ -177,11 +195,12 @@ func ReviewIsStale(sess *Session) bool {
 	if _, err := os.Stat(sess.Path); err != nil {
 		return true
 	}
-	head, err := gitOutput(sess.Path, "rev-parse", "HEAD")
-	if err != nil || strings.TrimSpace(head) != sess.Review.HeadSHA {
+	backend := VCSForSession(sess)
+	head, err := backend.Head(sess.Path)
+	if err != nil || head != sess.Review.HeadSHA {
 		return true
 	}
-	status, err := gitOutput(sess.Path, "status", "--porcelain=v1")
+	status, err := backend.Status(sess.Path, false)
 	if err != nil || hashString(status) != sess.Review.StatusHash {
 		return true
 	}
@@ -0,0 +1,322 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SeedMethodAuto     = "auto"
+	SeedMethodReflink  = "reflink"
+	SeedMethodHardlink = "hardlink"
+	SeedMethodCopy     = "copy"
+)
+
+// defaultSeedLockfiles maps well-known dependency directories to the lockfiles
+// that determine their contents. Lockfiles are looked up next to the directory,
+// so apps/web/node_modules is keyed by apps/web/package-lock.json.
+var defaultSeedLockfiles = map[string][]string{
+	"node_modules": {"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lockb", "bun.lock"},
+	".venv":        {"uv.lock", "poetry.lock", "Pipfile.lock", "requirements.txt"},
+	"venv":         {"uv.lock", "poetry.lock", "Pipfile.lock", "requirements.txt"},
+	"vendor":       {"go.sum", "Gemfile.lock", "composer.lock"},
+	".bundle":      {"Gemfile.lock"},
+	"target":       {"Cargo.lock"},
+}
+
+// SeedOptions configures SeedBootstrapDirs.
+type SeedOptions struct {
+	Dirs      []string            // directories relative to the checkout root
+	Lockfiles map[string][]string // dir (or base name) -> lockfile names; overrides defaults
+	Method    string              // auto, reflink, hardlink or copy
+	Sources   []string            // candidate source checkouts in preference order
+}
+
+// SeedResult reports how one bootstrap directory was seeded.
+type SeedResult struct {
+	Dir      string
+	Source   string
+	Method   string
+	Files    int
+	Duration time.Duration
+	Skipped  string // reason the directory was not seeded; empty on success
+}
+
+// SeedSources returns candidate checkouts to seed a new session from: the main
+// checkout first, then the most recently active other session of the project.
+func SeedSources(store *SessionStore, projectPath, excludeSession string) []string {
+	sources := []string{projectPath}
+	if store == nil {
+		return sources
+	}
+	var recent *Session
+	var recentAt time.Time
+	for name, sess := range store.Sessions {
+		if name == excludeSession || sess.ProjectPath != projectPath || sess.Path == "" {
+			continue
+		}
+		if _, err := os.Stat(sess.Path); err != nil {
+			continue
+		}
+		at := sessionLastActiveAt(sess)
+		if recent == nil || at.After(recentAt) {
+			recent, recentAt = sess, at
+		}
+	}
+	if recent != nil {
+		sources = append(sources, recent.Path)
+	}
+	return sources
+}
+
+// SeedBootstrapDirs pre-populates dependency directories (node_modules, .venv,
+// build caches, ...) in a new worktree from an existing checkout so the first
+// install in a session is incremental. A directory is only seeded from a
+// source whose lockfiles hash identically to the worktree's, and never over an
+// existing directory.
+func SeedBootstrapDirs(worktreePath string, opts SeedOptions) ([]SeedResult, error) {
+	if len(opts.Dirs) == 0 {
+		return nil, nil
+	}
+	method := opts.Method
+	if method == "" {
+		method = SeedMethodAuto
+	}
+	switch method {
+	case SeedMethodAuto, SeedMethodReflink, SeedMethodHardlink, SeedMethodCopy:
+	default:
+		return nil, fmt.Errorf("unknown bootstrap_dirs_method %q (valid: auto, reflink, hardlink, copy)", method)
+	}
+
+	fmt.Printf("Seeding bootstrap directories...\n")
+	start := time.Now()
+	var results []SeedResult
+	for _, rel := range opts.Dirs {
+		rel = strings.TrimSpace(rel)
+		if rel == "" {
+			continue
+		}
+		if filepath.IsAbs(rel) {
+			return results, fmt.Errorf("bootstrap dir path must be relative: %s", rel)
+		}
+		clean := filepath.Clean(rel)
+		if clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return results, fmt.Errorf("bootstrap dir path cannot escape project root: %s", rel)
+		}
+		result := seedOneDir(worktreePath, clean, opts, method)
+		results = append(results, result)
+		if result.Skipped != "" {
+			fmt.Printf("  Skipped %s: %s\n", clean, result.Skipped)
+		} else {
+			fmt.Printf("  Seeded %s from %s (%d files, %s, %s)\n", clean, result.Source, result.Files, result.Method, result.Duration.Round(time.Millisecond))
+		}
+	}
+	fmt.Printf("  Seeding took %s\n", time.Since(start).Round(time.Millisecond))
+	return results, nil
+}
+
+func seedOneDir(worktreePath, rel string, opts SeedOptions, method string) SeedResult {
+	result := SeedResult{Dir: rel}
+	dest := filepath.Join(worktreePath, rel)
+	if _, err := os.Lstat(dest); err == nil {
+		result.Skipped = "already exists in worktree"
+		return result
+	}
+	lockfiles := seedLockfiles(rel, opts.Lockfiles)
+	wantHash, _ := hashSeedLockfiles(worktreePath, rel, lockfiles)
+
+	reason := "not found in any source checkout"
+	for _, source := range opts.Sources {
+		if source == "" || filepath.Clean(source) == filepath.Clean(worktreePath) {
+			continue
+		}
+		src := filepath.Join(source, rel)
+		info, err := os.Lstat(src)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if len(lockfiles) > 0 {
+			if gotHash, _ := hashSeedLockfiles(source, rel, lockfiles); gotHash != wantHash {
+				reason = "lockfile differs from every source checkout"
+				continue
+			}
+		}
+		start := time.Now()
+		files, used, err := seedTree(src, dest, method)
+		if err != nil {
+			_ = os.RemoveAll(dest)
+			reason = fmt.Sprintf("copy from %s failed: %v", source, err)
+			continue
+		}
+		result.Source = source
+		result.Method = used
+		result.Files = files
+		result.Duration = time.Since(start)
+		return result
+	}
+	result.Skipped = reason
+	return result
+}
+
+func seedLockfiles(rel string, overrides map[string][]string) []string {
+	if names, ok := overrides[filepath.ToSlash(rel)]; ok {
+		return names
+	}
+	base := filepath.Base(rel)
+	if names, ok := overrides[base]; ok {
+		return names
+	}
+	return defaultSeedLockfiles[base]
+}
+
+// hashSeedLockfiles hashes the lockfiles that sit next to rel under root. The
+// boolean reports whether any lockfile was present.
+func hashSeedLockfiles(root, rel string, names []string) (string, bool) {
+	h := sha256.New()
+	found := false
+	parent := filepath.Join(root, filepath.Dir(rel))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(parent, name))
+		if err != nil {
+			continue
+		}
+		found = true
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), found
+}
+
+type seedFile struct {
+	src, dst string
+	mode     fs.FileMode
+}
+
+// seedTree replicates src into dst and returns the number of regular files and
+// the method actually used. Directories and symlinks are recreated serially;
+// regular files are cloned, linked or copied by a worker pool.
+func seedTree(src, dst, method string) (int, string, error) {
+	if method == SeedMethodAuto || method == SeedMethodReflink {
+		if n, ok := cloneTree(src, dst); ok {
+			return n, SeedMethodReflink, nil
+		}
+	}
+
+	var files []seedFile
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			files = append(files, seedFile{src: path, dst: target, mode: info.Mode().Perm()})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		cloned   int
+		wg       sync.WaitGroup
+	)
+	jobs := make(chan seedFile)
+	workers := runtime.NumCPU() * 2
+	if workers > 16 {
+		workers = 16
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				used, err := seedFileWithMethod(f, method)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				if used == SeedMethodReflink {
+					cloned++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, f := range files {
+		jobs <- f
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return 0, "", firstErr
+	}
+
+	used := method
+	if method == SeedMethodAuto {
+		used = SeedMethodCopy
+		if len(files) > 0 && cloned == len(files) {
+			used = SeedMethodReflink
+		}
+	}
+	return len(files), used, nil
+}
+
+func seedFileWithMethod(f seedFile, method string) (string, error) {
+	if method == SeedMethodHardlink {
+		return SeedMethodHardlink, os.Link(f.src, f.dst)
+	}
+	in, err := os.Open(f.src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(f.dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, f.mode)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+	if method != SeedMethodCopy {
+		if err := cloneFile(out, in); err == nil {
+			return SeedMethodReflink, nil
+		} else if method == SeedMethodReflink {
+			return "", fmt.Errorf("reflink not supported for %s: %w", f.src, err)
+		}
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		return "", err
+	}
+	return SeedMethodCopy, out.Close()
+}
+
+// errCloneUnsupported is returned by cloneFile on platforms without reflinks.
+var errCloneUnsupported = errors.New("copy-on-write clone not supported on this platform")
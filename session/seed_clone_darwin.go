@@ -0,0 +1,35 @@
+//go:build darwin
+
+package session
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneFile is only reached when clonefile(2) on the whole tree failed, which
+// means the volume does not support it; fall back to a regular copy.
+func cloneFile(dst, src *os.File) error {
+	return errCloneUnsupported
+}
+
+// cloneTree clones an entire directory on APFS with a single clonefile(2) call.
+func cloneTree(src, dst string) (int, bool) {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return 0, false
+	}
+	if err := unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW); err != nil {
+		return 0, false
+	}
+	files := 0
+	_ = filepath.WalkDir(dst, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			files++
+		}
+		return nil
+	})
+	return files, true
+}
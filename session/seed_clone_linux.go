@@ -0,0 +1,20 @@
+//go:build linux
+
+package session
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneFile shares dst's extents with src via FICLONE (btrfs, XFS, bcachefs).
+func cloneFile(dst, src *os.File) error {
+	return unix.IoctlFileClone(int(dst.Fd()), int(src.Fd()))
+}
+
+// cloneTree has no whole-directory equivalent on Linux; files are cloned one
+// at a time by seedTree instead.
+func cloneTree(src, dst string) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package session
+
+import "os"
+
+func cloneFile(dst, src *os.File) error {
+	return errCloneUnsupported
+}
+
+func cloneTree(src, dst string) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,141 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeSeedFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSeedBootstrapDirsCopiesWhenLockfilesMatch(t *testing.T) {
+	source := t.TempDir()
+	worktree := t.TempDir()
+	writeSeedFile(t, source, "package-lock.json", "lock-v1")
+	writeSeedFile(t, worktree, "package-lock.json", "lock-v1")
+	writeSeedFile(t, source, "node_modules/left-pad/index.js", "module.exports = 1")
+	writeSeedFile(t, source, "node_modules/left-pad/package.json", "{}")
+	if err := os.Symlink("../left-pad/index.js", filepath.Join(source, "node_modules", ".bin")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, method := range []string{SeedMethodAuto, SeedMethodCopy, SeedMethodHardlink} {
+		t.Run(method, func(t *testing.T) {
+			dest := t.TempDir()
+			writeSeedFile(t, dest, "package-lock.json", "lock-v1")
+			results, err := SeedBootstrapDirs(dest, SeedOptions{Dirs: []string{"node_modules"}, Method: method, Sources: []string{source}})
+			if err != nil {
+				t.Fatalf("SeedBootstrapDirs: %v", err)
+			}
+			if len(results) != 1 || results[0].Skipped != "" {
+				t.Fatalf("results = %+v", results)
+			}
+			if results[0].Files != 2 {
+				t.Fatalf("files = %d, want 2", results[0].Files)
+			}
+			data, err := os.ReadFile(filepath.Join(dest, "node_modules", "left-pad", "index.js"))
+			if err != nil || string(data) != "module.exports = 1" {
+				t.Fatalf("seeded file = %q, %v", data, err)
+			}
+			if link, err := os.Readlink(filepath.Join(dest, "node_modules", ".bin")); err != nil || link != "../left-pad/index.js" {
+				t.Fatalf("symlink = %q, %v", link, err)
+			}
+		})
+	}
+}
+
+func TestSeedBootstrapDirsSkipsOnLockfileMismatch(t *testing.T) {
+	source := t.TempDir()
+	worktree := t.TempDir()
+	writeSeedFile(t, source, "apps/web/yarn.lock", "old")
+	writeSeedFile(t, source, "apps/web/node_modules/a.js", "a")
+	writeSeedFile(t, worktree, "apps/web/yarn.lock", "new")
+
+	results, err := SeedBootstrapDirs(worktree, SeedOptions{Dirs: []string{"apps/web/node_modules"}, Sources: []string{source}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Skipped == "" {
+		t.Fatalf("expected mismatch to skip seeding: %+v", results[0])
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "apps", "web", "node_modules")); !os.IsNotExist(err) {
+		t.Fatalf("node_modules should not exist, stat err = %v", err)
+	}
+}
+
+func TestSeedBootstrapDirsFallsBackToNextSource(t *testing.T) {
+	main := t.TempDir()
+	recent := t.TempDir()
+	worktree := t.TempDir()
+	writeSeedFile(t, main, "uv.lock", "old")
+	writeSeedFile(t, main, ".venv/bin/python", "old")
+	writeSeedFile(t, recent, "uv.lock", "new")
+	writeSeedFile(t, recent, ".venv/bin/python", "new")
+	writeSeedFile(t, worktree, "uv.lock", "new")
+
+	results, err := SeedBootstrapDirs(worktree, SeedOptions{Dirs: []string{".venv"}, Sources: []string{main, recent}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Source != recent {
+		t.Fatalf("source = %q, want %q (%+v)", results[0].Source, recent, results[0])
+	}
+}
+
+func TestSeedBootstrapDirsNeverOverwrites(t *testing.T) {
+	source := t.TempDir()
+	worktree := t.TempDir()
+	writeSeedFile(t, source, "cache/x", "source")
+	writeSeedFile(t, worktree, "cache/x", "mine")
+
+	results, err := SeedBootstrapDirs(worktree, SeedOptions{Dirs: []string{"cache"}, Sources: []string{source}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Skipped == "" {
+		t.Fatal("expected existing directory to be skipped")
+	}
+	if data, _ := os.ReadFile(filepath.Join(worktree, "cache", "x")); string(data) != "mine" {
+		t.Fatalf("existing file overwritten: %q", data)
+	}
+}
+
+func TestSeedBootstrapDirsRejectsUnsafePaths(t *testing.T) {
+	for _, dir := range []string{"../escape", "..", "/abs"} {
+		if _, err := SeedBootstrapDirs(t.TempDir(), SeedOptions{Dirs: []string{dir}}); err == nil {
+			t.Errorf("expected %q to be rejected", dir)
+		}
+	}
+	if _, err := SeedBootstrapDirs(t.TempDir(), SeedOptions{Dirs: []string{"..cache"}}); err != nil {
+		t.Errorf("..cache should be allowed: %v", err)
+	}
+	if _, err := SeedBootstrapDirs(t.TempDir(), SeedOptions{Dirs: []string{"x"}, Method: "rsync"}); err == nil {
+		t.Error("expected unknown method to be rejected")
+	}
+}
+
+func TestSeedSourcesPrefersMainThenMostRecentSession(t *testing.T) {
+	project := t.TempDir()
+	older, newer := t.TempDir(), t.TempDir()
+	now := time.Now()
+	store := &SessionStore{Sessions: map[string]*Session{
+		"older": {Name: "older", Path: older, ProjectPath: project, CreatedAt: now.Add(-2 * time.Hour)},
+		"newer": {Name: "newer", Path: newer, ProjectPath: project, CreatedAt: now.Add(-3 * time.Hour), LastAttached: now},
+		"other": {Name: "other", Path: t.TempDir(), ProjectPath: t.TempDir(), LastAttached: now.Add(time.Hour)},
+		"self":  {Name: "self", Path: t.TempDir(), ProjectPath: project, LastAttached: now.Add(time.Hour)},
+	}}
+	got := SeedSources(store, project, "self")
+	if len(got) != 2 || got[0] != project || got[1] != newer {
+		t.Fatalf("SeedSources = %v", got)
+	}
+}
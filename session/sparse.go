@@ -0,0 +1,153 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ParseSparsePaths splits a comma-separated --sparse value into cone paths.
+func ParseSparsePaths(raw []string) []string {
+	var paths []string
+	for _, entry := range raw {
+		for _, p := range strings.Split(entry, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths
+}
+
+// ValidateSparsePaths rejects cone paths that are absolute, escape the
+// repository, or could be parsed by git as an option.
+func ValidateSparsePaths(paths []string) error {
+	for _, p := range paths {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("sparse path must not be empty")
+		}
+		if strings.HasPrefix(p, "-") {
+			return fmt.Errorf("sparse path must not start with '-': %s", p)
+		}
+		if filepath.IsAbs(p) || strings.HasPrefix(p, "/") {
+			return fmt.Errorf("sparse path must be relative: %s", p)
+		}
+		clean := path.Clean(filepath.ToSlash(p))
+		if clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("sparse path cannot escape the repository: %s", p)
+		}
+	}
+	return nil
+}
+
+func normalizeSparsePaths(paths []string) []string {
+	out := make([]string, 0, len(paths))
+	seen := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		clean := strings.TrimSuffix(path.Clean(filepath.ToSlash(strings.TrimSpace(p))), "/")
+		if clean == "" || clean == "." || seen[clean] {
+			continue
+		}
+		seen[clean] = true
+		out = append(out, clean)
+	}
+	return out
+}
+
+// checkoutSparse configures a cone-mode sparse checkout in a worktree that was
+// added with --no-checkout, then populates the index and working tree.
+func checkoutSparse(worktreePath string, paths []string, skipLFS bool) error {
+	if err := setSparsePaths(worktreePath, paths); err != nil {
+		return err
+	}
+	cmd := exec.Command("git", "checkout")
+	cmd.Dir = worktreePath
+	if skipLFS {
+		cmd.Env = append(os.Environ(), "GIT_LFS_SKIP_SMUDGE=1")
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to check out sparse worktree: %w\n%s", err, output)
+	}
+	return nil
+}
+
+func setSparsePaths(worktreePath string, paths []string) error {
+	args := append([]string{"sparse-checkout", "set", "--cone"}, normalizeSparsePaths(paths)...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = worktreePath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set sparse-checkout paths: %w\n%s", err, output)
+	}
+	return nil
+}
+
+// IsSparseWorktree reports whether sparse checkout is enabled for the worktree.
+func IsSparseWorktree(worktreePath string) bool {
+	cmd := exec.Command("git", "config", "--bool", "core.sparseCheckout")
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
+
+// SparseCheckoutList returns the cone directories of a sparse worktree. A
+// worktree without sparse checkout returns nil.
+func SparseCheckoutList(worktreePath string) ([]string, error) {
+	if !IsSparseWorktree(worktreePath) {
+		return nil, nil
+	}
+	cmd := exec.Command("git", "sparse-checkout", "list")
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sparse-checkout paths: %w", err)
+	}
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, nil
+}
+
+// SparseCheckoutAdd widens a worktree's cone. Adding paths to a full checkout
+// converts it into a sparse checkout containing only the given paths.
+func SparseCheckoutAdd(worktreePath string, paths []string) error {
+	if err := ValidateSparsePaths(paths); err != nil {
+		return err
+	}
+	current, err := SparseCheckoutList(worktreePath)
+	if err != nil {
+		return err
+	}
+	return setSparsePaths(worktreePath, append(current, paths...))
+}
+
+// SparseCheckoutRemove narrows a sparse worktree's cone. Git keeps files that
+// have local modifications, so no work is lost by removing a path.
+func SparseCheckoutRemove(worktreePath string, paths []string) error {
+	if err := ValidateSparsePaths(paths); err != nil {
+		return err
+	}
+	current, err := SparseCheckoutList(worktreePath)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return fmt.Errorf("worktree at %s is not a sparse checkout", worktreePath)
+	}
+	remove := make(map[string]bool, len(paths))
+	for _, p := range normalizeSparsePaths(paths) {
+		remove[p] = true
+	}
+	var keep []string
+	for _, p := range normalizeSparsePaths(current) {
+		if !remove[p] {
+			keep = append(keep, p)
+		}
+	}
+	return setSparsePaths(worktreePath, keep)
+}
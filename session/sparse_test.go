@@ -0,0 +1,109 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func makeMonorepo(t *testing.T) string {
+	t.Helper()
+	dir := makeStaleRepo(t)
+	for _, rel := range []string{"apps/web/index.js", "apps/api/main.go", "libs/ui/button.js"} {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(rel+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "monorepo")
+	runGit(t, dir, "push", "origin", "main")
+	return dir
+}
+
+func TestCreateWorktreeWithSparsePaths(t *testing.T) {
+	repo := makeMonorepo(t)
+	path, err := CreateWorktreeWithOptions(repo, "sparse-feature", false, WorktreeOptions{SparsePaths: []string{"apps/web"}, SkipLFS: true})
+	if err != nil {
+		t.Fatalf("CreateWorktreeWithOptions: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "apps", "web", "index.js")); err != nil {
+		t.Fatalf("cone file missing: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "README.md")); err != nil {
+		t.Fatalf("top-level file missing: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "apps", "api", "main.go")); !os.IsNotExist(err) {
+		t.Fatalf("file outside cone should not be checked out, stat err = %v", err)
+	}
+
+	got, err := SparseCheckoutList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []string{"apps/web"}) {
+		t.Fatalf("sparse list = %v", got)
+	}
+
+	if err := SparseCheckoutAdd(path, []string{"libs/ui/"}); err != nil {
+		t.Fatalf("SparseCheckoutAdd: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "libs", "ui", "button.js")); err != nil {
+		t.Fatalf("added path not checked out: %v", err)
+	}
+	if err := SparseCheckoutRemove(path, []string{"apps/web"}); err != nil {
+		t.Fatalf("SparseCheckoutRemove: %v", err)
+	}
+	got, _ = SparseCheckoutList(path)
+	if !reflect.DeepEqual(got, []string{"libs/ui"}) {
+		t.Fatalf("sparse list after remove = %v", got)
+	}
+}
+
+func TestSparseWorktreeStaleAndReviewAreClean(t *testing.T) {
+	repo := makeMonorepo(t)
+	path, err := CreateWorktreeWithOptions(repo, "sparse-stale", false, WorktreeOptions{SparsePaths: []string{"apps/web"}})
+	if err != nil {
+		t.Fatalf("CreateWorktreeWithOptions: %v", err)
+	}
+	runGit(t, path, "branch", "--set-upstream-to=origin/main")
+
+	old := time.Now().Add(-45 * 24 * time.Hour)
+	sess := &Session{Name: "sparse-stale", Path: path, CreatedAt: old, UpdatedAt: old}
+	status := AnalyzeStaleSession(sess, 14*24*time.Hour)
+	if status.Category != StaleCategoryClean {
+		t.Fatalf("category = %s, reasons = %v", status.Category, status.Reasons)
+	}
+
+	review, err := ReviewSession(sess, ReviewOptions{BaseBranch: "origin/main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review.Classification != ReviewClassificationClean {
+		t.Fatalf("classification = %s (%s)", review.Classification, review.Error)
+	}
+}
+
+func TestValidateSparsePathsRejectsUnsafePaths(t *testing.T) {
+	for _, p := range []string{"", "/abs", "../up", "-flag", "a/../../b"} {
+		if err := ValidateSparsePaths([]string{p}); err == nil {
+			t.Errorf("expected %q to be rejected", p)
+		}
+	}
+	if err := ValidateSparsePaths([]string{"apps/web", "libs/ui/"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParseSparsePathsSplitsCommas(t *testing.T) {
+	got := ParseSparsePaths([]string{"apps/web, libs/ui", "", "tools"})
+	want := []string{"apps/web", "libs/ui", "tools"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseSparsePaths = %v, want %v", got, want)
+	}
+}
@@ -95,27 +95,28 @@ func normalizeStaleAnalysisOptions(opts StaleAnalysisOptions) StaleAnalysisOptio
 // StaleStatus describes whether a session is old enough and safe enough for
 // automated cleanup. Only stale-clean sessions are removed in bulk.
 type StaleStatus struct {
-	SessionName               string         `json:"session_name"`
-	Category                  string         `json:"category"`
-	LastActiveAt              time.Time      `json:"last_active_at"`
-	LastReviewedAt            time.Time      `json:"last_reviewed_at,omitempty"`
-	Age                       time.Duration  `json:"-"`
-	AgeSeconds                int64          `json:"age_seconds"`
-	WorktreeExists            bool           `json:"worktree_exists"`
-	TmuxStatus                string         `json:"tmux_status"`
-	EditorStatus              string         `json:"editor_status"`
-	HasUncommitted            bool           `json:"has_uncommitted"`
-	HasUntracked              bool           `json:"has_untracked"`
-	HasIgnored                bool           `json:"has_ignored"`
-	HasUnpushedCommits        bool           `json:"has_unpushed_commits"`
-	UnpushedCommits           int            `json:"unpushed_commits"`
-	GitStatusUnknown          bool           `json:"git_status_unknown"`
-	UnpushedStatusUnknown     bool           `json:"unpushed_status_unknown"`
-	GitChecksIncomplete       bool           `json:"git_checks_incomplete"`
-	CleanupCandidate          bool           `json:"cleanup_candidate"`
-	PotentialCleanupCandidate bool           `json:"potential_cleanup_candidate"`
-	Reasons                   []string       `json:"reasons"`
-	CleanupReview             *SessionReview `json:"cleanup_review,omitempty"`
+	SessionName               string            `json:"session_name"`
+	Category                  string            `json:"category"`
+	LastActiveAt              time.Time         `json:"last_active_at"`
+	LastReviewedAt            time.Time         `json:"last_reviewed_at,omitempty"`
+	Age                       time.Duration     `json:"-"`
+	AgeSeconds                int64             `json:"age_seconds"`
+	WorktreeExists            bool              `json:"worktree_exists"`
+	TmuxStatus                string            `json:"tmux_status"`
+	EditorStatus              string            `json:"editor_status"`
+	HasUncommitted            bool              `json:"has_uncommitted"`
+	HasUntracked              bool              `json:"has_untracked"`
+	HasIgnored                bool              `json:"has_ignored"`
+	HasUnpushedCommits        bool              `json:"has_unpushed_commits"`
+	UnpushedCommits           int               `json:"unpushed_commits"`
+	GitStatusUnknown          bool              `json:"git_status_unknown"`
+	UnpushedStatusUnknown     bool              `json:"unpushed_status_unknown"`
+	GitChecksIncomplete       bool              `json:"git_checks_incomplete"`
+	CleanupCandidate          bool              `json:"cleanup_candidate"`
+	PotentialCleanupCandidate bool              `json:"potential_cleanup_candidate"`
+	Reasons                   []string          `json:"reasons"`
+	CleanupReview             *SessionReview    `json:"cleanup_review,omitempty"`
+	Submodules                []SubmoduleStatus `json:"submodules,omitempty"`
 }
 
 // StaleSummary groups analyzed sessions for CLI/API/UI consumers.
@@ -272,7 +273,7 @@ func probeStaleSession(sess *Session, opts StaleAnalysisOptions) StaleStatus {
 	}
 
 	if opts.includeGit {
-		inspectGitState(sess.Path, &status, opts)
+		inspectVCSState(VCSForSession(sess), sess.Path, &status, opts)
 	} else {
 		status.GitChecksIncomplete = true
 	}
@@ -436,20 +437,14 @@ func sessionLastActiveAt(sess *Session) time.Time {
 	return time.Now()
 }
 
-func inspectGitState(path string, status *StaleStatus, opts StaleAnalysisOptions) {
-	args := []string{"status", "--porcelain"}
-	if opts.includeIgnored {
-		args = append(args, "--ignored")
-	}
-	cmd := exec.Command("git", args...)
-	cmd.Dir = path
-	output, err := cmd.Output()
+func inspectVCSState(backend VCS, path string, status *StaleStatus, opts StaleAnalysisOptions) {
+	output, err := backend.Status(path, opts.includeIgnored)
 	if err != nil {
 		status.GitStatusUnknown = true
-		status.Reasons = append(status.Reasons, "git status unavailable")
+		status.Reasons = append(status.Reasons, backend.Name()+" status unavailable")
 		return
 	}
-	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
 		if line == "" {
 			continue
 		}
@@ -474,7 +469,7 @@ func inspectGitState(path string, status *StaleStatus, opts StaleAnalysisOptions
 	if !opts.includeUnpushed {
 		return
 	}
-	count, ok := unpushedCommitCount(path)
+	count, ok := backend.UnpushedCommits(path)
 	if !ok {
 		status.UnpushedStatusUnknown = true
 		status.Reasons = append(status.Reasons, "unpushed commit status unavailable")
@@ -485,6 +480,35 @@ func inspectGitState(path string, status *StaleStatus, opts StaleAnalysisOptions
 		status.UnpushedCommits = count
 		status.Reasons = append(status.Reasons, fmt.Sprintf("%d unpushed commit(s)", count))
 	}
+	if backend.Name() == VCSGit {
+		inspectSubmoduleState(path, status)
+	}
+}
+
+// inspectSubmoduleState folds submodule state into the superproject status so
+// commits that only exist inside a submodule block cleanup like any other
+// unpushed work.
+func inspectSubmoduleState(path string, status *StaleStatus) {
+	subs, err := InspectSubmodules(path)
+	if err != nil {
+		status.UnpushedStatusUnknown = true
+		status.Reasons = append(status.Reasons, "submodule status unavailable")
+		return
+	}
+	status.Submodules = subs
+	for _, sub := range subs {
+		switch {
+		case sub.StatusUnknown:
+			status.UnpushedStatusUnknown = true
+		case sub.UnpushedCommits > 0:
+			status.HasUnpushedCommits = true
+		case sub.Dirty:
+			status.HasUncommitted = true
+		default:
+			continue
+		}
+		status.Reasons = append(status.Reasons, describeSubmodule(sub))
+	}
 }
 
 func unpushedCommitCount(path string) (count int, ok bool) {
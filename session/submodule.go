@@ -0,0 +1,153 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SubmoduleStatus is the local state of one initialized submodule. Nested
+// submodules are reported with paths relative to the top-level worktree.
+type SubmoduleStatus struct {
+	Path            string `json:"path"`
+	Dirty           bool   `json:"dirty,omitempty"`
+	UnpushedCommits int    `json:"unpushed_commits,omitempty"`
+	StatusUnknown   bool   `json:"status_unknown,omitempty"`
+}
+
+// HasSubmodules reports whether the checkout declares any submodules.
+func HasSubmodules(worktreePath string) bool {
+	_, err := os.Stat(filepath.Join(worktreePath, ".gitmodules"))
+	return err == nil
+}
+
+// initSubmodules initializes and checks out submodules in a new worktree.
+// Submodules already initialized in the main checkout are used as --reference
+// repositories so their objects are shared instead of cloned again.
+func initSubmodules(repoPath, worktreePath string, skipLFS bool) error {
+	if !HasSubmodules(worktreePath) {
+		return nil
+	}
+	env := os.Environ()
+	if skipLFS {
+		env = append(env, "GIT_LFS_SKIP_SMUDGE=1")
+	}
+	run := func(args ...string) error {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = worktreePath
+		cmd.Env = env
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %w\n%s", strings.Join(args, " "), err, output)
+		}
+		return nil
+	}
+
+	paths, err := declaredSubmodulePaths(worktreePath)
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		reference := filepath.Join(repoPath, p)
+		if _, err := os.Stat(filepath.Join(reference, ".git")); err != nil {
+			continue
+		}
+		if err := run("submodule", "update", "--init", "--reference", reference, "--", p); err != nil {
+			return err
+		}
+	}
+	// Pick up submodules the main checkout never initialized, plus nested ones.
+	return run("submodule", "update", "--init", "--recursive")
+}
+
+// declaredSubmodulePaths lists the top-level submodule paths from .gitmodules.
+func declaredSubmodulePaths(worktreePath string) ([]string, error) {
+	cmd := exec.Command("git", "config", "--file", ".gitmodules", "--get-regexp", `^submodule\..*\.path$`)
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil, nil // no matching keys
+		}
+		return nil, fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if _, p, ok := strings.Cut(line, " "); ok && p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// InspectSubmodules reports dirty and unpushed state for every initialized
+// submodule, recursively. A commit counts as unpushed when no remote-tracking
+// ref in the submodule contains it; removing the worktree would lose it.
+func InspectSubmodules(worktreePath string) ([]SubmoduleStatus, error) {
+	if !HasSubmodules(worktreePath) {
+		return nil, nil
+	}
+	cmd := exec.Command("git", "submodule", "foreach", "--quiet", "--recursive", `echo "$displaypath"`)
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list submodules: %w", err)
+	}
+	var statuses []SubmoduleStatus
+	for _, p := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if p == "" {
+			continue
+		}
+		statuses = append(statuses, inspectSubmodule(worktreePath, p))
+	}
+	return statuses, nil
+}
+
+func inspectSubmodule(worktreePath, rel string) SubmoduleStatus {
+	status := SubmoduleStatus{Path: rel}
+	dir := filepath.Join(worktreePath, rel)
+
+	porcelain, err := gitOutput(dir, "status", "--porcelain", "--ignore-submodules=all")
+	if err != nil {
+		status.StatusUnknown = true
+		return status
+	}
+	status.Dirty = strings.TrimSpace(porcelain) != ""
+
+	count, err := gitOutput(dir, "rev-list", "--count", "HEAD", "--not", "--remotes")
+	if err != nil {
+		status.StatusUnknown = true
+		return status
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(count)); err == nil {
+		status.UnpushedCommits = n
+	} else {
+		status.StatusUnknown = true
+	}
+	return status
+}
+
+// SubmodulesWithUnpushedCommits filters statuses down to submodules holding
+// commits that exist nowhere else, including ones whose state is unknown.
+func SubmodulesWithUnpushedCommits(statuses []SubmoduleStatus) []SubmoduleStatus {
+	var out []SubmoduleStatus
+	for _, s := range statuses {
+		if s.UnpushedCommits > 0 || s.StatusUnknown {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func describeSubmodule(s SubmoduleStatus) string {
+	switch {
+	case s.StatusUnknown:
+		return fmt.Sprintf("submodule %s: status unavailable", s.Path)
+	case s.UnpushedCommits > 0:
+		return fmt.Sprintf("submodule %s: %d unpushed commit(s)", s.Path, s.UnpushedCommits)
+	default:
+		return fmt.Sprintf("submodule %s: local changes", s.Path)
+	}
+}
@@ -0,0 +1,100 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// makeSuperproject returns a repo with libs/lib registered as a submodule.
+// File-protocol submodule clones are disabled by default in modern git, so
+// the test allows them through an isolated global config.
+func makeSuperproject(t *testing.T) string {
+	t.Helper()
+	globalConfig := filepath.Join(t.TempDir(), "gitconfig")
+	if err := os.WriteFile(globalConfig, []byte("[protocol \"file\"]\n\tallow = always\n[user]\n\tname = test\n\temail = test@example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", globalConfig)
+
+	lib := makeStaleRepo(t)
+	libRemote, err := gitOutput(lib, "remote", "get-url", "origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	super := makeStaleRepo(t)
+	runGit(t, super, "submodule", "add", "-b", "main", strings.TrimSpace(libRemote), "libs/lib")
+	runGit(t, super, "commit", "-m", "add lib submodule")
+	runGit(t, super, "push", "origin", "main")
+	return super
+}
+
+func TestCreateWorktreeInitializesSubmodules(t *testing.T) {
+	super := makeSuperproject(t)
+	path, err := CreateWorktreeWithOptions(super, "with-subs", false, WorktreeOptions{Submodules: true})
+	if err != nil {
+		t.Fatalf("CreateWorktreeWithOptions: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "libs", "lib", "README.md")); err != nil {
+		t.Fatalf("submodule not checked out: %v", err)
+	}
+	alternates, err := gitOutput(filepath.Join(path, "libs", "lib"), "rev-parse", "--git-path", "objects/info/alternates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alternatesPath := strings.TrimSpace(alternates)
+	if !filepath.IsAbs(alternatesPath) {
+		alternatesPath = filepath.Join(path, "libs", "lib", alternatesPath)
+	}
+	if _, err := os.Stat(alternatesPath); err != nil {
+		t.Fatalf("submodule should borrow objects from the main checkout: %v", err)
+	}
+}
+
+func TestStaleAndReviewReportUnpushedSubmoduleCommits(t *testing.T) {
+	super := makeSuperproject(t)
+	path, err := CreateWorktreeWithOptions(super, "sub-work", false, WorktreeOptions{Submodules: true})
+	if err != nil {
+		t.Fatalf("CreateWorktreeWithOptions: %v", err)
+	}
+	old := time.Now().Add(-45 * 24 * time.Hour)
+	sess := &Session{Name: "sub-work", Path: path, CreatedAt: old, UpdatedAt: old}
+
+	if status := AnalyzeStaleSession(sess, 14*24*time.Hour); status.Category != StaleCategoryClean {
+		t.Fatalf("fresh worktree category = %s, reasons = %v", status.Category, status.Reasons)
+	}
+
+	// Commit inside the submodule, record the new gitlink and push only the
+	// superproject: the superproject looks clean but the submodule commit
+	// exists nowhere else.
+	sub := filepath.Join(path, "libs", "lib")
+	if err := os.WriteFile(filepath.Join(sub, "change.txt"), []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, sub, "add", ".")
+	runGit(t, sub, "commit", "-m", "local only")
+	runGit(t, path, "add", "libs/lib")
+	runGit(t, path, "commit", "-m", "bump lib")
+	runGit(t, path, "push", "-u", "origin", "sub-work")
+
+	status := AnalyzeStaleSession(sess, 14*24*time.Hour)
+	if status.Category != StaleCategoryNeedsReview || !status.HasUnpushedCommits {
+		t.Fatalf("category = %s, unpushed = %v, reasons = %v", status.Category, status.HasUnpushedCommits, status.Reasons)
+	}
+	if len(status.Submodules) != 1 || status.Submodules[0].Path != "libs/lib" || status.Submodules[0].UnpushedCommits != 1 {
+		t.Fatalf("submodules = %+v", status.Submodules)
+	}
+
+	review, err := ReviewSession(sess, ReviewOptions{BaseBranch: "origin/sub-work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review.Classification != ReviewClassificationUniqueCommits {
+		t.Fatalf("classification = %s (%s)", review.Classification, review.Summary)
+	}
+	if got := SubmodulesWithUnpushedCommits(review.Submodules); len(got) != 1 {
+		t.Fatalf("review submodules = %+v", review.Submodules)
+	}
+}
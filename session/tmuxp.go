@@ -72,35 +72,7 @@ func GenerateTmuxpConfig(worktreePath string, data TmuxpData, projectPath string
 		return fmt.Errorf("failed to load tmuxp template: %w", err)
 	}
 
-	// Create template with helper functions
-	funcMap := template.FuncMap{
-		"toPortVar": func(serviceName string) string {
-			// Convert service name to PORT variable name
-			// e.g., "ui" -> "UI_PORT", "auth-service" -> "AUTH_SERVICE_PORT"
-			upper := strings.ToUpper(serviceName)
-			return strings.ReplaceAll(upper, "-", "_") + "_PORT"
-		},
-		"toHostVar": func(serviceName string) string {
-			// Convert service name to HOST variable name
-			// e.g., "ui" -> "UI_HOST", "auth-service" -> "AUTH_SERVICE_HOST"
-			upper := strings.ToUpper(serviceName)
-			return strings.ReplaceAll(upper, "-", "_") + "_HOST"
-		},
-		"toExternalHostVar": func(serviceName string) string {
-			// Convert service name to EXTERNAL_HOST variable name
-			// e.g., "frontend" -> "FRONTEND_EXTERNAL_HOST"
-			upper := strings.ToUpper(serviceName)
-			return strings.ReplaceAll(upper, "-", "_") + "_EXTERNAL_HOST"
-		},
-		"defaultTmuxMouse": func() string {
-			return DefaultTmuxMouse
-		},
-		"defaultTmuxHistoryLimit": func() int {
-			return DefaultTmuxHistoryLimit
-		},
-	}
-
-	tmpl, err := template.New("tmuxp").Funcs(funcMap).Parse(templateContent)
+	tmpl, err := template.New("tmuxp").Funcs(templateFuncs()).Parse(templateContent)
 	if err != nil {
 		return fmt.Errorf("failed to parse tmuxp template: %w", err)
 	}
@@ -292,3 +264,34 @@ func AttachReadyTmuxSession(sessionName string) error {
 	}
 	return wait()
 }
+
+// templateFuncs returns the helper functions available to session templates
+// (.tmuxp.yaml and bootstrap templates).
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"toPortVar": func(serviceName string) string {
+			// Convert service name to PORT variable name
+			// e.g., "ui" -> "UI_PORT", "auth-service" -> "AUTH_SERVICE_PORT"
+			upper := strings.ToUpper(serviceName)
+			return strings.ReplaceAll(upper, "-", "_") + "_PORT"
+		},
+		"toHostVar": func(serviceName string) string {
+			// Convert service name to HOST variable name
+			// e.g., "ui" -> "UI_HOST", "auth-service" -> "AUTH_SERVICE_HOST"
+			upper := strings.ToUpper(serviceName)
+			return strings.ReplaceAll(upper, "-", "_") + "_HOST"
+		},
+		"toExternalHostVar": func(serviceName string) string {
+			// Convert service name to EXTERNAL_HOST variable name
+			// e.g., "frontend" -> "FRONTEND_EXTERNAL_HOST"
+			upper := strings.ToUpper(serviceName)
+			return strings.ReplaceAll(upper, "-", "_") + "_EXTERNAL_HOST"
+		},
+		"defaultTmuxMouse": func() string {
+			return DefaultTmuxMouse
+		},
+		"defaultTmuxHistoryLimit": func() int {
+			return DefaultTmuxHistoryLimit
+		},
+	}
+}
@@ -26,14 +26,19 @@ import (
 // The project's .tmuxp.yaml is never modified — we read it as a declarative
 // spec and build the tmux session ourselves.
 func EnsureTmuxSessionInContainer(sessionName, containerName string, sess *Session) error {
+	cli := "docker"
+	if sess != nil {
+		cli = ContainerCLI(sess.Target)
+	}
+
 	// Pre-flight: verify the container is running.
-	if err := verifyContainerRunning(containerName); err != nil {
+	if err := verifyContainerRunning(cli, containerName); err != nil {
 		return fmt.Errorf("container %q not running: %w", containerName, err)
 	}
 
 	if exec.Command("tmux", "has-session", "-t", "="+sessionName).Run() == nil {
 		// Session exists — verify panes are running inside the container.
-		if err := verifyPanesInContainer(sessionName, containerName); err == nil {
+		if err := verifyPanesInContainer(sessionName, cli, containerName); err == nil {
 			return nil // all good
 		}
 		// Panes are not in the container (host-mode leftover or stale).
@@ -41,7 +46,7 @@ func EnsureTmuxSessionInContainer(sessionName, containerName string, sess *Sessi
 		_ = exec.Command("tmux", "kill-session", "-t", "="+sessionName).Run()
 	}
 
-	if err := createContainerTmuxSession(sessionName, containerName, sess); err != nil {
+	if err := createContainerTmuxSession(sessionName, cli, containerName, sess); err != nil {
 		return fmt.Errorf("create tmux session: %w", err)
 	}
 
@@ -49,7 +54,7 @@ func EnsureTmuxSessionInContainer(sessionName, containerName string, sess *Sessi
 	// docker exec takes a moment to start; 3s is enough for the container
 	// state check + exec handshake.
 	time.Sleep(3 * time.Second)
-	if err := verifyPanesInContainer(sessionName, containerName); err != nil {
+	if err := verifyPanesInContainer(sessionName, cli, containerName); err != nil {
 		// Kill the broken session — do not leave host-mode panes alive.
 		_ = exec.Command("tmux", "kill-session", "-t", "="+sessionName).Run()
 		return fmt.Errorf("tmux session launched but panes are not inside container: %w", err)
@@ -58,11 +63,20 @@ func EnsureTmuxSessionInContainer(sessionName, containerName string, sess *Sessi
 	return nil
 }
 
+// ContainerCLI returns the container runtime CLI that manages a session's
+// container: podman for Podman sessions, docker otherwise.
+func ContainerCLI(meta TargetMeta) string {
+	if meta.Type == "podman" {
+		return "podman"
+	}
+	return "docker"
+}
+
 // verifyContainerRunning checks that the named container exists and is running.
-func verifyContainerRunning(containerName string) error {
-	out, err := exec.Command("docker", "inspect", "--format", "{{.State.Running}}", containerName).Output()
+func verifyContainerRunning(cli, containerName string) error {
+	out, err := exec.Command(cli, "inspect", "--format", "{{.State.Running}}", containerName).Output()
 	if err != nil {
-		return fmt.Errorf("docker inspect: %w", err)
+		return fmt.Errorf("%s inspect: %w", cli, err)
 	}
 	if strings.TrimSpace(string(out)) != "true" {
 		return fmt.Errorf("container is not running (state: %s)", strings.TrimSpace(string(out)))
@@ -75,7 +89,7 @@ func verifyContainerRunning(containerName string) error {
 // expected container, or running the guard script (waiting at reconnect
 // prompt after a service exit). A pane running an unguarded host shell
 // is the security-critical failure case.
-func verifyPanesInContainer(sessionName, containerName string) error {
+func verifyPanesInContainer(sessionName, cli, containerName string) error {
 	out, err := exec.Command("tmux", "list-panes", "-s", "-t", "="+sessionName,
 		"-F", "#{pane_pid}").Output()
 	if err != nil {
@@ -88,7 +102,7 @@ func verifyPanesInContainer(sessionName, containerName string) error {
 	}
 
 	// The exact docker exec prefix we expect to find in child processes.
-	execPrefix := "docker exec -it " + containerName + " "
+	execPrefix := cli + " exec -it " + containerName + " "
 	guardDir := hostTmuxDir(sessionName)
 
 	// Every pane must be in a safe state:
@@ -237,11 +251,14 @@ func extractDockerExecInner(cmd string) (string, bool) {
 	//   docker exec -it <name> bash -lc '<inner>'
 	//   bash -c 'while true; do ... docker exec -it <name> bash -lc '<inner>'; ...'
 	idx := strings.Index(cmd, "docker exec")
+	if podmanIdx := strings.Index(cmd, "podman exec"); podmanIdx >= 0 && (idx < 0 || podmanIdx < idx) {
+		idx = podmanIdx
+	}
 	if idx < 0 {
 		return cmd, false
 	}
 
-	// Find "bash -lc '" after "docker exec"
+	// Find "bash -lc '" after "docker exec" / "podman exec"
 	bashIdx := strings.Index(cmd[idx:], "bash -lc '")
 	if bashIdx < 0 {
 		return cmd, false
@@ -288,12 +305,12 @@ func stringVal(m map[string]interface{}, key string) string {
 // createContainerTmuxSession creates a tmux session on the host where every
 // pane runs a guarded docker exec into the container. Uses tmux commands
 // directly (not tmuxp) to avoid YAML quoting issues.
-func createContainerTmuxSession(sessionName, containerName string, sess *Session) error {
+func createContainerTmuxSession(sessionName, cli, containerName string, sess *Session) error {
 	// Find the worktree path from the container's /workspace mount.
-	out, err := exec.Command("docker", "inspect", containerName,
+	out, err := exec.Command(cli, "inspect", containerName,
 		"--format", `{{range .Mounts}}{{if eq .Destination "/workspace"}}{{.Source}}{{end}}{{end}}`).Output()
 	if err != nil {
-		return fmt.Errorf("docker inspect %q: %w", containerName, err)
+		return fmt.Errorf("%s inspect %q: %w", cli, containerName, err)
 	}
 	worktreePath := strings.TrimSpace(string(out))
 	if worktreePath == "" {
@@ -315,9 +332,17 @@ func createContainerTmuxSession(sessionName, containerName string, sess *Session
 		return err
 	}
 
+	return buildGuardedTmuxSession(sessionName, guardDir, windows, func(id string, before []string, cmd string) (string, error) {
+		return writeGuardScript(guardDir, cli, containerName, id, before, cmd)
+	})
+}
+
+// buildGuardedTmuxSession creates a detached tmux session from the parsed
+// windows, starting every pane with the guard script produced by writeScript.
+func buildGuardedTmuxSession(sessionName, guardDir string, windows []tmuxpWindow, writeScript func(id string, before []string, cmd string) (string, error)) error {
 	// Create the tmux session with the first window.
 	firstWin := windows[0]
-	firstScript, err := writeGuardScript(guardDir, containerName, "w0-p0", firstWin.Before, firstWin.Panes[0])
+	firstScript, err := writeScript("w0-p0", firstWin.Before, firstWin.Panes[0])
 	if err != nil {
 		return err
 	}
@@ -334,8 +359,7 @@ func createContainerTmuxSession(sessionName, containerName string, sess *Session
 
 	// Add remaining panes to the first window.
 	for j := 1; j < len(firstWin.Panes); j++ {
-		script, err := writeGuardScript(guardDir, containerName,
-			fmt.Sprintf("w0-p%d", j), firstWin.Before, firstWin.Panes[j])
+		script, err := writeScript(fmt.Sprintf("w0-p%d", j), firstWin.Before, firstWin.Panes[j])
 		if err != nil {
 			return err
 		}
@@ -350,8 +374,7 @@ func createContainerTmuxSession(sessionName, containerName string, sess *Session
 	// Add subsequent windows.
 	for i := 1; i < len(windows); i++ {
 		w := windows[i]
-		script, err := writeGuardScript(guardDir, containerName,
-			fmt.Sprintf("w%d-p0", i), w.Before, w.Panes[0])
+		script, err := writeScript(fmt.Sprintf("w%d-p0", i), w.Before, w.Panes[0])
 		if err != nil {
 			return err
 		}
@@ -361,8 +384,7 @@ func createContainerTmuxSession(sessionName, containerName string, sess *Session
 		}
 
 		for j := 1; j < len(w.Panes); j++ {
-			script, err := writeGuardScript(guardDir, containerName,
-				fmt.Sprintf("w%d-p%d", i, j), w.Before, w.Panes[j])
+			script, err := writeScript(fmt.Sprintf("w%d-p%d", i, j), w.Before, w.Panes[j])
 			if err != nil {
 				return err
 			}
@@ -386,17 +408,17 @@ func createContainerTmuxSession(sessionName, containerName string, sess *Session
 // 3. On exit, shows a reconnect prompt instead of dropping to host
 //
 // Using a script file avoids all nested quoting issues with tmux + bash + docker.
-func writeGuardScript(dir, containerName, id string, before []string, cmd string) (string, error) {
+func writeGuardScript(dir, cli, containerName, id string, before []string, cmd string) (string, error) {
 	parts := append(before, cmd)
 	inner := strings.Join(parts, " && ")
 
 	script := fmt.Sprintf(`#!/bin/bash
-# Guard script for container pane — DO NOT run commands outside docker exec.
+# Guard script for container pane — DO NOT run commands outside %s exec.
 # If this script exits, the pane shows a reconnect prompt.
 set -e
 CONTAINER=%q
 while true; do
-    state=$(docker inspect --format '{{.State.Running}}' "$CONTAINER" 2>/dev/null || echo "missing")
+    state=$(%s inspect --format '{{.State.Running}}' "$CONTAINER" 2>/dev/null || echo "missing")
     if [ "$state" != "true" ]; then
         echo ""
         echo "ERROR: Container $CONTAINER is not running (state: $state)"
@@ -405,12 +427,12 @@ while true; do
         read -r
         continue
     fi
-    docker exec -it "$CONTAINER" bash -lc %q || true
+    %s exec -it "$CONTAINER" bash -lc %q || true
     echo ""
     echo "=== Container session exited. Press Enter to reconnect, Ctrl-C to close ==="
     read -r
 done
-`, containerName, inner)
+`, cli, containerName, cli, cli, inner)
 
 	path := filepath.Join(dir, id+".sh")
 	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
@@ -58,7 +58,7 @@ func TestUnescapeBashSingleQuote(t *testing.T) {
 
 func TestWriteGuardScript(t *testing.T) {
 	dir := t.TempDir()
-	path, err := writeGuardScript(dir, "test-container", "w0-p0",
+	path, err := writeGuardScript(dir, "docker", "test-container", "w0-p0",
 		[]string{"cd /workspace", "export FOO=bar"},
 		"pi -c")
 	if err != nil {
@@ -94,6 +94,36 @@ func TestWriteGuardScript(t *testing.T) {
 	}
 }
 
+func TestWriteGuardScriptPodman(t *testing.T) {
+	dir := t.TempDir()
+	path, err := writeGuardScript(dir, "podman", "test-container", "w0-p0", nil, "pi -c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(content)
+	if !strings.Contains(s, "podman exec -it") || !strings.Contains(s, "podman inspect") {
+		t.Errorf("script should use podman for exec and inspect:\n%s", s)
+	}
+	if strings.Contains(s, "docker") {
+		t.Errorf("podman script should not reference docker:\n%s", s)
+	}
+}
+
+func TestContainerCLI(t *testing.T) {
+	if got := ContainerCLI(TargetMeta{Type: "podman"}); got != "podman" {
+		t.Errorf("ContainerCLI(podman) = %q", got)
+	}
+	for _, typ := range []string{"docker", "gatepost", ""} {
+		if got := ContainerCLI(TargetMeta{Type: typ}); got != "docker" {
+			t.Errorf("ContainerCLI(%q) = %q, want docker", typ, got)
+		}
+	}
+}
+
 func TestHostTmuxDir(t *testing.T) {
 	dir := hostTmuxDir("claude/magical-darwin-3GRgz")
 	if !strings.Contains(dir, ".devx") || !strings.Contains(dir, "tmux") {
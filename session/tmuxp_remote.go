@@ -0,0 +1,66 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// EnsureTmuxSessionRemote ensures a local tmux session mirrors a session that
+// runs on a remote host. Its single pane runs a guard script that attaches to
+// the remote tmux session with attach (an ssh argv) and reconnects when the
+// connection drops, so the local session never exposes a host shell.
+func EnsureTmuxSessionRemote(sessionName string, attach []string) error {
+	if len(attach) == 0 {
+		return fmt.Errorf("empty remote attach command")
+	}
+	if exec.Command("tmux", "has-session", "-t", "="+sessionName).Run() == nil {
+		if err := verifyPanesGuarded(sessionName); err == nil {
+			return nil
+		}
+		_ = exec.Command("tmux", "kill-session", "-t", "="+sessionName).Run()
+	}
+
+	guardDir := hostTmuxDir(sessionName)
+	if err := os.MkdirAll(guardDir, 0o755); err != nil {
+		return err
+	}
+	windows := []tmuxpWindow{{Name: "remote", Panes: []string{""}}}
+	err := buildGuardedTmuxSession(sessionName, guardDir, windows, func(id string, _ []string, _ string) (string, error) {
+		return writeRemoteGuardScript(guardDir, id, attach)
+	})
+	if err != nil {
+		_ = exec.Command("tmux", "kill-session", "-t", "="+sessionName).Run()
+		return fmt.Errorf("create tmux session: %w", err)
+	}
+	// Hide the local status bar; the remote tmux session draws its own.
+	_ = exec.Command("tmux", "set-option", "-t", "="+sessionName, "status", "off").Run()
+	return nil
+}
+
+// writeRemoteGuardScript writes a bash script that keeps the pane attached to
+// the remote tmux session, offering a reconnect prompt when ssh exits.
+func writeRemoteGuardScript(dir, id string, attach []string) (string, error) {
+	quoted := make([]string, len(attach))
+	for i, arg := range attach {
+		quoted[i] = shellSingleQuote(arg)
+	}
+	script := fmt.Sprintf(`#!/bin/bash
+# Guard script for remote session pane — only attaches over ssh.
+# If this script exits, the pane shows a reconnect prompt.
+while true; do
+    %s || true
+    echo ""
+    echo "=== Remote session detached or disconnected. Press Enter to reconnect, Ctrl-C to close ==="
+    read -r
+done
+`, strings.Join(quoted, " "))
+
+	path := filepath.Join(dir, id+".sh")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,26 @@
+package session
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriteRemoteGuardScript(t *testing.T) {
+	dir := t.TempDir()
+	path, err := writeRemoteGuardScript(dir, "w0-p0", []string{"ssh", "-t", "buildbox", "tmux attach-session -t '=feat'"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(content)
+	if !strings.Contains(s, `'ssh' '-t' 'buildbox' 'tmux attach-session -t '"'"'=feat'"'"''`) {
+		t.Errorf("remote guard script should quote the attach command:\n%s", s)
+	}
+	if !strings.Contains(s, "Press Enter to reconnect") {
+		t.Error("script should offer to reconnect")
+	}
+}
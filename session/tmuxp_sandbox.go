@@ -0,0 +1,121 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// EnsureTmuxSessionInSandbox ensures the named tmux session is alive on the
+// host tmux server, with every pane running inside the session's sandbox.
+// prefix is the sandbox launcher argv (ending in "--") that each pane command
+// is appended to.
+//
+// Like container sessions, the tmux session is built from .tmuxp.yaml with
+// guard scripts, so a pane never falls back to an unsandboxed host shell.
+func EnsureTmuxSessionInSandbox(sessionName string, sess *Session, prefix []string) error {
+	if sess == nil {
+		return fmt.Errorf("nil session")
+	}
+	if len(prefix) == 0 {
+		return fmt.Errorf("empty sandbox command")
+	}
+
+	if exec.Command("tmux", "has-session", "-t", "="+sessionName).Run() == nil {
+		if err := verifyPanesGuarded(sessionName); err == nil {
+			return nil
+		}
+		// Host-mode leftover or stale session — kill and recreate.
+		_ = exec.Command("tmux", "kill-session", "-t", "="+sessionName).Run()
+	}
+
+	windows, err := parseTmuxpWindows(sess.Path)
+	if err != nil {
+		return fmt.Errorf("parse tmuxp config: %w", err)
+	}
+	if len(windows) == 0 {
+		return fmt.Errorf("no windows found in .tmuxp.yaml")
+	}
+
+	guardDir := hostTmuxDir(sessionName)
+	if err := os.MkdirAll(guardDir, 0o755); err != nil {
+		return err
+	}
+	err = buildGuardedTmuxSession(sessionName, guardDir, windows, func(id string, before []string, cmd string) (string, error) {
+		return writeSandboxGuardScript(guardDir, prefix, id, before, cmd)
+	})
+	if err != nil {
+		_ = exec.Command("tmux", "kill-session", "-t", "="+sessionName).Run()
+		return fmt.Errorf("create tmux session: %w", err)
+	}
+
+	if err := verifyPanesGuarded(sessionName); err != nil {
+		_ = exec.Command("tmux", "kill-session", "-t", "="+sessionName).Run()
+		return fmt.Errorf("tmux session launched but panes are not sandboxed: %w", err)
+	}
+	return nil
+}
+
+// verifyPanesGuarded checks that every pane in the tmux session runs one of
+// the session's guard scripts.
+func verifyPanesGuarded(sessionName string) error {
+	out, err := exec.Command("tmux", "list-panes", "-s", "-t", "="+sessionName,
+		"-F", "#{pane_pid}").Output()
+	if err != nil {
+		return fmt.Errorf("list panes: %w", err)
+	}
+	pids := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(pids) == 0 || pids[0] == "" {
+		return fmt.Errorf("no panes found")
+	}
+	guardDir := hostTmuxDir(sessionName)
+	for i, pid := range pids {
+		pid = strings.TrimSpace(pid)
+		if pid == "" {
+			continue
+		}
+		psOut, _ := exec.Command("ps", "-o", "command=", "-p", pid).Output()
+		cmdLine := strings.TrimSpace(string(psOut))
+		if !strings.Contains(cmdLine, guardDir) {
+			return fmt.Errorf("pane %d (pid %s, cmd %q) is not running a sandbox guard script", i, pid, cmdLine)
+		}
+	}
+	return nil
+}
+
+// writeSandboxGuardScript writes a bash script that runs the pane command
+// inside the sandbox and, when it exits, waits for the user instead of
+// dropping to a host shell.
+func writeSandboxGuardScript(dir string, prefix []string, id string, before []string, cmd string) (string, error) {
+	parts := append(append([]string{}, before...), cmd)
+	inner := strings.Join(parts, " && ")
+
+	quoted := make([]string, len(prefix))
+	for i, arg := range prefix {
+		quoted[i] = shellSingleQuote(arg)
+	}
+
+	script := fmt.Sprintf(`#!/bin/bash
+# Guard script for sandboxed pane — DO NOT run commands outside the sandbox.
+# If this script exits, the pane shows a restart prompt.
+while true; do
+    %s bash -lc %s || true
+    echo ""
+    echo "=== Sandbox session exited. Press Enter to restart, Ctrl-C to close ==="
+    read -r
+done
+`, strings.Join(quoted, " "), shellSingleQuote(inner))
+
+	path := filepath.Join(dir, id+".sh")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// shellSingleQuote quotes s for a POSIX shell.
+func shellSingleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
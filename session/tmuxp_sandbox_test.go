@@ -0,0 +1,27 @@
+package session
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriteSandboxGuardScriptQuotes(t *testing.T) {
+	dir := t.TempDir()
+	path, err := writeSandboxGuardScript(dir, []string{"bwrap", "--setenv", "GREETING", "it's here", "--"}, "w0-p0",
+		[]string{"cd /src"}, "echo 'hi'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(content)
+	if !strings.Contains(s, `'bwrap' '--setenv' 'GREETING' 'it'"'"'s here' '--' bash -lc 'cd /src && echo '"'"'hi'"'"''`) {
+		t.Errorf("sandbox guard script should quote the launcher and pane command:\n%s", s)
+	}
+	if !strings.Contains(s, "while true") {
+		t.Error("script should loop instead of dropping to a host shell")
+	}
+}
@@ -0,0 +1,160 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	VCSGit = "git"
+	VCSJJ  = "jj"
+)
+
+// VCS is the version-control backend behind a session's workspace. Status,
+// commit and diff output is normalized to git's text formats so stale
+// analysis and reviews can treat every backend the same way.
+type VCS interface {
+	Name() string
+	// CreateWorkspace creates (or reuses) the session workspace under
+	// <repoPath>/.worktrees/<name> and returns its path.
+	CreateWorkspace(repoPath, name string, detach bool, opts WorktreeOptions) (string, error)
+	ListWorkspaces(repoPath string) ([]WorktreeInfo, error)
+	// RemoveWorkspace removes the workspace CreateWorkspace made for name.
+	RemoveWorkspace(repoPath, name, workspacePath string) error
+	// Status returns uncommitted changes in `git status --porcelain` format.
+	Status(workspacePath string, includeIgnored bool) (string, error)
+	// Head returns an identifier that changes whenever the workspace's
+	// current commit changes.
+	Head(workspacePath string) (string, error)
+	// UnpushedCommits counts commits that exist only locally. ok is false
+	// when there is nothing to compare against.
+	UnpushedCommits(workspacePath string) (count int, ok bool)
+	// UniqueCommits lists commits not in base, one "<id> <subject>" per line.
+	UniqueCommits(workspacePath, base string) (string, error)
+	// ChangedFiles lists files changed since the merge base with base, one
+	// "<status>\t<path>" per line.
+	ChangedFiles(workspacePath, base string) (string, error)
+	// ResolveBase returns the first usable base among requested or the
+	// backend's defaults.
+	ResolveBase(workspacePath, requested string) (string, error)
+}
+
+// ResolveVCS returns the backend for a project. An empty or "auto" name picks
+// jj when the project has a .jj directory (including colocated repos) and git
+// otherwise.
+func ResolveVCS(name, repoPath string) (VCS, error) {
+	switch name {
+	case "", "auto":
+		return vcsByName(DetectVCS(repoPath)), nil
+	case VCSGit, VCSJJ:
+		return vcsByName(name), nil
+	default:
+		return nil, fmt.Errorf("unknown vcs %q (valid: auto, git, jj)", name)
+	}
+}
+
+// DetectVCS reports which backend manages path.
+func DetectVCS(path string) string {
+	if info, err := os.Stat(filepath.Join(path, ".jj")); err == nil && info.IsDir() {
+		return VCSJJ
+	}
+	return VCSGit
+}
+
+// VCSForSession returns the backend that created a session's workspace.
+// Sessions created before backends were recorded are git worktrees unless the
+// workspace is a non-colocated jj workspace.
+func VCSForSession(sess *Session) VCS {
+	if sess != nil && sess.VCS != "" {
+		return vcsByName(sess.VCS)
+	}
+	if sess != nil && sess.Path != "" && DetectVCS(sess.Path) == VCSJJ {
+		if _, err := os.Stat(filepath.Join(sess.Path, ".git")); os.IsNotExist(err) {
+			return jjVCS{}
+		}
+	}
+	return gitVCS{}
+}
+
+func vcsByName(name string) VCS {
+	if name == VCSJJ {
+		return jjVCS{}
+	}
+	return gitVCS{}
+}
+
+// WorkspaceExists reports whether workspacePath is a registered workspace of
+// the repository.
+func WorkspaceExists(backend VCS, repoPath, workspacePath string) (bool, error) {
+	workspaces, err := backend.ListWorkspaces(repoPath)
+	if err != nil {
+		return false, err
+	}
+	for _, ws := range workspaces {
+		if ws.Path == workspacePath {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type gitVCS struct{}
+
+func (gitVCS) Name() string { return VCSGit }
+
+func (gitVCS) CreateWorkspace(repoPath, name string, detach bool, opts WorktreeOptions) (string, error) {
+	return CreateWorktreeWithOptions(repoPath, name, detach, opts)
+}
+
+func (gitVCS) ListWorkspaces(repoPath string) ([]WorktreeInfo, error) {
+	return ListWorktrees(repoPath)
+}
+
+func (gitVCS) RemoveWorkspace(repoPath, name, workspacePath string) error {
+	cmd := exec.Command("git", "worktree", "remove", "--force", workspacePath)
+	if repoPath != "" {
+		cmd.Dir = repoPath
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree remove failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (gitVCS) Status(workspacePath string, includeIgnored bool) (string, error) {
+	args := []string{"status", "--porcelain"}
+	if includeIgnored {
+		args = append(args, "--ignored")
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workspacePath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+func (gitVCS) Head(workspacePath string) (string, error) {
+	head, err := gitOutput(workspacePath, "rev-parse", "HEAD")
+	return strings.TrimSpace(head), err
+}
+
+func (gitVCS) UnpushedCommits(workspacePath string) (int, bool) {
+	return unpushedCommitCount(workspacePath)
+}
+
+func (gitVCS) UniqueCommits(workspacePath, base string) (string, error) {
+	return gitOutput(workspacePath, "log", "--oneline", base+"..HEAD")
+}
+
+func (gitVCS) ChangedFiles(workspacePath, base string) (string, error) {
+	return gitOutput(workspacePath, "diff", "--name-status", base+"...HEAD")
+}
+
+func (gitVCS) ResolveBase(workspacePath, requested string) (string, error) {
+	return ResolveReviewBase(workspacePath, requested)
+}
@@ -0,0 +1,249 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// jjVCS manages sessions as Jujutsu workspaces (`jj workspace add`). It works
+// for both native and git-colocated jj repositories.
+type jjVCS struct{}
+
+const jjOneLineTemplate = `commit_id.short() ++ " " ++ description.first_line() ++ "\n"`
+
+func (jjVCS) Name() string { return VCSJJ }
+
+func (j jjVCS) CreateWorkspace(repoPath, name string, detach bool, opts WorktreeOptions) (string, error) {
+	if err := ValidateSparsePaths(opts.SparsePaths); err != nil {
+		return "", err
+	}
+	if _, err := exec.LookPath("jj"); err != nil {
+		return "", fmt.Errorf("jj not found in PATH")
+	}
+	workspacePath := filepath.Join(repoPath, ".worktrees", name)
+
+	names, err := jjWorkspaceNames(repoPath)
+	if err != nil {
+		return "", err
+	}
+	_, statErr := os.Stat(workspacePath)
+	if names[name] {
+		// Like git worktrees, an intact workspace is reused even with
+		// --detach so its uncommitted work survives.
+		if statErr == nil && DetectVCS(workspacePath) == VCSJJ {
+			fmt.Printf("Reusing existing jj workspace at %s\n", workspacePath)
+			return workspacePath, nil
+		}
+		// The directory is gone or no longer a workspace.
+		if _, err := jjOutput(repoPath, "workspace", "forget", name); err != nil {
+			return "", err
+		}
+	}
+	if statErr == nil {
+		if !detach {
+			return "", fmt.Errorf("directory %s exists but is not a jj workspace. Remove it manually or use --detach", workspacePath)
+		}
+		if err := os.RemoveAll(workspacePath); err != nil {
+			return "", fmt.Errorf("failed to remove existing directory: %w", err)
+		}
+	}
+
+	if _, err := jjOutput(repoPath, "git", "fetch"); err != nil {
+		fmt.Printf("Warning: could not fetch from origin: %v\n", err)
+	}
+
+	args := []string{"workspace", "add", "--name", name}
+	// Start on top of an existing bookmark of the same name so a session can
+	// resume work that was pushed from elsewhere.
+	bookmark := fmt.Sprintf(`bookmarks(exact:%q) | remote_bookmarks(exact:%q)`, name, name)
+	if out, err := jjOutput(repoPath, "log", "--no-graph", "--limit=1", "--revisions="+bookmark, "-T", `commit_id ++ "\n"`); err == nil && strings.TrimSpace(out) != "" {
+		fmt.Printf("Found existing bookmark '%s', using it for this session.\n", name)
+		args = append(args, "--revision="+strings.TrimSpace(out))
+	}
+	args = append(args, workspacePath)
+	if _, err := jjOutput(repoPath, args...); err != nil {
+		return "", fmt.Errorf("failed to create jj workspace: %w", err)
+	}
+
+	if len(opts.SparsePaths) > 0 {
+		sparseArgs := []string{"sparse", "set", "--clear"}
+		for _, p := range normalizeSparsePaths(opts.SparsePaths) {
+			sparseArgs = append(sparseArgs, "--add", p)
+		}
+		if _, err := jjOutput(workspacePath, sparseArgs...); err != nil {
+			return "", fmt.Errorf("failed to set sparse patterns: %w", err)
+		}
+	}
+	if opts.Submodules {
+		fmt.Printf("Note: jj does not support submodules; skipping submodule init\n")
+	}
+	return workspacePath, nil
+}
+
+func (jjVCS) ListWorkspaces(repoPath string) ([]WorktreeInfo, error) {
+	names, err := jjWorkspaceNames(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	var workspaces []WorktreeInfo
+	for name := range names {
+		path := filepath.Join(repoPath, ".worktrees", name)
+		if name == "default" {
+			path = repoPath
+		}
+		workspaces = append(workspaces, WorktreeInfo{Path: path, Branch: name})
+	}
+	return workspaces, nil
+}
+
+func (jjVCS) RemoveWorkspace(repoPath, name, workspacePath string) error {
+	if repoPath != "" && filepath.Clean(repoPath) == filepath.Clean(workspacePath) {
+		return fmt.Errorf("refusing to remove the default jj workspace at %s", workspacePath)
+	}
+	dir := repoPath
+	if dir == "" {
+		dir = workspacePath
+	}
+	// The workspace was added with --name <session>, which for a name such
+	// as feat/x is not the last element of its path.
+	if _, err := jjOutput(dir, "workspace", "forget", name); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(workspacePath); err != nil {
+		return fmt.Errorf("failed to remove workspace directory: %w", err)
+	}
+	return nil
+}
+
+func (jjVCS) Status(workspacePath string, includeIgnored bool) (string, error) {
+	// jj snapshots every non-ignored file into the working-copy commit, so
+	// there are no untracked files; changes in @ are the uncommitted work.
+	out, err := jjOutput(workspacePath, "diff", "--summary", "--revisions=@")
+	if err != nil {
+		return "", err
+	}
+	return jjSummaryToPorcelain(out), nil
+}
+
+func (jjVCS) Head(workspacePath string) (string, error) {
+	out, err := jjOutput(workspacePath, "log", "--no-graph", "--revisions=@", "-T", "commit_id")
+	return strings.TrimSpace(out), err
+}
+
+func (jjVCS) UnpushedCommits(workspacePath string) (int, bool) {
+	remotes, err := jjOutput(workspacePath, "log", "--no-graph", "--limit=1", "--revisions=remote_bookmarks()", "-T", `commit_id ++ "\n"`)
+	if err != nil || strings.TrimSpace(remotes) == "" {
+		return 0, false
+	}
+	// @ itself is reported by Status, so only count its ancestors.
+	out, err := jjOutput(workspacePath, "log", "--no-graph", "--revisions=(::@- ~ ::remote_bookmarks()) ~ empty()", "-T", `commit_id ++ "\n"`)
+	if err != nil {
+		return 0, false
+	}
+	return countLines(out), true
+}
+
+func (jjVCS) UniqueCommits(workspacePath, base string) (string, error) {
+	return jjOutput(workspacePath, "log", "--no-graph", "--revisions=("+base+")..@- ~ empty()", "-T", jjOneLineTemplate)
+}
+
+func (jjVCS) ChangedFiles(workspacePath, base string) (string, error) {
+	out, err := jjOutput(workspacePath, "diff", "--summary", "--from=heads(::@- & ::("+base+"))", "--to=@-")
+	if err != nil {
+		return "", err
+	}
+	return jjSummaryToNameStatus(out), nil
+}
+
+func (jjVCS) ResolveBase(workspacePath, requested string) (string, error) {
+	candidates := []string{}
+	if requested != "" {
+		candidates = append(candidates, requested)
+	} else {
+		candidates = append(candidates, "trunk()", "main@origin", "main", "master@origin", "master")
+	}
+	for _, c := range candidates {
+		// trunk() falls back to root() when nothing matches; that is not a
+		// meaningful base, so require a non-root commit.
+		out, err := jjOutput(workspacePath, "log", "--no-graph", "--limit=1", "--revisions=("+c+") ~ root()", "-T", `commit_id ++ "\n"`)
+		if err == nil && strings.TrimSpace(out) != "" {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("could not resolve base revision (tried %s)", strings.Join(candidates, ", "))
+}
+
+func jjOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("jj", append([]string{"--color=never", "--no-pager"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("jj %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+func jjWorkspaceNames(repoPath string) (map[string]bool, error) {
+	out, err := jjOutput(repoPath, "workspace", "list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jj workspaces: %w", err)
+	}
+	return parseJJWorkspaceList(out), nil
+}
+
+// parseJJWorkspaceList extracts names from `jj workspace list` lines such as
+// "default: qpvuntsm 230dd059 (empty) (no description set)".
+func parseJJWorkspaceList(output string) map[string]bool {
+	names := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if name, _, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(name) != "" && !strings.HasPrefix(line, " ") {
+			names[strings.TrimSpace(name)] = true
+		}
+	}
+	return names
+}
+
+// jjSummaryToPorcelain converts `jj diff --summary` lines ("M path") into
+// git porcelain v1 lines (" M path").
+func jjSummaryToPorcelain(summary string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(summary, "\n"), "\n") {
+		code, path, ok := strings.Cut(line, " ")
+		if !ok || len(code) != 1 {
+			continue
+		}
+		if code == "M" {
+			fmt.Fprintf(&b, " M %s\n", path)
+		} else {
+			fmt.Fprintf(&b, "%s  %s\n", code, path)
+		}
+	}
+	return b.String()
+}
+
+// jjSummaryToNameStatus converts `jj diff --summary` lines into git
+// `--name-status` lines ("M\tpath").
+func jjSummaryToNameStatus(summary string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(summary, "\n"), "\n") {
+		code, path, ok := strings.Cut(line, " ")
+		if !ok || len(code) != 1 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s\t%s\n", code, path)
+	}
+	return b.String()
+}
+
+func countLines(s string) int {
+	n := 0
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) != "" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,179 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveVCSDetectsJJ(t *testing.T) {
+	gitRepo := t.TempDir()
+	jjRepo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(jjRepo, ".jj"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name, path, want string
+	}{
+		{"", gitRepo, VCSGit},
+		{"auto", jjRepo, VCSJJ},
+		{"git", jjRepo, VCSGit},
+		{"jj", gitRepo, VCSJJ},
+	} {
+		backend, err := ResolveVCS(tc.name, tc.path)
+		if err != nil {
+			t.Fatalf("ResolveVCS(%q): %v", tc.name, err)
+		}
+		if backend.Name() != tc.want {
+			t.Errorf("ResolveVCS(%q, %s) = %s, want %s", tc.name, tc.path, backend.Name(), tc.want)
+		}
+	}
+	if _, err := ResolveVCS("hg", gitRepo); err == nil {
+		t.Error("expected unknown vcs to be rejected")
+	}
+}
+
+func TestVCSForSession(t *testing.T) {
+	if got := VCSForSession(&Session{VCS: VCSJJ}).Name(); got != VCSJJ {
+		t.Errorf("recorded jj session = %s", got)
+	}
+
+	// A non-colocated jj workspace has .jj but no .git.
+	workspace := t.TempDir()
+	if err := os.Mkdir(filepath.Join(workspace, ".jj"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := VCSForSession(&Session{Path: workspace}).Name(); got != VCSJJ {
+		t.Errorf("jj workspace session = %s", got)
+	}
+
+	// Colocated checkouts keep using git unless recorded otherwise.
+	if err := os.WriteFile(filepath.Join(workspace, ".git"), []byte("gitdir: x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := VCSForSession(&Session{Path: workspace}).Name(); got != VCSGit {
+		t.Errorf("colocated session = %s", got)
+	}
+}
+
+func TestParseJJWorkspaceList(t *testing.T) {
+	got := parseJJWorkspaceList("default: qpvuntsm 230dd059 (empty) (no description set)\nfeat-x: rlvkpnrz 7e2e8a1f add thing\n")
+	if !got["default"] || !got["feat-x"] || len(got) != 2 {
+		t.Fatalf("parseJJWorkspaceList = %v", got)
+	}
+}
+
+func TestJJSummaryConversions(t *testing.T) {
+	summary := "M src/main.go\nA docs/new.md\nD old.txt\n"
+	if got, want := jjSummaryToPorcelain(summary), " M src/main.go\nA  docs/new.md\nD  old.txt\n"; got != want {
+		t.Errorf("porcelain = %q, want %q", got, want)
+	}
+	if got, want := jjSummaryToNameStatus(summary), "M\tsrc/main.go\nA\tdocs/new.md\nD\told.txt\n"; got != want {
+		t.Errorf("name-status = %q, want %q", got, want)
+	}
+	dirty, untracked := parsePorcelain(jjSummaryToPorcelain(summary), 10)
+	if len(dirty.files) != 3 || len(untracked.files) != 0 {
+		t.Errorf("dirty = %v, untracked = %v", dirty.files, untracked.files)
+	}
+}
+
+func TestJJWorkspaceStaleAndReview(t *testing.T) {
+	if _, err := exec.LookPath("jj"); err != nil {
+		t.Skip("jj not installed")
+	}
+	repo := makeStaleRepo(t)
+	cmd := exec.Command("jj", "git", "init", "--colocate")
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("jj git init --colocate failed: %v\n%s", err, out)
+	}
+	t.Setenv("JJ_USER", "test")
+	t.Setenv("JJ_EMAIL", "test@example.com")
+
+	backend, err := ResolveVCS("auto", repo)
+	if err != nil || backend.Name() != VCSJJ {
+		t.Fatalf("ResolveVCS = %v, %v", backend, err)
+	}
+	path, err := backend.CreateWorkspace(repo, "jj-feature", false, WorktreeOptions{})
+	if err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	if exists, err := WorkspaceExists(backend, repo, path); err != nil || !exists {
+		t.Fatalf("WorkspaceExists = %v, %v", exists, err)
+	}
+
+	old := time.Now().Add(-45 * 24 * time.Hour)
+	sess := &Session{Name: "jj-feature", Path: path, ProjectPath: repo, VCS: VCSJJ, CreatedAt: old, UpdatedAt: old}
+	if status := AnalyzeStaleSession(sess, 14*24*time.Hour); status.Category != StaleCategoryClean {
+		t.Fatalf("category = %s, reasons = %v", status.Category, status.Reasons)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "new.txt"), []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	status := AnalyzeStaleSession(sess, 14*24*time.Hour)
+	if status.Category != StaleCategoryNeedsReview || !status.HasUncommitted {
+		t.Fatalf("category = %s, reasons = %v", status.Category, status.Reasons)
+	}
+	review, err := ReviewSession(sess, ReviewOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review.Classification != ReviewClassificationDirtyOnly {
+		t.Fatalf("classification = %s (%s: %s)", review.Classification, review.Summary, review.Error)
+	}
+
+	// Creating it again with --detach reuses the workspace and keeps the
+	// uncommitted file.
+	if again, err := backend.CreateWorkspace(repo, "jj-feature", true, WorktreeOptions{}); err != nil || again != path {
+		t.Fatalf("CreateWorkspace again = %s, %v", again, err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "new.txt")); err != nil {
+		t.Fatalf("uncommitted file lost: %v", err)
+	}
+
+	if err := backend.RemoveWorkspace(repo, "jj-feature", path); err != nil {
+		t.Fatalf("RemoveWorkspace: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("workspace directory still exists: %v", err)
+	}
+}
+
+func TestJJRemoveWorkspaceForgetsSessionName(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script stub")
+	}
+	bin := t.TempDir()
+	logPath := filepath.Join(bin, "jj.log")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$@\" >> %s\n", logPath)
+	if err := os.WriteFile(filepath.Join(bin, "jj"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	repo := t.TempDir()
+	path := filepath.Join(repo, ".worktrees", "feat", "x")
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := (jjVCS{}).RemoveWorkspace(repo, "feat/x", path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "--color=never --no-pager workspace forget feat/x" {
+		t.Fatalf("jj called with %q", got)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("workspace directory still exists: %v", err)
+	}
+}
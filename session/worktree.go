@@ -85,11 +85,40 @@ func IsWorktreeCheckedOut(repoPath, branchName string) (bool, string, error) {
 	return false, "", nil
 }
 
+// WorktreeOptions tunes how a new worktree is materialized. The zero value
+// produces a full checkout, matching the historical CreateWorktree behavior.
+type WorktreeOptions struct {
+	// SparsePaths are cone-mode sparse-checkout directories. When non-empty the
+	// worktree is added with --no-checkout, the cone is configured, and only
+	// then are files checked out.
+	SparsePaths []string
+	// SkipLFS sets GIT_LFS_SKIP_SMUDGE so LFS pointers are not downloaded
+	// during checkout. Run `git lfs pull` inside the worktree to fetch them.
+	SkipLFS bool
+	// Filter is a partial-clone object filter (e.g. "blob:none") applied to the
+	// pre-create fetch. The first filtered fetch converts the repository into a
+	// partial clone, so later sparse checkouts only download blobs in the cone.
+	Filter string
+	// Submodules initializes and checks out submodules after the worktree is
+	// created, borrowing objects from the main checkout's submodules.
+	Submodules bool
+}
+
 // CreateWorktree creates a new git worktree and returns the path to it.
 // If the branch is already checked out in an existing worktree (anywhere in
 // the repo — including paths created by external tools like Claude Code),
 // that existing worktree path is returned and reused rather than failing.
 func CreateWorktree(repoPath, name string, detach bool) (string, error) {
+	return CreateWorktreeWithOptions(repoPath, name, detach, WorktreeOptions{})
+}
+
+// CreateWorktreeWithOptions is CreateWorktree with sparse-checkout, LFS and
+// partial-clone tuning. Reused or adopted worktrees are returned as-is; the
+// options only apply to worktrees created by this call.
+func CreateWorktreeWithOptions(repoPath, name string, detach bool, opts WorktreeOptions) (string, error) {
+	if err := ValidateSparsePaths(opts.SparsePaths); err != nil {
+		return "", err
+	}
 	worktreePath := filepath.Join(repoPath, ".worktrees", name)
 
 	// Prune stale worktree registrations upfront. This handles the case where a
@@ -151,7 +180,7 @@ func CreateWorktree(repoPath, name string, detach bool) (string, error) {
 	}
 
 	// Fetch from origin to ensure remote refs are current (non-fatal)
-	if err := FetchOrigin(repoPath); err != nil {
+	if err := fetchOriginWithFilter(repoPath, opts.Filter); err != nil {
 		fmt.Printf("Warning: could not fetch from origin: %v\n", err)
 	}
 
@@ -161,13 +190,20 @@ func CreateWorktree(repoPath, name string, detach bool) (string, error) {
 		return "", err
 	}
 
+	// Sparse worktrees are added without a checkout so the cone can be set
+	// before any files are written.
+	addArgs := []string{"worktree", "add"}
+	if len(opts.SparsePaths) > 0 {
+		addArgs = append(addArgs, "--no-checkout")
+	}
+
 	var (
 		cmd             *exec.Cmd
 		pullAfterCreate bool
 	)
 	if branchExists {
 		// Local branch exists — check it out, then pull to get any remote commits
-		cmd = exec.Command("git", "worktree", "add", worktreePath, name)
+		cmd = exec.Command("git", append(addArgs, worktreePath, name)...)
 		pullAfterCreate = true
 	} else {
 		// Check for remote branch
@@ -179,14 +215,17 @@ func CreateWorktree(repoPath, name string, detach bool) (string, error) {
 			// Create local branch from remote, tracking origin.
 			// We just fetched, so origin/<name> is already up-to-date.
 			fmt.Printf("Found existing remote branch '%s', using it for this session.\n", name)
-			cmd = exec.Command("git", "worktree", "add", "-b", name, worktreePath, "origin/"+name)
+			cmd = exec.Command("git", append(addArgs, "-b", name, worktreePath, "origin/"+name)...)
 		} else {
 			// No existing branch — create new from HEAD (current behavior)
-			cmd = exec.Command("git", "worktree", "add", "-b", name, worktreePath)
+			cmd = exec.Command("git", append(addArgs, "-b", name, worktreePath)...)
 		}
 	}
 
 	cmd.Dir = repoPath
+	if opts.SkipLFS {
+		cmd.Env = append(os.Environ(), "GIT_LFS_SKIP_SMUDGE=1")
+	}
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		// Both old and new git versions report the branch-in-use error differently:
@@ -205,6 +244,12 @@ func CreateWorktree(repoPath, name string, detach bool) (string, error) {
 		return "", fmt.Errorf("failed to create worktree: %w\n%s", err, output)
 	}
 
+	if len(opts.SparsePaths) > 0 {
+		if err := checkoutSparse(worktreePath, opts.SparsePaths, opts.SkipLFS); err != nil {
+			return "", err
+		}
+	}
+
 	// For existing local branches, pull from remote to pick up any commits we're missing
 	if pullAfterCreate {
 		if pullErr := PullFromOrigin(worktreePath, name); pullErr != nil {
@@ -222,13 +267,28 @@ func CreateWorktree(repoPath, name string, detach bool) (string, error) {
 		}
 	}
 
+	if opts.Submodules {
+		if err := initSubmodules(repoPath, worktreePath, opts.SkipLFS); err != nil {
+			fmt.Printf("Warning: failed to initialize submodules: %v\n", err)
+		}
+	}
+
 	return worktreePath, nil
 }
 
 // FetchOrigin fetches remote refs from origin, pruning deleted branches.
 // Failure is non-fatal (caller warns and continues).
 func FetchOrigin(repoPath string) error {
-	cmd := exec.Command("git", "fetch", "origin", "--prune")
+	return fetchOriginWithFilter(repoPath, "")
+}
+
+// fetchOriginWithFilter is FetchOrigin with an optional partial-clone filter.
+func fetchOriginWithFilter(repoPath, filter string) error {
+	args := []string{"fetch", "origin", "--prune"}
+	if filter != "" {
+		args = append(args, "--filter="+filter)
+	}
+	cmd := exec.Command("git", args...)
 	cmd.Dir = repoPath
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git fetch failed: %w\n%s", err, output)
@@ -0,0 +1,250 @@
+package target
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jfox85/devx/caddy"
+)
+
+// Cache volume labels, used to list and prune volumes DevX created.
+const (
+	CacheLabel        = "devx.cache"         // cache name
+	CacheLabelProject = "devx.cache.project" // project the cache belongs to
+)
+
+// CacheSpec is a project cache directory shared by all of the project's
+// container sessions through a named volume.
+type CacheSpec struct {
+	Name     string // e.g. "gomod"
+	Path     string // path in the container; "~" is the container user's home
+	Gatepost string // access for gatepost sessions: "read-write" (default), "read-only" or "none"
+}
+
+// CacheMount is a resolved cache volume mount.
+type CacheMount struct {
+	Volume   string
+	Target   string
+	ReadOnly bool
+	Project  string
+	Name     string
+}
+
+// CacheVolume is a cache volume in the local volume store.
+type CacheVolume struct {
+	Name    string `json:"name"`
+	Project string `json:"project"`
+	Cache   string `json:"cache"`
+	Size    string `json:"size,omitempty"`
+	Links   int    `json:"links"` // containers using the volume; -1 if unknown
+	CLI     string `json:"cli"`
+}
+
+// CacheVolumeName is the shared volume backing a project's cache.
+func CacheVolumeName(project, cache string) string {
+	return "devx-cache-" + caddy.SanitizeHostname(project) + "-" + caddy.SanitizeHostname(cache)
+}
+
+// ResolveCacheMounts turns cache specs into mounts for a session of the given
+// target type. user is the container user (empty means root) and decides
+// where "~" points.
+func ResolveCacheMounts(project, targetType, user string, caches []CacheSpec) ([]CacheMount, error) {
+	home := "/root"
+	if user != "" && user != "root" && user != "0" {
+		home = "/home/" + user
+	}
+	var mounts []CacheMount
+	seen := make(map[string]bool)
+	for _, c := range caches {
+		if c.Name == "" || c.Path == "" {
+			return nil, fmt.Errorf("cache entries need a name and a path")
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("cache %q is defined twice", c.Name)
+		}
+		seen[c.Name] = true
+
+		p := c.Path
+		if p == "~" || strings.HasPrefix(p, "~/") {
+			p = home + p[1:]
+		}
+		p = path.Clean(p)
+		if !path.IsAbs(p) {
+			return nil, fmt.Errorf("cache %q: path must be absolute or start with ~/: %s", c.Name, c.Path)
+		}
+		if p == "/" || p == "/workspace" || strings.HasPrefix(p, "/workspace/") {
+			return nil, fmt.Errorf("cache %q: cannot mount over %s", c.Name, p)
+		}
+
+		readOnly := false
+		if targetType == "gatepost" {
+			switch c.Gatepost {
+			case "", "read-write":
+			case "read-only":
+				readOnly = true
+			case "none":
+				continue
+			default:
+				return nil, fmt.Errorf("cache %q: gatepost must be read-write, read-only or none", c.Name)
+			}
+		}
+		mounts = append(mounts, CacheMount{
+			Volume:   CacheVolumeName(project, c.Name),
+			Target:   p,
+			ReadOnly: readOnly,
+			Project:  project,
+			Name:     c.Name,
+		})
+	}
+	return mounts, nil
+}
+
+// cacheMountArgs returns the --mount flags for cache volumes.
+func cacheMountArgs(mounts []CacheMount) []string {
+	var args []string
+	for _, m := range mounts {
+		spec := "type=volume,source=" + m.Volume + ",target=" + m.Target
+		if m.ReadOnly {
+			spec += ",readonly"
+		}
+		args = append(args, "--mount", spec)
+	}
+	return args
+}
+
+// ensureCacheVolumes creates missing cache volumes with DevX labels, so they
+// show up in `devx cache list` rather than as anonymous auto-created volumes.
+func ensureCacheVolumes(ctx context.Context, cli string, mounts []CacheMount) error {
+	for _, m := range mounts {
+		if cliRunIgnore(ctx, cli, "volume", "inspect", m.Volume) == nil {
+			continue
+		}
+		if err := cliRun(ctx, cli, "volume", "create",
+			"--label", CacheLabel+"="+m.Name,
+			"--label", CacheLabelProject+"="+m.Project,
+			m.Volume); err != nil {
+			return fmt.Errorf("create cache volume %s: %w", m.Volume, err)
+		}
+	}
+	return nil
+}
+
+// ListCacheVolumes lists DevX cache volumes for every available container
+// runtime.
+func ListCacheVolumes(ctx context.Context) ([]CacheVolume, error) {
+	var volumes []CacheVolume
+	var errs []string
+	for _, cli := range []string{"docker", "podman"} {
+		if _, err := exec.LookPath(cli); err != nil {
+			continue
+		}
+		out, err := cliOutput(ctx, cli, "volume", "ls", "--filter", "label="+CacheLabel, "--format", "{{json .}}")
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", cli, err))
+			continue
+		}
+		vols := parseCacheVolumes(out, cli)
+		usage := volumeUsage(ctx, cli)
+		for i := range vols {
+			vols[i].Links = -1
+			if u, ok := usage[vols[i].Name]; ok {
+				vols[i].Size = u.size
+				vols[i].Links = u.links
+			}
+		}
+		volumes = append(volumes, vols...)
+	}
+	if len(volumes) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("list cache volumes: %s", strings.Join(errs, "; "))
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+	return volumes, nil
+}
+
+// RemoveCacheVolume deletes a cache volume. The runtime refuses to remove
+// volumes that a container still uses.
+func RemoveCacheVolume(ctx context.Context, v CacheVolume) error {
+	return cliRun(ctx, v.CLI, "volume", "rm", v.Name)
+}
+
+func parseCacheVolumes(out, cli string) []CacheVolume {
+	var volumes []CacheVolume
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry struct {
+			Name   string
+			Labels json.RawMessage
+		}
+		if json.Unmarshal([]byte(line), &entry) != nil || entry.Name == "" {
+			continue
+		}
+		labels := parseLabels(entry.Labels)
+		volumes = append(volumes, CacheVolume{
+			Name:    entry.Name,
+			Project: labels[CacheLabelProject],
+			Cache:   labels[CacheLabel],
+			CLI:     cli,
+		})
+	}
+	return volumes
+}
+
+// parseLabels accepts docker's "k=v,k2=v2" string and podman's label object.
+func parseLabels(raw json.RawMessage) map[string]string {
+	labels := make(map[string]string)
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		for _, kv := range strings.Split(s, ",") {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				labels[k] = v
+			}
+		}
+		return labels
+	}
+	_ = json.Unmarshal(raw, &labels)
+	return labels
+}
+
+type volumeUsageInfo struct {
+	size  string
+	links int
+}
+
+// volumeUsage reads per-volume size and container counts from `system df -v`.
+func volumeUsage(ctx context.Context, cli string) map[string]volumeUsageInfo {
+	usage := make(map[string]volumeUsageInfo)
+	out, err := cliOutput(ctx, cli, "system", "df", "-v", "--format", "{{json .}}")
+	if err != nil {
+		return usage
+	}
+	var df struct {
+		Volumes []struct {
+			Name       string
+			VolumeName string
+			Size       string
+			Links      json.Number
+		}
+	}
+	if json.Unmarshal([]byte(out), &df) != nil {
+		return usage
+	}
+	for _, v := range df.Volumes {
+		name := v.Name
+		if name == "" {
+			name = v.VolumeName
+		}
+		links, _ := v.Links.Int64()
+		usage[name] = volumeUsageInfo{size: v.Size, links: int(links)}
+	}
+	return usage
+}
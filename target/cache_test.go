@@ -0,0 +1,92 @@
+package target
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveCacheMounts(t *testing.T) {
+	caches := []CacheSpec{
+		{Name: "gomod", Path: "/root/go/pkg/mod"},
+		{Name: "npm", Path: "~/.npm", Gatepost: "read-only"},
+		{Name: "pip", Path: "~/.cache/pip", Gatepost: "none"},
+	}
+	mounts, err := ResolveCacheMounts("My App", "docker", "", caches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CacheMount{
+		{Volume: "devx-cache-my-app-gomod", Target: "/root/go/pkg/mod", Project: "My App", Name: "gomod"},
+		{Volume: "devx-cache-my-app-npm", Target: "/root/.npm", Project: "My App", Name: "npm"},
+		{Volume: "devx-cache-my-app-pip", Target: "/root/.cache/pip", Project: "My App", Name: "pip"},
+	}
+	if !reflect.DeepEqual(mounts, want) {
+		t.Errorf("docker mounts = %+v, want %+v", mounts, want)
+	}
+
+	mounts, err = ResolveCacheMounts("app", "gatepost", "", caches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 2 || mounts[0].ReadOnly || !mounts[1].ReadOnly {
+		t.Errorf("gatepost mounts = %+v", mounts)
+	}
+
+	mounts, err = ResolveCacheMounts("app", "docker", "node", caches[1:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mounts[0].Target != "/home/node/.npm" {
+		t.Errorf("~ should expand to the container user's home, got %s", mounts[0].Target)
+	}
+}
+
+func TestResolveCacheMountsRejectsBadEntries(t *testing.T) {
+	for _, caches := range [][]CacheSpec{
+		{{Name: "x"}},
+		{{Name: "x", Path: "relative/dir"}},
+		{{Name: "x", Path: "/workspace/node_modules"}},
+		{{Name: "x", Path: "/"}},
+		{{Name: "x", Path: "/a"}, {Name: "x", Path: "/b"}},
+		{{Name: "x", Path: "/a", Gatepost: "sometimes"}},
+	} {
+		if _, err := ResolveCacheMounts("app", "gatepost", "", caches); err == nil {
+			t.Errorf("expected an error for %+v", caches)
+		}
+	}
+}
+
+func TestContainerRunArgsCaches(t *testing.T) {
+	args, _ := containerRunArgs("devx-feat", "devx-feat-net", StartOpts{
+		WorktreePath: "/wt",
+		Caches: []CacheMount{
+			{Volume: "devx-cache-app-gomod", Target: "/root/go/pkg/mod"},
+			{Volume: "devx-cache-app-npm", Target: "/root/.npm", ReadOnly: true},
+		},
+	})
+	joined := strings.Join(args, " ")
+	for _, want := range []string{
+		"--mount type=volume,source=devx-cache-app-gomod,target=/root/go/pkg/mod",
+		"--mount type=volume,source=devx-cache-app-npm,target=/root/.npm,readonly",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("run args missing %q: %s", want, joined)
+		}
+	}
+}
+
+func TestParseCacheVolumes(t *testing.T) {
+	out := `{"Driver":"local","Labels":"devx.cache=gomod,devx.cache.project=app","Name":"devx-cache-app-gomod"}
+{"Name":"devx-cache-web-npm","Labels":{"devx.cache":"npm","devx.cache.project":"web"}}
+not json
+`
+	got := parseCacheVolumes(out, "docker")
+	want := []CacheVolume{
+		{Name: "devx-cache-app-gomod", Project: "app", Cache: "gomod", CLI: "docker"},
+		{Name: "devx-cache-web-npm", Project: "web", Cache: "npm", CLI: "docker"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("volumes = %+v, want %+v", got, want)
+	}
+}
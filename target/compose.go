@@ -0,0 +1,413 @@
+package target
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jfox85/devx/session"
+)
+
+// ComposeTarget runs the project's Docker Compose stack (databases, caches,
+// workers) for each session, plus a dev container attached to the compose
+// network. Each session gets its own compose project, named after its
+// container, so stacks of different sessions never collide.
+type ComposeTarget struct{}
+
+// ComposeRuntimeConfig selects the compose stack for a session.
+type ComposeRuntimeConfig struct {
+	Files         []string // compose files relative to the worktree; auto-detected when empty
+	Network       string   // network to attach the dev container to; default <project>_default
+	RemoveVolumes bool     // remove named volumes on teardown
+}
+
+// ComposeService is the state of one service container in a compose project.
+type ComposeService struct {
+	Service string `json:"service"`
+	Name    string `json:"name"`
+	State   string `json:"state"`
+	Health  string `json:"health,omitempty"`
+}
+
+// defaultComposeFiles are looked up in the worktree, in compose's own order,
+// when no files are configured.
+var defaultComposeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+func (c *ComposeTarget) Type() string { return "compose" }
+
+func (c *ComposeTarget) Start(ctx context.Context, opts StartOpts) (*StartResult, error) {
+	files, err := resolveComposeFiles(opts.WorktreePath, opts.Compose.Files)
+	if err != nil {
+		return nil, err
+	}
+	meta := session.ComposeMeta{
+		Project:       ContainerName(opts.SessionName),
+		Files:         files,
+		RemoveVolumes: opts.Compose.RemoveVolumes,
+	}
+	netName := opts.Compose.Network
+	if netName == "" {
+		netName = meta.Project + "_default"
+	}
+
+	// Services substitute the session's ports and env via ${VAR}.
+	env := composeEnv(opts)
+	if err := checkComposeConfig(ctx, meta, opts.WorktreePath, env); err != nil {
+		return nil, err
+	}
+	up := exec.CommandContext(ctx, "docker", append(composeArgs(meta), "up", "-d", "--remove-orphans")...)
+	up.Dir = opts.WorktreePath
+	up.Env = env
+	if out, err := up.CombinedOutput(); err != nil {
+		// Services that did start would otherwise outlive the failed session.
+		_ = composeDown(ctx, meta)
+		return nil, fmt.Errorf("docker compose up: %w\n%s", err, strings.TrimSpace(string(out)))
+	}
+
+	// Ports named after a compose service are published by that service; the
+	// dev container publishes the rest.
+	services, _ := composeServiceNames(ctx, meta, opts.WorktreePath, env)
+	devOpts := opts
+	devOpts.HostPorts = make(map[string]int)
+	for svc, port := range opts.HostPorts {
+		if !services[strings.ToLower(svc)] {
+			devOpts.HostPorts[svc] = port
+		}
+	}
+	devOpts.Labels = make(map[string]string, len(opts.Labels)+1)
+	for k, v := range opts.Labels {
+		devOpts.Labels[k] = v
+	}
+	devOpts.Labels["devx.compose_project"] = meta.Project
+
+	name := ContainerName(opts.SessionName)
+	if err := ensureCacheVolumes(ctx, "docker", opts.Caches); err != nil {
+		_ = composeDown(ctx, meta)
+		return nil, err
+	}
+	args, image := containerRunArgs(name, netName, devOpts)
+	if err := dockerRun(ctx, args...); err != nil {
+		_ = composeDown(ctx, meta)
+		return nil, fmt.Errorf("create dev container: %w", err)
+	}
+	containerID, err := dockerOutput(ctx, "inspect", "--format", "{{.Id}}", name)
+	if err != nil {
+		_ = dockerRunIgnore(ctx, "rm", "-f", name)
+		_ = composeDown(ctx, meta)
+		return nil, fmt.Errorf("inspect container: %w", err)
+	}
+
+	return &StartResult{
+		Meta: session.TargetMeta{
+			Type:          "compose",
+			ContainerID:   containerID,
+			ContainerName: name,
+			NetworkName:   netName,
+			Image:         image,
+			Compose:       meta,
+		},
+	}, nil
+}
+
+// Stop removes the dev container and takes the compose project down,
+// including named volumes when the session was created with remove_volumes.
+func (c *ComposeTarget) Stop(ctx context.Context, meta session.TargetMeta) error {
+	var errs []string
+	if meta.ContainerName != "" {
+		if err := dockerRun(ctx, "rm", "-f", meta.ContainerName); err != nil && !isDockerNotFound(err) {
+			errs = append(errs, fmt.Sprintf("rm dev container: %v", err))
+		}
+	}
+	if meta.Compose.Project != "" {
+		if err := composeDown(ctx, meta.Compose); err != nil {
+			errs = append(errs, fmt.Sprintf("compose down: %v", err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("compose teardown: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// CheckComposeAvailable returns nil if Docker and the compose plugin are
+// available, or an error with a clear message.
+func CheckComposeAvailable() error {
+	if err := CheckAvailable(); err != nil {
+		return err
+	}
+	cmd := exec.Command("docker", "compose", "version")
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Docker Compose v2 is not available. Install the docker compose plugin to use --target compose")
+	}
+	return nil
+}
+
+// ComposeServices reports the state of every service container in a compose
+// session's project.
+func ComposeServices(ctx context.Context, meta session.TargetMeta) ([]ComposeService, error) {
+	if meta.Compose.Project == "" {
+		return nil, nil
+	}
+	out, err := exec.CommandContext(ctx, "docker", "compose", "-p", meta.Compose.Project, "ps", "--all", "--format", "json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker compose ps: %w", err)
+	}
+	return parseComposePS(out)
+}
+
+// parseComposePS accepts both output shapes of `docker compose ps --format
+// json`: a JSON array (compose < 2.21) and one object per line (>= 2.21).
+func parseComposePS(out []byte) ([]ComposeService, error) {
+	type psEntry struct {
+		Service string
+		Name    string
+		State   string
+		Health  string
+	}
+	var entries []psEntry
+	trimmed := strings.TrimSpace(string(out))
+	if trimmed == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal([]byte(trimmed), &entries); err != nil {
+			return nil, fmt.Errorf("parse compose ps output: %w", err)
+		}
+	} else {
+		for _, line := range strings.Split(trimmed, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			var e psEntry
+			if err := json.Unmarshal([]byte(line), &e); err != nil {
+				return nil, fmt.Errorf("parse compose ps output: %w", err)
+			}
+			entries = append(entries, e)
+		}
+	}
+	services := make([]ComposeService, 0, len(entries))
+	for _, e := range entries {
+		services = append(services, ComposeService{Service: e.Service, Name: e.Name, State: e.State, Health: e.Health})
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Service < services[j].Service })
+	return services, nil
+}
+
+// resolveComposeFiles returns absolute compose file paths, auto-detecting
+// the standard file names when none are configured.
+func resolveComposeFiles(worktreePath string, configured []string) ([]string, error) {
+	if len(configured) == 0 {
+		for _, name := range defaultComposeFiles {
+			path := filepath.Join(worktreePath, name)
+			if _, err := os.Stat(path); err == nil {
+				return []string{path}, nil
+			}
+		}
+		return nil, fmt.Errorf("no compose file found in %s (looked for %s); set compose.files", worktreePath, strings.Join(defaultComposeFiles, ", "))
+	}
+	var files []string
+	for _, f := range configured {
+		clean := filepath.Clean(f)
+		if filepath.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+			return nil, fmt.Errorf("compose file must be inside the worktree: %s", f)
+		}
+		path := filepath.Join(worktreePath, clean)
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("compose file %s: %w", f, err)
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// composeArgs returns the `docker compose` arguments selecting a session's
+// project and files.
+func composeArgs(meta session.ComposeMeta) []string {
+	args := []string{"compose", "-p", meta.Project}
+	for _, f := range meta.Files {
+		args = append(args, "-f", f)
+	}
+	return args
+}
+
+// composeEnv is the environment compose interpolates the stack with: the
+// caller's environment plus the session's *_PORT variables and container env.
+func composeEnv(opts StartOpts) []string {
+	env := os.Environ()
+	for svc, port := range opts.HostPorts {
+		env = append(env, fmt.Sprintf("%s_PORT=%d", strings.ToUpper(strings.ReplaceAll(svc, "-", "_")), port))
+	}
+	keys := make([]string, 0, len(opts.Env))
+	for k := range opts.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+opts.Env[k])
+	}
+	return env
+}
+
+func composeServiceNames(ctx context.Context, meta session.ComposeMeta, dir string, env []string) (map[string]bool, error) {
+	cmd := exec.CommandContext(ctx, "docker", append(composeArgs(meta), "config", "--services")...)
+	cmd.Dir = dir
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			names[strings.ToLower(line)] = true
+		}
+	}
+	return names, nil
+}
+
+// composeConfig is the part of `docker compose config --format json` that
+// can reach the host.
+type composeConfig struct {
+	Services map[string]struct {
+		Privileged  bool     `json:"privileged"`
+		CapAdd      []string `json:"cap_add"`
+		Devices     []any    `json:"devices"`
+		SecurityOpt []string `json:"security_opt"`
+		NetworkMode string   `json:"network_mode"`
+		Pid         string   `json:"pid"`
+		Ipc         string   `json:"ipc"`
+		Uts         string   `json:"uts"`
+		UsernsMode  string   `json:"userns_mode"`
+		Cgroup      string   `json:"cgroup"`
+		Build       *struct {
+			Context            string            `json:"context"`
+			AdditionalContexts map[string]string `json:"additional_contexts"`
+		} `json:"build"`
+		Volumes []struct {
+			Type   string `json:"type"`
+			Source string `json:"source"`
+		} `json:"volumes"`
+	} `json:"services"`
+	Volumes map[string]struct {
+		DriverOpts map[string]string `json:"driver_opts"`
+	} `json:"volumes"`
+	Secrets map[string]struct {
+		File string `json:"file"`
+	} `json:"secrets"`
+	Configs map[string]struct {
+		File string `json:"file"`
+	} `json:"configs"`
+}
+
+// checkComposeConfig refuses a compose stack that would reach the host. The
+// compose files come from the worktree, so like devcontainer.json they must
+// not grant privileges or bind host paths outside the worktree.
+func checkComposeConfig(ctx context.Context, meta session.ComposeMeta, dir string, env []string) error {
+	cmd := exec.CommandContext(ctx, "docker", append(composeArgs(meta), "config", "--format", "json")...)
+	cmd.Dir = dir
+	cmd.Env = env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("docker compose config: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return validateComposeConfig(out, dir)
+}
+
+func validateComposeConfig(data []byte, worktreePath string) error {
+	var cfg composeConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("parse compose config: %w", err)
+	}
+	var problems []string
+	inWorktree := func(what, path string) {
+		if _, err := resolveBindSource(path, worktreePath); err != nil {
+			problems = append(problems, fmt.Sprintf("%s %s: %v", what, path, err))
+		}
+	}
+	names := make([]string, 0, len(cfg.Services))
+	for name := range cfg.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		svc := cfg.Services[name]
+		if svc.Privileged {
+			problems = append(problems, name+": privileged")
+		}
+		if len(svc.CapAdd) > 0 {
+			problems = append(problems, name+": cap_add "+strings.Join(svc.CapAdd, ","))
+		}
+		if len(svc.Devices) > 0 {
+			problems = append(problems, name+": devices")
+		}
+		for _, opt := range svc.SecurityOpt {
+			if !strings.HasPrefix(opt, "no-new-privileges") {
+				problems = append(problems, name+": security_opt "+opt)
+			}
+		}
+		for key, mode := range map[string]string{"network_mode": svc.NetworkMode, "pid": svc.Pid, "ipc": svc.Ipc, "uts": svc.Uts, "userns_mode": svc.UsernsMode, "cgroup": svc.Cgroup} {
+			if mode == "host" {
+				problems = append(problems, name+": "+key+": host")
+			}
+		}
+		if svc.Build != nil {
+			// Local contexts are absolute after compose config; the rest are
+			// URLs or images.
+			contexts := []string{svc.Build.Context}
+			for _, c := range svc.Build.AdditionalContexts {
+				contexts = append(contexts, c)
+			}
+			for _, c := range contexts {
+				if filepath.IsAbs(c) {
+					inWorktree(name+": build context", c)
+				}
+			}
+		}
+		for _, v := range svc.Volumes {
+			if v.Type == "bind" {
+				inWorktree(name+": bind mount of", v.Source)
+			}
+		}
+	}
+	for name, v := range cfg.Volumes {
+		if v.DriverOpts["device"] != "" {
+			problems = append(problems, "volume "+name+": driver_opts device")
+		}
+	}
+	for name, sec := range cfg.Secrets {
+		if sec.File != "" {
+			inWorktree("secret "+name+" file", sec.File)
+		}
+	}
+	for name, c := range cfg.Configs {
+		if c.File != "" {
+			inWorktree("config "+name+" file", c.File)
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("compose stack would reach the host: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// composeDown stops and removes the compose project by name, so it works
+// even if the compose files have since changed or been deleted.
+func composeDown(ctx context.Context, meta session.ComposeMeta) error {
+	args := []string{"compose", "-p", meta.Project, "down", "--remove-orphans"}
+	if meta.RemoveVolumes {
+		args = append(args, "--volumes")
+	}
+	return dockerRun(ctx, args...)
+}
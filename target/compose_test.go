@@ -0,0 +1,107 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jfox85/devx/session"
+)
+
+func TestParseComposePS(t *testing.T) {
+	array := `[{"Service":"redis","Name":"devx-a-redis-1","State":"running"},{"Service":"db","Name":"devx-a-db-1","State":"running","Health":"healthy"}]`
+	ndjson := `{"Service":"redis","Name":"devx-a-redis-1","State":"running"}
+{"Service":"db","Name":"devx-a-db-1","State":"running","Health":"healthy"}
+`
+	want := []ComposeService{
+		{Service: "db", Name: "devx-a-db-1", State: "running", Health: "healthy"},
+		{Service: "redis", Name: "devx-a-redis-1", State: "running"},
+	}
+	for name, out := range map[string]string{"array": array, "ndjson": ndjson} {
+		got, err := parseComposePS([]byte(out))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %+v, want %+v", name, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: services[%d] = %+v, want %+v", name, i, got[i], want[i])
+			}
+		}
+	}
+	if got, err := parseComposePS([]byte("\n")); err != nil || got != nil {
+		t.Errorf("empty output: got %v, %v", got, err)
+	}
+}
+
+func TestResolveComposeFiles(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := resolveComposeFiles(dir, nil); err == nil {
+		t.Fatal("expected an error when no compose file exists")
+	}
+	for _, name := range []string{"docker-compose.yml", "compose.dev.yaml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("services: {}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := resolveComposeFiles(dir, nil)
+	if err != nil || len(files) != 1 || files[0] != filepath.Join(dir, "docker-compose.yml") {
+		t.Fatalf("auto-detect = %v, %v", files, err)
+	}
+	files, err = resolveComposeFiles(dir, []string{"docker-compose.yml", "./compose.dev.yaml"})
+	if err != nil || len(files) != 2 || files[1] != filepath.Join(dir, "compose.dev.yaml") {
+		t.Fatalf("configured files = %v, %v", files, err)
+	}
+	for _, bad := range []string{"../compose.yaml", "/etc/compose.yaml", "missing.yaml"} {
+		if _, err := resolveComposeFiles(dir, []string{bad}); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestComposeArgsAndEnv(t *testing.T) {
+	args := strings.Join(composeArgs(session.ComposeMeta{Project: "devx-feat", Files: []string{"/wt/a.yaml", "/wt/b.yaml"}}), " ")
+	if args != "compose -p devx-feat -f /wt/a.yaml -f /wt/b.yaml" {
+		t.Fatalf("compose args = %q", args)
+	}
+	env := strings.Join(composeEnv(StartOpts{
+		HostPorts: map[string]int{"web": 3001, "api-server": 3002},
+		Env:       map[string]string{"SESSION_NAME": "feat"},
+	}), "\n")
+	for _, want := range []string{"WEB_PORT=3001", "API_SERVER_PORT=3002", "SESSION_NAME=feat"} {
+		if !strings.Contains(env, want) {
+			t.Errorf("compose env missing %s", want)
+		}
+	}
+}
+
+func TestValidateComposeConfig(t *testing.T) {
+	worktree := t.TempDir()
+	if err := os.Mkdir(filepath.Join(worktree, "data"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ok := `{"services":{"db":{"image":"postgres","cap_add":[],"volumes":[{"type":"volume","source":"pgdata","target":"/var/lib/postgresql/data"},{"type":"bind","source":"` + filepath.Join(worktree, "data") + `","target":"/seed"}],"build":{"context":"` + worktree + `"}}},"volumes":{"pgdata":{}}}`
+	if err := validateComposeConfig([]byte(ok), worktree); err != nil {
+		t.Fatalf("worktree-only stack rejected: %v", err)
+	}
+
+	for _, bad := range []string{
+		`{"services":{"app":{"privileged":true}}}`,
+		`{"services":{"app":{"cap_add":["SYS_ADMIN"]}}}`,
+		`{"services":{"app":{"devices":["/dev/sda:/dev/sda"]}}}`,
+		`{"services":{"app":{"network_mode":"host"}}}`,
+		`{"services":{"app":{"pid":"host"}}}`,
+		`{"services":{"app":{"security_opt":["seccomp=unconfined"]}}}`,
+		`{"services":{"app":{"volumes":[{"type":"bind","source":"/","target":"/host"}]}}}`,
+		`{"services":{"app":{"build":{"context":"/"}}}}`,
+		`{"services":{"app":{}},"volumes":{"home":{"driver_opts":{"type":"none","o":"bind","device":"/home"}}}}`,
+		`{"services":{"app":{}},"secrets":{"key":{"file":"/root/.ssh/id_rsa"}}}`,
+	} {
+		if err := validateComposeConfig([]byte(bad), worktree); err == nil {
+			t.Errorf("%s was allowed", bad)
+		}
+	}
+}
@@ -0,0 +1,537 @@
+package target
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// devcontainerPaths are the locations checked for a devcontainer config, in
+// the order the Dev Containers spec looks them up.
+var devcontainerPaths = []string{
+	filepath.Join(".devcontainer", "devcontainer.json"),
+	".devcontainer.json",
+}
+
+// devcontainerImageRepo is the repository for images built from a
+// devcontainer config; the tag is a hash of the config.
+const devcontainerImageRepo = "devx-devcontainer"
+
+// DevcontainerSpec is the subset of devcontainer.json DevX understands.
+type DevcontainerSpec struct {
+	Image string `json:"image"`
+	Build struct {
+		Dockerfile string            `json:"dockerfile"`
+		Context    string            `json:"context"`
+		Args       map[string]string `json:"args"`
+		Target     string            `json:"target"`
+	} `json:"build"`
+	LegacyDockerfile  string                     `json:"dockerFile"`
+	ContainerEnv      map[string]string          `json:"containerEnv"`
+	Mounts            []json.RawMessage          `json:"mounts"`
+	ForwardPorts      []json.RawMessage          `json:"forwardPorts"`
+	PortsAttributes   map[string]json.RawMessage `json:"portsAttributes"`
+	RemoteUser        string                     `json:"remoteUser"`
+	ContainerUser     string                     `json:"containerUser"`
+	PostCreateCommand json.RawMessage            `json:"postCreateCommand"`
+	PostStartCommand  json.RawMessage            `json:"postStartCommand"`
+
+	path string // devcontainer.json on disk
+	raw  []byte // file contents, for the image cache key
+}
+
+// Devcontainer is a devcontainer config resolved for one session: image
+// built, ports mapped onto the session's allocation and variables expanded.
+type Devcontainer struct {
+	Path       string            // devcontainer.json the session was created from
+	Image      string            // image to run
+	Env        map[string]string // containerEnv
+	Mounts     []string          // --mount specs
+	Ports      map[string]int    // service -> port inside the container
+	User       string            // user the container (and every exec) runs as
+	PostCreate [][]string        // postCreateCommand, one argv per command
+	PostStart  [][]string        // postStartCommand, one argv per command
+	Warnings   []string          // settings that were ignored
+}
+
+// LoadDevcontainer reads the worktree's devcontainer.json. It returns nil
+// without error when the worktree has none.
+func LoadDevcontainer(worktreePath string) (*DevcontainerSpec, error) {
+	for _, rel := range devcontainerPaths {
+		path := filepath.Join(worktreePath, rel)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var spec DevcontainerSpec
+		if err := json.Unmarshal(stripJSONC(data), &spec); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", rel, err)
+		}
+		if spec.Build.Dockerfile == "" {
+			spec.Build.Dockerfile = spec.LegacyDockerfile
+		}
+		if spec.Image == "" && spec.Build.Dockerfile == "" {
+			return nil, fmt.Errorf("%s: image or build.dockerfile is required (compose-based devcontainers are not supported)", rel)
+		}
+		spec.path = path
+		spec.raw = data
+		return &spec, nil
+	}
+	return nil, nil
+}
+
+// PrepareDevcontainer loads the worktree's devcontainer.json, builds its
+// image if needed and resolves it against the session's services (in
+// allocation order). It returns nil when the worktree has no devcontainer.
+func PrepareDevcontainer(ctx context.Context, worktreePath string, services []string) (*Devcontainer, error) {
+	spec, err := LoadDevcontainer(worktreePath)
+	if err != nil || spec == nil {
+		return nil, err
+	}
+	image, err := devcontainerImage(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	return resolveDevcontainer(spec, image, worktreePath, services)
+}
+
+// devcontainerImage returns the image named by the spec, or builds the spec's
+// Dockerfile. Built images are tagged with a hash of the devcontainer config
+// and Dockerfile, so an unchanged config reuses the cached image.
+func devcontainerImage(ctx context.Context, spec *DevcontainerSpec) (string, error) {
+	if spec.Build.Dockerfile == "" {
+		return spec.Image, nil
+	}
+	dir := filepath.Dir(spec.path)
+	dockerfile, err := devcontainerRelPath(dir, spec.Build.Dockerfile)
+	if err != nil {
+		return "", err
+	}
+	contextDir := dir
+	if spec.Build.Context != "" {
+		if contextDir, err = devcontainerRelPath(dir, spec.Build.Context); err != nil {
+			return "", err
+		}
+	}
+	dockerfileData, err := os.ReadFile(dockerfile)
+	if err != nil {
+		return "", fmt.Errorf("read devcontainer Dockerfile: %w", err)
+	}
+
+	tag := devcontainerImageRepo + ":" + devcontainerHash(spec.raw, dockerfileData)
+	if ImageExists(tag) {
+		return tag, nil
+	}
+	args := []string{"-f", dockerfile, "--label", ImageLabelKind + "=devcontainer"}
+	keys := make([]string, 0, len(spec.Build.Args))
+	for k := range spec.Build.Args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--build-arg", k+"="+spec.Build.Args[k])
+	}
+	if spec.Build.Target != "" {
+		args = append(args, "--target", spec.Build.Target)
+	}
+	if err := BuildImage(ctx, tag, contextDir, args...); err != nil {
+		return "", fmt.Errorf("build devcontainer image: %w", err)
+	}
+	return tag, nil
+}
+
+// devcontainerHash is the cache key for a built devcontainer image.
+func devcontainerHash(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%d:", len(p))
+		h.Write(p)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// devcontainerRelPath resolves a path relative to the .devcontainer
+// directory, which must stay inside the worktree.
+func devcontainerRelPath(dir, rel string) (string, error) {
+	if filepath.IsAbs(rel) {
+		return "", fmt.Errorf("devcontainer path must be relative: %s", rel)
+	}
+	path := filepath.Join(dir, rel)
+	root := dir
+	if filepath.Base(dir) == ".devcontainer" {
+		root = filepath.Dir(dir)
+	}
+	if r, err := filepath.Rel(root, path); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("devcontainer path must be inside the worktree: %s", rel)
+	}
+	return path, nil
+}
+
+// resolveDevcontainer turns a spec into the settings applied to the session
+// container.
+func resolveDevcontainer(spec *DevcontainerSpec, image, worktreePath string, services []string) (*Devcontainer, error) {
+	dc := &Devcontainer{
+		Path:  spec.path,
+		Image: image,
+		Env:   make(map[string]string),
+		Ports: make(map[string]int),
+		User:  spec.RemoteUser,
+	}
+	if dc.User == "" {
+		dc.User = spec.ContainerUser
+	}
+	for k, v := range spec.ContainerEnv {
+		dc.Env[k] = expandDevcontainerVars(v, worktreePath)
+	}
+
+	for _, raw := range spec.Mounts {
+		mount, err := devcontainerMount(raw, worktreePath)
+		if err != nil {
+			dc.Warnings = append(dc.Warnings, err.Error())
+			continue
+		}
+		dc.Mounts = append(dc.Mounts, mount)
+	}
+
+	ports, warnings := devcontainerPorts(spec, services)
+	dc.Ports = ports
+	dc.Warnings = append(dc.Warnings, warnings...)
+
+	var err error
+	if dc.PostCreate, err = devcontainerCommands(spec.PostCreateCommand); err != nil {
+		return nil, fmt.Errorf("postCreateCommand: %w", err)
+	}
+	if dc.PostStart, err = devcontainerCommands(spec.PostStartCommand); err != nil {
+		return nil, fmt.Errorf("postStartCommand: %w", err)
+	}
+	return dc, nil
+}
+
+// devcontainerPorts maps forwardPorts onto the session's services. A port
+// whose portsAttributes label names a service goes to that service; the rest
+// are paired with the remaining services in allocation order. Ports on other
+// hosts ("db:5432") are skipped.
+func devcontainerPorts(spec *DevcontainerSpec, services []string) (map[string]int, []string) {
+	mapped := make(map[string]int)
+	var warnings, unlabeled []string
+	var unlabeledPorts []int
+	for _, raw := range spec.ForwardPorts {
+		port, ok := devcontainerPortNumber(raw)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("forwardPorts entry %s ignored", string(raw)))
+			continue
+		}
+		label := devcontainerPortLabel(spec.PortsAttributes[strconv.Itoa(port)])
+		if svc := matchService(label, services); svc != "" {
+			if _, taken := mapped[svc]; !taken {
+				mapped[svc] = port
+				continue
+			}
+		}
+		unlabeledPorts = append(unlabeledPorts, port)
+	}
+	for _, svc := range services {
+		if _, taken := mapped[svc]; !taken {
+			unlabeled = append(unlabeled, svc)
+		}
+	}
+	for i, port := range unlabeledPorts {
+		if i >= len(unlabeled) {
+			warnings = append(warnings, fmt.Sprintf("forwardPorts %d has no allocated session port", port))
+			continue
+		}
+		mapped[unlabeled[i]] = port
+	}
+	return mapped, warnings
+}
+
+func devcontainerPortNumber(raw json.RawMessage) (int, bool) {
+	var n int
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n, n > 0 && n < 65536
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return 0, false
+	}
+	host, portStr, found := strings.Cut(s, ":")
+	if !found {
+		portStr = host
+	} else if host != "localhost" && host != "127.0.0.1" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(portStr)
+	return n, err == nil && n > 0 && n < 65536
+}
+
+func devcontainerPortLabel(raw json.RawMessage) string {
+	var attrs struct {
+		Label string `json:"label"`
+	}
+	if len(raw) == 0 || json.Unmarshal(raw, &attrs) != nil {
+		return ""
+	}
+	return attrs.Label
+}
+
+func matchService(label string, services []string) string {
+	if label == "" {
+		return ""
+	}
+	for _, svc := range services {
+		if strings.EqualFold(svc, label) {
+			return svc
+		}
+	}
+	return ""
+}
+
+// devcontainerMountKeys are the --mount keys a mounts entry may set. Others,
+// such as volume-opt, can bind arbitrary host paths and are rejected.
+var devcontainerMountKeys = map[string]bool{"type": true, "source": true, "target": true, "readonly": true, "consistency": true}
+
+// devcontainerMount converts a mounts entry (string or object form) to a
+// --mount spec. Bind mounts must stay inside the worktree so a project cannot
+// expose host credentials to its container.
+func devcontainerMount(raw json.RawMessage, worktreePath string) (string, error) {
+	fields := make(map[string]string)
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		for _, part := range strings.Split(s, ",") {
+			k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+			fields[strings.ToLower(k)] = v
+		}
+	} else {
+		var obj map[string]interface{}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return "", fmt.Errorf("mounts entry %s ignored: %v", string(raw), err)
+		}
+		for k, v := range obj {
+			fields[strings.ToLower(k)] = fmt.Sprint(v)
+		}
+	}
+	for _, alias := range [][2]string{{"src", "source"}, {"dst", "target"}, {"destination", "target"}, {"ro", "readonly"}} {
+		if v, ok := fields[alias[0]]; ok {
+			fields[alias[1]] = v
+			delete(fields, alias[0])
+		}
+	}
+	for k, v := range fields {
+		if !devcontainerMountKeys[k] {
+			return "", fmt.Errorf("mounts entry %s ignored: unsupported key %q", string(raw), k)
+		}
+		// A value is joined into a comma-separated spec, where "," or "="
+		// would smuggle in more keys.
+		v = expandDevcontainerVars(v, worktreePath)
+		if strings.ContainsAny(v, ",=") {
+			return "", fmt.Errorf("mounts entry %s ignored: %s contains \",\" or \"=\"", string(raw), k)
+		}
+		fields[k] = v
+	}
+	if fields["target"] == "" {
+		return "", fmt.Errorf("mounts entry %s ignored: no target", string(raw))
+	}
+	if fields["type"] == "" {
+		fields["type"] = "volume"
+	}
+	switch fields["type"] {
+	case "volume", "tmpfs":
+	case "bind":
+		// Resolve symlinks first: Docker follows them when mounting, so a
+		// committed link to ~/.ssh must not pass as a worktree path.
+		src, err := resolveBindSource(fields["source"], worktreePath)
+		if err != nil {
+			return "", fmt.Errorf("bind mount of %s ignored: %v", fields["source"], err)
+		}
+		fields["source"] = src
+	default:
+		return "", fmt.Errorf("mounts entry %s ignored: unsupported type %q", string(raw), fields["type"])
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if fields[k] == "" {
+			parts = append(parts, k)
+			continue
+		}
+		parts = append(parts, k+"="+fields[k])
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// resolveBindSource resolves a bind mount source and the worktree through any
+// symlinks and returns the real source if it lies inside the worktree.
+func resolveBindSource(source, worktreePath string) (string, error) {
+	if !filepath.IsAbs(source) {
+		return "", fmt.Errorf("only absolute paths inside the worktree can be mounted")
+	}
+	src, err := filepath.EvalSymlinks(filepath.Clean(source))
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve source: %v", err)
+	}
+	root, err := filepath.EvalSymlinks(worktreePath)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve worktree: %v", err)
+	}
+	if r, err := filepath.Rel(root, src); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("only paths inside the worktree can be mounted")
+	}
+	return src, nil
+}
+
+// devcontainerCommands parses a lifecycle command: a shell string, an argv
+// array, or an object of named commands (run in name order).
+func devcontainerCommands(raw json.RawMessage) ([][]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if s == "" {
+			return nil, nil
+		}
+		return [][]string{{"/bin/sh", "-c", s}}, nil
+	}
+	var argv []string
+	if err := json.Unmarshal(raw, &argv); err == nil {
+		if len(argv) == 0 {
+			return nil, nil
+		}
+		return [][]string{argv}, nil
+	}
+	var named map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &named); err != nil {
+		return nil, fmt.Errorf("expected a string, array or object")
+	}
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var cmds [][]string
+	for _, name := range names {
+		sub, err := devcontainerCommands(named[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		cmds = append(cmds, sub...)
+	}
+	return cmds, nil
+}
+
+var devcontainerVarPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// expandDevcontainerVars substitutes the workspace variables of the
+// Dev Containers spec. ${localEnv:VAR:default} expands to its default only:
+// host environment variables are never copied into the container.
+func expandDevcontainerVars(s, worktreePath string) string {
+	return devcontainerVarPattern.ReplaceAllStringFunc(s, func(m string) string {
+		name := m[2 : len(m)-1]
+		switch name {
+		case "containerWorkspaceFolder":
+			return "/workspace"
+		case "containerWorkspaceFolderBasename":
+			return "workspace"
+		case "localWorkspaceFolder":
+			return worktreePath
+		case "localWorkspaceFolderBasename":
+			return filepath.Base(worktreePath)
+		case "devcontainerId":
+			return devcontainerHash([]byte(worktreePath))
+		}
+		if rest, ok := strings.CutPrefix(name, "localEnv:"); ok {
+			_, def, _ := strings.Cut(rest, ":")
+			return def
+		}
+		if rest, ok := strings.CutPrefix(name, "containerEnv:"); ok {
+			_, def, _ := strings.Cut(rest, ":")
+			return def
+		}
+		return m
+	})
+}
+
+// runDevcontainerCommands runs lifecycle commands in the container's
+// workspace, streaming their output.
+func runDevcontainerCommands(ctx context.Context, containerName string, cmds [][]string) error {
+	for _, argv := range cmds {
+		args := append([]string{"exec", "-w", "/workspace", containerName}, argv...)
+		cmd := exec.CommandContext(ctx, "docker", args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(argv, " "), err)
+		}
+	}
+	return nil
+}
+
+// stripJSONC removes // and /* */ comments and trailing commas, which
+// devcontainer.json allows, leaving plain JSON.
+func stripJSONC(data []byte) []byte {
+	var out bytes.Buffer
+	inString, escaped := false, false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			out.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inString = true
+			out.WriteByte(c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out.WriteByte('\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+		case c == ',':
+			j := i + 1
+			for j < len(data) && (data[j] == ' ' || data[j] == '\t' || data[j] == '\n' || data[j] == '\r') {
+				j++
+			}
+			if j < len(data) && (data[j] == '}' || data[j] == ']') {
+				continue
+			}
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.Bytes()
+}
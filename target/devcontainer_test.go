@@ -0,0 +1,227 @@
+package target
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeDevcontainer(t *testing.T, worktree, content string) {
+	t.Helper()
+	dir := filepath.Join(worktree, ".devcontainer")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "devcontainer.json"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDevcontainerParsesJSONC(t *testing.T) {
+	worktree := t.TempDir()
+	if spec, err := LoadDevcontainer(worktree); err != nil || spec != nil {
+		t.Fatalf("no devcontainer: got %v, %v", spec, err)
+	}
+	writeDevcontainer(t, worktree, `{
+	// The base image
+	"image": "mcr.microsoft.com/devcontainers/go:1", /* trailing comment */
+	"containerEnv": {"URL": "http://example.com/a//b",},
+	"forwardPorts": [3000, 5432,],
+}`)
+	spec, err := LoadDevcontainer(worktree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Image != "mcr.microsoft.com/devcontainers/go:1" {
+		t.Errorf("image = %q", spec.Image)
+	}
+	if spec.ContainerEnv["URL"] != "http://example.com/a//b" {
+		t.Errorf("// inside strings must be kept, got %q", spec.ContainerEnv["URL"])
+	}
+	if len(spec.ForwardPorts) != 2 {
+		t.Errorf("forwardPorts = %s", spec.ForwardPorts)
+	}
+}
+
+func TestLoadDevcontainerRequiresImageOrBuild(t *testing.T) {
+	worktree := t.TempDir()
+	writeDevcontainer(t, worktree, `{"dockerComposeFile": "compose.yaml"}`)
+	if _, err := LoadDevcontainer(worktree); err == nil {
+		t.Fatal("expected an error for a compose-based devcontainer")
+	}
+}
+
+func TestResolveDevcontainer(t *testing.T) {
+	worktree := t.TempDir()
+	writeDevcontainer(t, worktree, `{
+	"image": "node:20",
+	"remoteUser": "node",
+	"containerEnv": {"WS": "${containerWorkspaceFolder}/app", "TOKEN": "${localEnv:HOME:none}"},
+	"forwardPorts": [5173, 8080, "db:5432", 9999],
+	"portsAttributes": {"8080": {"label": "api"}},
+	"mounts": [
+		"source=node_modules,target=/workspace/node_modules,type=volume",
+		{"source": "${localWorkspaceFolder}/.cache", "target": "/cache", "type": "bind"},
+		"source=${localEnv:HOME}/.ssh,target=/home/node/.ssh,type=bind"
+	],
+	"postCreateCommand": "npm ci",
+	"postStartCommand": {"b": ["echo", "b"], "a": "echo a"}
+}`)
+	if err := os.Mkdir(filepath.Join(worktree, ".cache"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	realWorktree, err := filepath.EvalSymlinks(worktree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := LoadDevcontainer(worktree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc, err := resolveDevcontainer(spec, "node:20", worktree, []string{"web", "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc.User != "node" {
+		t.Errorf("user = %q", dc.User)
+	}
+	if dc.Env["WS"] != "/workspace/app" || dc.Env["TOKEN"] != "none" {
+		t.Errorf("env = %v", dc.Env)
+	}
+	if want := map[string]int{"api": 8080, "web": 5173}; !reflect.DeepEqual(dc.Ports, want) {
+		t.Errorf("ports = %v, want %v", dc.Ports, want)
+	}
+	wantMounts := []string{
+		"source=node_modules,target=/workspace/node_modules,type=volume",
+		"source=" + filepath.Join(realWorktree, ".cache") + ",target=/cache,type=bind",
+	}
+	if !reflect.DeepEqual(dc.Mounts, wantMounts) {
+		t.Errorf("mounts = %v, want %v", dc.Mounts, wantMounts)
+	}
+	warnings := strings.Join(dc.Warnings, "\n")
+	for _, want := range []string{"db:5432", "9999", ".ssh"} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("warnings should mention %s: %v", want, dc.Warnings)
+		}
+	}
+	if want := [][]string{{"/bin/sh", "-c", "npm ci"}}; !reflect.DeepEqual(dc.PostCreate, want) {
+		t.Errorf("postCreate = %v", dc.PostCreate)
+	}
+	if want := [][]string{{"/bin/sh", "-c", "echo a"}, {"echo", "b"}}; !reflect.DeepEqual(dc.PostStart, want) {
+		t.Errorf("postStart = %v", dc.PostStart)
+	}
+}
+
+func TestDevcontainerImageRejectsPathsOutsideWorktree(t *testing.T) {
+	worktree := t.TempDir()
+	writeDevcontainer(t, worktree, `{"build": {"dockerfile": "../../Dockerfile"}}`)
+	spec, err := LoadDevcontainer(worktree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := devcontainerImage(context.Background(), spec); err == nil || !strings.Contains(err.Error(), "inside the worktree") {
+		t.Fatalf("expected the Dockerfile path to be rejected, got %v", err)
+	}
+}
+
+func TestDevcontainerHashChangesWithConfig(t *testing.T) {
+	a := devcontainerHash([]byte(`{"build":{"dockerfile":"Dockerfile"}}`), []byte("FROM alpine"))
+	if a != devcontainerHash([]byte(`{"build":{"dockerfile":"Dockerfile"}}`), []byte("FROM alpine")) {
+		t.Fatal("hash should be stable")
+	}
+	if a == devcontainerHash([]byte(`{"build":{"dockerfile":"Dockerfile"}}`), []byte("FROM debian")) {
+		t.Fatal("hash should change with the Dockerfile")
+	}
+}
+
+func TestContainerRunArgsDevcontainer(t *testing.T) {
+	args, image := containerRunArgs("devx-feat", "devx-feat-net", StartOpts{
+		WorktreePath: "/wt",
+		HostPorts:    map[string]int{"web": 41000},
+		Image:        "devx-session-base:latest",
+		Env:          map[string]string{"WEB_PORT": "5173"},
+		Devcontainer: &Devcontainer{
+			Image:  "devx-devcontainer:abc",
+			Env:    map[string]string{"WEB_PORT": "1", "NODE_ENV": "development"},
+			Mounts: []string{"source=cache,target=/cache,type=volume"},
+			Ports:  map[string]int{"web": 5173},
+			User:   "node",
+		},
+	})
+	if image != "devx-devcontainer:abc" {
+		t.Errorf("image = %q", image)
+	}
+	joined := strings.Join(args, " ")
+	for _, want := range []string{
+		"-p 127.0.0.1:41000:5173",
+		"--mount source=cache,target=/cache,type=volume",
+		"--user node",
+		"-e NODE_ENV=development",
+		"-e WEB_PORT=5173",
+		"devx-devcontainer:abc sleep infinity",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("run args missing %q: %s", want, joined)
+		}
+	}
+	if strings.Contains(joined, "WEB_PORT=1") {
+		t.Errorf("session env should override containerEnv: %s", joined)
+	}
+}
+
+func TestDevcontainerMountRejectsEscapingSymlink(t *testing.T) {
+	worktree := t.TempDir()
+	secrets := t.TempDir()
+	if err := os.Symlink(secrets, filepath.Join(worktree, "keys")); err != nil {
+		t.Fatal(err)
+	}
+	raw := json.RawMessage(`"source=${localWorkspaceFolder}/keys,target=/keys,type=bind"`)
+	if mount, err := devcontainerMount(raw, worktree); err == nil {
+		t.Fatalf("symlink out of the worktree was mounted: %s", mount)
+	}
+	raw = json.RawMessage(`"source=${localWorkspaceFolder}/missing,target=/m,type=bind"`)
+	if mount, err := devcontainerMount(raw, worktree); err == nil {
+		t.Fatalf("unresolvable source was mounted: %s", mount)
+	}
+
+	// A link that stays inside the worktree mounts its real target.
+	if err := os.Mkdir(filepath.Join(worktree, "data"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("data", filepath.Join(worktree, "alias")); err != nil {
+		t.Fatal(err)
+	}
+	raw = json.RawMessage(`"source=${localWorkspaceFolder}/alias,target=/data,type=bind"`)
+	mount, err := devcontainerMount(raw, worktree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	real, _ := filepath.EvalSymlinks(filepath.Join(worktree, "data"))
+	if !strings.Contains(mount, "source="+real+",") {
+		t.Errorf("mount = %s, want source %s", mount, real)
+	}
+}
+
+func TestDevcontainerMountRejectsInjectedKeys(t *testing.T) {
+	worktree := t.TempDir()
+	for _, raw := range []string{
+		`{"type":"bind","source":"${localWorkspaceFolder}","target":"/x,source=/"}`,
+		`{"type":"volume","target":"/x,volume-opt=type=none,volume-opt=o=bind,volume-opt=device=/home"}`,
+		`{"type":"volume","source":"cache","target":"/x","volume-opt":"device=/home"}`,
+		`"type=volume,target=/x,volume-opt=device=/home"`,
+		`"type=bind,source=${localWorkspaceFolder},target=/x,bind-propagation=shared"`,
+	} {
+		if mount, err := devcontainerMount(json.RawMessage(raw), worktree); err == nil {
+			t.Errorf("%s was mounted as %s", raw, mount)
+		}
+	}
+
+	mount, err := devcontainerMount(json.RawMessage(`{"source":"cache","target":"/cache","readonly":true}`), worktree)
+	if err != nil || mount != "readonly=true,source=cache,target=/cache,type=volume" {
+		t.Errorf("mount = %s, %v", mount, err)
+	}
+}
@@ -20,54 +20,141 @@ func (d *DockerTarget) Start(ctx context.Context, opts StartOpts) (*StartResult,
 	name := ContainerName(opts.SessionName)
 	netName := NetworkName(opts.SessionName)
 
-	// Create per-session bridge network
-	if err := dockerRun(ctx, "network", "create", netName); err != nil {
-		return nil, fmt.Errorf("create network: %w", err)
-	}
+	var meta session.TargetMeta
+	switch opts.Network.Mode {
+	case "", NetworkFull:
+		// Create per-session bridge network
+		if err := dockerRun(ctx, "network", "create", netName); err != nil {
+			return nil, fmt.Errorf("create network: %w", err)
+		}
 
-	// Build docker run arguments
-	args := []string{
-		"run", "-d",
-		"--name", name,
-		"--network", netName,
-		"--restart", "unless-stopped",
-		"-v", opts.WorktreePath + ":/workspace",
-		"-w", "/workspace",
+		if err := ensureCacheVolumes(ctx, "docker", opts.Caches); err != nil {
+			_ = dockerRunIgnore(ctx, "network", "rm", netName)
+			return nil, err
+		}
+		args, image := containerRunArgs(name, netName, opts)
+		if err := dockerRun(ctx, args...); err != nil {
+			// Clean up network on failure
+			_ = dockerRunIgnore(ctx, "network", "rm", netName)
+			return nil, fmt.Errorf("create container: %w", err)
+		}
+		meta = session.TargetMeta{Type: "docker", ContainerName: name, NetworkName: netName, Image: image}
+	case NetworkNone:
+		if err := ensureCacheVolumes(ctx, "docker", opts.Caches); err != nil {
+			return nil, err
+		}
+		// Ports cannot be published without a network.
+		noNet := opts
+		noNet.HostPorts = nil
+		args, image := containerRunArgs(name, "none", noNet)
+		if err := dockerRun(ctx, args...); err != nil {
+			return nil, fmt.Errorf("create container: %w", err)
+		}
+		meta = session.TargetMeta{Type: "docker", ContainerName: name, Image: image, Network: session.NetworkMeta{Mode: NetworkNone}}
+	case NetworkAllowlist:
+		var err error
+		if meta, err = startAllowlistNetwork(ctx, name, netName, opts); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown network mode %q (valid: %s)", opts.Network.Mode, strings.Join(NetworkModes, ", "))
 	}
 
-	// Publish ports on loopback
-	for _, port := range opts.HostPorts {
-		args = append(args, "-p", fmt.Sprintf("127.0.0.1:%d:%d", port, port))
+	// Get container ID
+	containerID, err := dockerOutput(ctx, "inspect", "--format", "{{.Id}}", name)
+	if err != nil {
+		return nil, fmt.Errorf("inspect container: %w", err)
 	}
+	meta.ContainerID = containerID
 
-	// Security
-	sec := opts.Security
-	if sec.MemoryLimit == "" {
-		sec = DefaultSecurityOpts()
-	}
-	for _, cap := range sec.CapDrop {
-		args = append(args, "--cap-drop="+cap)
+	if dc := opts.Devcontainer; dc != nil {
+		meta.Devcontainer = session.DevcontainerMeta{Config: dc.Path, User: dc.User}
+		if err := runDevcontainerCommands(ctx, name, append(dc.PostCreate, dc.PostStart...)); err != nil {
+			_ = d.Stop(ctx, meta)
+			return nil, fmt.Errorf("devcontainer lifecycle command: %w", err)
+		}
 	}
-	if sec.NoNewPrivs {
-		args = append(args, "--security-opt", "no-new-privileges")
+
+	return &StartResult{Meta: meta}, nil
+}
+
+func (d *DockerTarget) Stop(ctx context.Context, meta session.TargetMeta) error {
+	// The egress proxy is attached to the session network, so it has to go
+	// before stopContainer removes that network.
+	errs := stopEgressProxy(ctx, meta)
+	errs = append(errs, stopContainer(ctx, "docker", meta)...)
+	errs = append(errs, removeEgressNetwork(ctx, meta)...)
+	if len(errs) > 0 {
+		return fmt.Errorf("docker teardown: %s", strings.Join(errs, "; "))
 	}
-	if sec.MemoryLimit != "" {
-		args = append(args, "--memory", sec.MemoryLimit)
+	return nil
+}
+
+// stopContainer stops and force-removes a session's container and removes
+// its network with the given container CLI (docker or podman). "No such"
+// errors are ignored; other failures are returned for the caller to report.
+func stopContainer(ctx context.Context, bin string, meta session.TargetMeta) []string {
+	var errs []string
+	if meta.ContainerName != "" {
+		_ = cliRunIgnore(ctx, bin, "stop", meta.ContainerName)
+		if err := cliRun(ctx, bin, "rm", "-f", meta.ContainerName); err != nil && !isDockerNotFound(err) {
+			errs = append(errs, fmt.Sprintf("rm container: %v", err))
+		}
 	}
-	if sec.CPULimit != "" {
-		args = append(args, "--cpus", sec.CPULimit)
+	if meta.NetworkName != "" {
+		if err := cliRun(ctx, bin, "network", "rm", meta.NetworkName); err != nil && !isDockerNotFound(err) {
+			errs = append(errs, fmt.Sprintf("rm network: %v", err))
+		}
 	}
-	if sec.PidsLimit > 0 {
-		args = append(args, "--pids-limit", fmt.Sprintf("%d", sec.PidsLimit))
+	return errs
+}
+
+// containerRunArgs builds the `run` arguments shared by the Docker and Podman
+// targets: bind-mounted worktree, loopback-only port publishing, security
+// limits, env and labels, with the image kept alive by `sleep infinity`.
+// runtimeFlags are runtime-specific flags placed right after `run -d`.
+func containerRunArgs(name, netName string, opts StartOpts, runtimeFlags ...string) ([]string, string) {
+	args := append([]string{"run", "-d"}, runtimeFlags...)
+	args = append(args,
+		"--name", name,
+		"--network", netName,
+		"--restart", "unless-stopped",
+		"-v", opts.WorktreePath+":/workspace",
+		"-w", "/workspace",
+	)
+
+	// Publish ports on loopback. A devcontainer may listen on fixed ports
+	// (forwardPorts), which are published on the session's allocated ports.
+	dc := opts.Devcontainer
+	for svc, port := range opts.HostPorts {
+		containerPort := port
+		if dc != nil && dc.Ports[svc] != 0 {
+			containerPort = dc.Ports[svc]
+		}
+		args = append(args, "-p", fmt.Sprintf("127.0.0.1:%d:%d", port, containerPort))
 	}
-	if sec.ReadOnlyRoot {
-		args = append(args, "--read-only")
-		for _, m := range sec.TmpfsMounts {
-			args = append(args, "--tmpfs", m)
+	if dc != nil {
+		for _, m := range dc.Mounts {
+			args = append(args, "--mount", m)
+		}
+		if dc.User != "" {
+			args = append(args, "--user", dc.User)
 		}
 	}
 
-	// Environment
+	args = append(args, cacheMountArgs(opts.Caches)...)
+
+	// Security
+	args = append(args, securityArgs(opts.Security)...)
+
+	// Environment; session variables take precedence over containerEnv.
+	if dc != nil {
+		for k, v := range dc.Env {
+			if _, ok := opts.Env[k]; !ok {
+				args = append(args, "-e", k+"="+v)
+			}
+		}
+	}
 	for k, v := range opts.Env {
 		args = append(args, "-e", k+"="+v)
 	}
@@ -79,56 +166,15 @@ func (d *DockerTarget) Start(ctx context.Context, opts StartOpts) (*StartResult,
 
 	// Image + command
 	image := opts.Image
+	if dc != nil {
+		image = dc.Image
+	}
 	if image == "" {
 		image = "devx-session-base:latest"
 	}
 	args = append(args, image, "sleep", "infinity")
 
-	if err := dockerRun(ctx, args...); err != nil {
-		// Clean up network on failure
-		_ = dockerRunIgnore(ctx, "network", "rm", netName)
-		return nil, fmt.Errorf("create container: %w", err)
-	}
-
-	// Get container ID
-	containerID, err := dockerOutput(ctx, "inspect", "--format", "{{.Id}}", name)
-	if err != nil {
-		return nil, fmt.Errorf("inspect container: %w", err)
-	}
-
-	return &StartResult{
-		Meta: session.TargetMeta{
-			Type:          "docker",
-			ContainerID:   containerID,
-			ContainerName: name,
-			NetworkName:   netName,
-			Image:         image,
-		},
-	}, nil
-}
-
-func (d *DockerTarget) Stop(ctx context.Context, meta session.TargetMeta) error {
-	var errs []string
-	if meta.ContainerName != "" {
-		// Stop then force-remove; ignore "no such container" but report other errors.
-		_ = dockerRunIgnore(ctx, "stop", meta.ContainerName)
-		if err := dockerRun(ctx, "rm", "-f", meta.ContainerName); err != nil {
-			if !isDockerNotFound(err) {
-				errs = append(errs, fmt.Sprintf("rm container: %v", err))
-			}
-		}
-	}
-	if meta.NetworkName != "" {
-		if err := dockerRun(ctx, "network", "rm", meta.NetworkName); err != nil {
-			if !isDockerNotFound(err) {
-				errs = append(errs, fmt.Sprintf("rm network: %v", err))
-			}
-		}
-	}
-	if len(errs) > 0 {
-		return fmt.Errorf("docker teardown: %s", strings.Join(errs, "; "))
-	}
-	return nil
+	return args, image
 }
 
 // isDockerNotFound checks if a docker error is a "not found" error.
@@ -182,13 +228,30 @@ func ImageExists(image string) bool {
 }
 
 // BuildImage builds a Docker image from the given context directory.
-func BuildImage(ctx context.Context, tag, contextDir string) error {
-	return dockerRun(ctx, "build", "-t", tag, contextDir)
+// extraArgs (e.g. -f, --build-arg) are passed to docker build before the context.
+func BuildImage(ctx context.Context, tag, contextDir string, extraArgs ...string) error {
+	args := append([]string{"build", "-t", tag}, extraArgs...)
+	return dockerRun(ctx, append(args, contextDir)...)
 }
 
 // dockerRun executes a docker command and returns any error.
 func dockerRun(ctx context.Context, args ...string) error {
-	cmd := exec.CommandContext(ctx, "docker", args...)
+	return cliRun(ctx, "docker", args...)
+}
+
+// dockerRunIgnore executes a docker command and silently ignores errors.
+func dockerRunIgnore(ctx context.Context, args ...string) error {
+	return cliRunIgnore(ctx, "docker", args...)
+}
+
+// dockerOutput executes a docker command and returns trimmed stdout.
+func dockerOutput(ctx context.Context, args ...string) (string, error) {
+	return cliOutput(ctx, "docker", args...)
+}
+
+// cliRun executes a container CLI (docker or podman) command and returns any error.
+func cliRun(ctx context.Context, bin string, args ...string) error {
+	cmd := exec.CommandContext(ctx, bin, args...)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
@@ -201,17 +264,17 @@ func dockerRun(ctx context.Context, args ...string) error {
 	return nil
 }
 
-// dockerRunIgnore executes a docker command and silently ignores errors.
-func dockerRunIgnore(ctx context.Context, args ...string) error {
-	cmd := exec.CommandContext(ctx, "docker", args...)
+// cliRunIgnore executes a container CLI command and silently ignores errors.
+func cliRunIgnore(ctx context.Context, bin string, args ...string) error {
+	cmd := exec.CommandContext(ctx, bin, args...)
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 	return cmd.Run()
 }
 
-// dockerOutput executes a docker command and returns trimmed stdout.
-func dockerOutput(ctx context.Context, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, "docker", args...)
+// cliOutput executes a container CLI command and returns trimmed stdout.
+func cliOutput(ctx context.Context, bin string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, bin, args...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
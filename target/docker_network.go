@@ -0,0 +1,291 @@
+package target
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"debug/elf"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jfox85/devx/caddy"
+	"github.com/jfox85/devx/session"
+)
+
+// Network modes of a docker session.
+const (
+	NetworkFull      = "full"      // a per-session bridge with direct internet access
+	NetworkNone      = "none"      // no network at all; ports are not published
+	NetworkAllowlist = "allowlist" // egress only through a filtering proxy
+)
+
+// NetworkModes are the accepted docker network modes.
+var NetworkModes = []string{NetworkFull, NetworkNone, NetworkAllowlist}
+
+// NetworkRuntimeConfig fences a docker session's egress.
+type NetworkRuntimeConfig struct {
+	Mode        string // full (default), none or allowlist
+	Policy      []byte // effective Gatepost-format policy YAML for allowlist; the default policy when empty
+	ProxyBinary string // Linux devx binary the allowlist proxy container runs
+}
+
+const (
+	egressProxyAlias     = "egress-proxy"
+	egressProxyPort      = 3128
+	egressProxyDir       = "/etc/devx-egress"
+	egressProxyBin       = "/usr/local/bin/devx"
+	egressProxyImageRepo = "devx-egress-proxy" // tagged with a hash of the devx binary
+)
+
+// NetworkStrictness orders network modes from most to least open, so callers
+// can keep the stricter of two settings. Unknown modes rank as full.
+func NetworkStrictness(mode string) int {
+	switch mode {
+	case NetworkNone:
+		return 2
+	case NetworkAllowlist:
+		return 1
+	}
+	return 0
+}
+
+// egressDir holds the allowlist proxy's policy and audit log on the host.
+func egressDir(sessionName string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "devx", "egress", caddy.SanitizeHostname(sessionName)), nil
+}
+
+// EgressAuditLog returns the allowlist proxy's audit log of a docker
+// session, or "" when the session is not fenced by the proxy.
+func EgressAuditLog(meta session.TargetMeta) string {
+	if meta.Network.Mode != NetworkAllowlist || meta.Network.Dir == "" {
+		return ""
+	}
+	return filepath.Join(meta.Network.Dir, "audit.jsonl")
+}
+
+// startAllowlistNetwork starts a docker session behind the filtering proxy.
+// The session container sits alone on an internal network; the proxy
+// container joins that network and a normal bridge, enforces the policy on
+// every request and publishes the session's ports by relaying them.
+func startAllowlistNetwork(ctx context.Context, name, netName string, opts StartOpts) (session.TargetMeta, error) {
+	cfg := opts.Network
+	if cfg.ProxyBinary == "" {
+		return session.TargetMeta{}, fmt.Errorf("network: allowlist needs a Linux devx binary for the proxy; set docker.egress_proxy_binary")
+	}
+	if _, err := os.Stat(cfg.ProxyBinary); err != nil {
+		return session.TargetMeta{}, fmt.Errorf("egress proxy binary: %w", err)
+	}
+	proxyImage, err := ensureEgressProxyImage(ctx, cfg.ProxyBinary)
+	if err != nil {
+		return session.TargetMeta{}, err
+	}
+	policy := cfg.Policy
+	if len(policy) == 0 {
+		policy = []byte(defaultGatepostPolicy)
+	}
+	dir, err := egressDir(opts.SessionName)
+	if err != nil {
+		return session.TargetMeta{}, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return session.TargetMeta{}, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "policy.yaml"), policy, 0o600); err != nil {
+		return session.TargetMeta{}, fmt.Errorf("write egress policy: %w", err)
+	}
+
+	proxyName := name + "-egress-proxy"
+	egressNet := netName + "-egress"
+	meta := session.TargetMeta{
+		Type:          "docker",
+		ContainerName: name,
+		NetworkName:   netName,
+		Network: session.NetworkMeta{
+			Mode:               NetworkAllowlist,
+			ProxyContainerName: proxyName,
+			EgressNetworkName:  egressNet,
+			Dir:                dir,
+		},
+	}
+	fail := func(err error) (session.TargetMeta, error) {
+		_ = (&DockerTarget{}).Stop(ctx, meta)
+		return session.TargetMeta{}, err
+	}
+
+	if err := dockerRun(ctx, "network", "create", "--internal", netName); err != nil {
+		return session.TargetMeta{}, fmt.Errorf("create network: %w", err)
+	}
+	if err := dockerRun(ctx, "network", "create", egressNet); err != nil {
+		return fail(fmt.Errorf("create egress network: %w", err))
+	}
+	if err := ensureCacheVolumes(ctx, "docker", opts.Caches); err != nil {
+		return fail(err)
+	}
+
+	// The session publishes nothing itself; the proxy relays its ports.
+	agentOpts := opts
+	agentOpts.HostPorts = nil
+	agentOpts.Env = map[string]string{}
+	for k, v := range opts.Env {
+		agentOpts.Env[k] = v
+	}
+	proxyURL := fmt.Sprintf("http://%s:%d", egressProxyAlias, egressProxyPort)
+	for _, k := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		agentOpts.Env[k] = proxyURL
+	}
+	agentOpts.Env["NO_PROXY"] = "localhost,127.0.0.1"
+	agentOpts.Env["no_proxy"] = "localhost,127.0.0.1"
+	args, image := containerRunArgs(name, netName, agentOpts)
+	if err := dockerRun(ctx, args...); err != nil {
+		return fail(fmt.Errorf("create container: %w", err))
+	}
+	meta.Image = image
+
+	if err := dockerRun(ctx, egressProxyRunArgs(proxyName, egressNet, proxyImage, dir, name, opts)...); err != nil {
+		return fail(fmt.Errorf("create egress proxy: %w", err))
+	}
+	if err := dockerRun(ctx, "network", "connect", "--alias", egressProxyAlias, netName, proxyName); err != nil {
+		return fail(fmt.Errorf("connect egress proxy: %w", err))
+	}
+	return meta, nil
+}
+
+// ensureEgressProxyImage returns the image the allowlist proxy runs: the devx
+// binary alone on an empty filesystem, imported on first use. The proxy is the
+// only container with internet access, so nothing of the project-controlled
+// session image, such as its libc or an LD_PRELOAD in its ENV, may reach it.
+func ensureEgressProxyImage(ctx context.Context, binary string) (string, error) {
+	if err := checkStaticBinary(binary); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(binary)
+	if err != nil {
+		return "", fmt.Errorf("egress proxy binary: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	tag := egressProxyImageRepo + ":" + hex.EncodeToString(sum[:8])
+	if ImageExists(tag) {
+		return tag, nil
+	}
+	rootfs, err := egressProxyRootfs(data)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, "docker", "import", "-", tag)
+	cmd.Stdin = bytes.NewReader(rootfs)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("import egress proxy image: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return tag, nil
+}
+
+// egressProxyRootfs is a tar of a filesystem holding only the devx binary.
+func egressProxyRootfs(binary []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, dir := range []string{"usr/", "usr/local/", "usr/local/bin/"} {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: dir, Mode: 0o755}); err != nil {
+			return nil, err
+		}
+	}
+	hdr := &tar.Header{Typeflag: tar.TypeReg, Name: strings.TrimPrefix(egressProxyBin, "/"), Mode: 0o755, Size: int64(len(binary))}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(binary); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// checkStaticBinary requires a statically linked Linux executable, the only
+// kind that runs on the proxy image's empty filesystem.
+func checkStaticBinary(path string) error {
+	f, err := elf.Open(path)
+	if err != nil {
+		return fmt.Errorf("egress proxy binary %s is not a Linux executable: %w", path, err)
+	}
+	defer f.Close()
+	for _, p := range f.Progs {
+		if p.Type == elf.PT_INTERP {
+			return fmt.Errorf("egress proxy binary %s is dynamically linked; build devx with CGO_ENABLED=0 or set docker.egress_proxy_binary to a release build", path)
+		}
+	}
+	return nil
+}
+
+// egressProxyRunArgs builds the `run` arguments of the allowlist proxy, which
+// runs the devx binary from its own image with everything locked down.
+func egressProxyRunArgs(proxyName, egressNet, image, dir, agentName string, opts StartOpts) []string {
+	args := []string{"run", "-d",
+		"--name", proxyName,
+		"--network", egressNet,
+		"--restart", "unless-stopped",
+		"--read-only",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"-v", dir + ":" + egressProxyDir,
+		"--entrypoint", egressProxyBin,
+	}
+	// Run as the host user so the proxy can append to the audit log in the
+	// host-owned state directory without any capabilities.
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, gid))
+	}
+	var forwards []string
+	dc := opts.Devcontainer
+	for _, svc := range sortedKeys(opts.HostPorts) {
+		port := opts.HostPorts[svc]
+		containerPort := port
+		if dc != nil && dc.Ports[svc] != 0 {
+			containerPort = dc.Ports[svc]
+		}
+		args = append(args, "-p", fmt.Sprintf("127.0.0.1:%d:%d", port, port))
+		forwards = append(forwards, "--forward", fmt.Sprintf("%d=%s:%d", port, agentName, containerPort))
+	}
+	for k, v := range opts.Labels {
+		args = append(args, "--label", k+"="+v)
+	}
+	args = append(args, "--label", "devx.role=egress-proxy", image,
+		"egress-proxy",
+		"--listen", fmt.Sprintf(":%d", egressProxyPort),
+		"--policy", egressProxyDir+"/policy.yaml",
+		"--audit", egressProxyDir+"/audit.jsonl",
+	)
+	return append(args, forwards...)
+}
+
+// stopEgressProxy removes the allowlist proxy container.
+func stopEgressProxy(ctx context.Context, meta session.TargetMeta) []string {
+	var errs []string
+	if name := meta.Network.ProxyContainerName; name != "" {
+		if err := dockerRun(ctx, "rm", "-f", name); err != nil && !isDockerNotFound(err) {
+			errs = append(errs, fmt.Sprintf("rm egress proxy: %v", err))
+		}
+	}
+	return errs
+}
+
+// removeEgressNetwork removes the proxy's outbound network; it must run after
+// the proxy container is gone.
+func removeEgressNetwork(ctx context.Context, meta session.TargetMeta) []string {
+	if meta.Network.EgressNetworkName == "" {
+		return nil
+	}
+	if err := dockerRun(ctx, "network", "rm", meta.Network.EgressNetworkName); err != nil && !isDockerNotFound(err) {
+		return []string{fmt.Sprintf("rm egress network: %v", err)}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package target
+
+import (
+	"archive/tar"
+	"bytes"
+	"debug/elf"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jfox85/devx/session"
+)
+
+func TestNetworkStrictness(t *testing.T) {
+	if !(NetworkStrictness(NetworkNone) > NetworkStrictness(NetworkAllowlist) &&
+		NetworkStrictness(NetworkAllowlist) > NetworkStrictness(NetworkFull)) {
+		t.Error("want none > allowlist > full")
+	}
+	if NetworkStrictness("") != NetworkStrictness(NetworkFull) {
+		t.Error("unset mode should rank as full")
+	}
+}
+
+func TestEgressProxyRunArgs(t *testing.T) {
+	args := egressProxyRunArgs("devx-feat-egress-proxy", "devx-feat-net-egress", "devx-egress-proxy:0123", "/state", "devx-feat", StartOpts{
+		HostPorts: map[string]int{"web": 18000},
+		Labels:    map[string]string{"devx.session": "feat"},
+		Network:   NetworkRuntimeConfig{Mode: NetworkAllowlist, ProxyBinary: "/opt/devx-linux"},
+	})
+	joined := strings.Join(args, " ")
+	for _, want := range []string{
+		"--network devx-feat-net-egress",
+		"--read-only",
+		"--cap-drop ALL",
+		"--entrypoint /usr/local/bin/devx",
+		"-v /state:/etc/devx-egress",
+		"-p 127.0.0.1:18000:18000",
+		"devx-egress-proxy:0123 egress-proxy --listen :3128",
+		"--forward 18000=devx-feat:18000",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("args missing %q:\n%s", want, joined)
+		}
+	}
+	if strings.Contains(joined, "/opt/devx-linux") {
+		t.Errorf("the proxy binary should come from the proxy image, not a mount:\n%s", joined)
+	}
+}
+
+func TestCheckStaticBinary(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "devx")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkStaticBinary(script); err == nil {
+		t.Error("a script should be rejected")
+	}
+	if f, err := elf.Open("/bin/sh"); err == nil {
+		dynamic := false
+		for _, p := range f.Progs {
+			dynamic = dynamic || p.Type == elf.PT_INTERP
+		}
+		f.Close()
+		if dynamic && checkStaticBinary("/bin/sh") == nil {
+			t.Error("a dynamically linked binary should be rejected")
+		}
+	}
+}
+
+func TestEgressProxyRootfsHoldsOnlyTheBinary(t *testing.T) {
+	data, err := egressProxyRootfs([]byte("ELF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(bytes.NewReader(data))
+	var files []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			files = append(files, hdr.Name)
+		}
+	}
+	if len(files) != 1 || files[0] != "usr/local/bin/devx" {
+		t.Fatalf("files = %v", files)
+	}
+}
+
+func TestEgressAuditLog(t *testing.T) {
+	if got := EgressAuditLog(session.TargetMeta{Type: "docker"}); got != "" {
+		t.Errorf("full network audit log = %q, want empty", got)
+	}
+	meta := session.TargetMeta{Type: "docker", Network: session.NetworkMeta{Mode: NetworkAllowlist, Dir: "/state"}}
+	if got := EgressAuditLog(meta); got != filepath.Join("/state", "audit.jsonl") {
+		t.Errorf("EgressAuditLog = %q", got)
+	}
+}
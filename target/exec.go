@@ -8,7 +8,10 @@ import (
 
 // ExecInSession builds an exec.Cmd that runs a command in the session's
 // execution environment. For host sessions it runs the command directly.
-// For Docker sessions it wraps with docker exec.
+// For sandbox sessions it wraps with bwrap, for remote sessions with ssh over
+// the session's control master. For container sessions it wraps
+// with docker exec (podman exec for Podman sessions), and plugin sessions
+// run whatever command their plugin returns.
 //
 // The caller is responsible for setting Stdin/Stdout/Stderr and running
 // the command.
@@ -16,12 +19,22 @@ func ExecInSession(meta session.TargetMeta, cmd []string, interactive bool) *exe
 	if meta.Type == "" || meta.Type == "host" {
 		return exec.Command(cmd[0], cmd[1:]...)
 	}
-	// Docker: prefix with docker exec
+	if meta.Type == "remote" {
+		return exec.Command("ssh", remoteArgs(meta.Remote, remoteExecScript(meta.Remote, cmd), interactive)...)
+	}
+	if meta.Type == "sandbox" {
+		prefix := sandboxCommandPrefix(meta)
+		return exec.Command(prefix[0], append(prefix[1:], cmd...)...)
+	}
+	if meta.Plugin.Name != "" {
+		return pluginExecCommand(meta, cmd, interactive)
+	}
+	// Containers: prefix with docker/podman exec
 	args := []string{"exec"}
 	if interactive {
 		args = append(args, "-it")
 	}
 	args = append(args, meta.ContainerName)
 	args = append(args, cmd...)
-	return exec.Command("docker", args...)
+	return exec.Command(session.ContainerCLI(meta), args...)
 }
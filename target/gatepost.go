@@ -59,7 +59,7 @@ func newGatepostRuntime(sessionName string) (gatepostRuntime, error) {
 	}, nil
 }
 
-func prepareGatepostStateDirs(r gatepostRuntime, policyPath string) error {
+func prepareGatepostStateDirs(r gatepostRuntime, policyPath string, policy []byte) error {
 	if err := os.MkdirAll(r.auditDir, 0o700); err != nil {
 		return err
 	}
@@ -77,7 +77,7 @@ func prepareGatepostStateDirs(r gatepostRuntime, policyPath string) error {
 		}
 		_ = os.Chmod(dir, 0o700)
 	}
-	if err := writeGatepostPolicy(policyPath); err != nil {
+	if err := writeGatepostPolicy(policyPath, policy); err != nil {
 		return err
 	}
 	if err := os.WriteFile(filepath.Join(r.auditDir, "audit.jsonl"), nil, 0o600); err != nil {
@@ -158,7 +158,7 @@ func (g *GatepostTarget) Start(ctx context.Context, opts StartOpts) (*StartResul
 			_ = removeGatepostRuntimeState(runtime)
 		}
 	}()
-	if err := prepareGatepostStateDirs(runtime, policyPath); err != nil {
+	if err := prepareGatepostStateDirs(runtime, policyPath, gatepostCfg.Policy); err != nil {
 		return nil, err
 	}
 	statePrepared = true
@@ -192,6 +192,9 @@ func (g *GatepostTarget) Start(ctx context.Context, opts StartOpts) (*StartResul
 	if agentImage == "" {
 		agentImage = getenvDefault("DEVX_GATEPOST_AGENT_IMAGE", getenvDefault("DEVX_DOCKER_IMAGE", "gatepost-pi-agent:latest"))
 	}
+	// The proxy always boots in the run phase, so a restart under
+	// --restart unless-stopped can never come back with the wider install
+	// allow list. Install commands switch phases over the control API.
 	proxyArgs := []string{"run", "-d", "--name", runtime.proxyName,
 		"--network", runtime.egressNet, "--network-alias", "gatepost-control",
 		"--restart", "unless-stopped",
@@ -352,25 +355,14 @@ func (g *GatepostTarget) Start(ctx context.Context, opts StartOpts) (*StartResul
 			}
 		}
 	}
-	sec := opts.Security
-	if sec.MemoryLimit == "" {
-		sec = DefaultSecurityOpts()
-	}
-	for _, cap := range sec.CapDrop {
-		agentArgs = append(agentArgs, "--cap-drop="+cap)
-	}
-	if sec.NoNewPrivs {
-		agentArgs = append(agentArgs, "--security-opt", "no-new-privileges")
-	}
-	if sec.MemoryLimit != "" {
-		agentArgs = append(agentArgs, "--memory", sec.MemoryLimit)
-	}
-	if sec.CPULimit != "" {
-		agentArgs = append(agentArgs, "--cpus", sec.CPULimit)
-	}
-	if sec.PidsLimit > 0 {
-		agentArgs = append(agentArgs, "--pids-limit", fmt.Sprintf("%d", sec.PidsLimit))
+	if err := ensureCacheVolumes(ctx, "docker", opts.Caches); err != nil {
+		if cleanupErr := cleanupWithLogs(gatepostCleanupMeta(runtime, 0)); cleanupErr != nil {
+			return nil, fmt.Errorf("%w; cleanup failed: %v", err, cleanupErr)
+		}
+		return nil, err
 	}
+	agentArgs = append(agentArgs, cacheMountArgs(opts.Caches)...)
+	agentArgs = append(agentArgs, securityArgs(opts.Security)...)
 	projectAlias := opts.Labels["devx.project"]
 	agentRole := "devx_" + sanitizeRoleSegment(projectAlias) + "_coding_session"
 	agentBranch := opts.SessionName
@@ -484,6 +476,15 @@ func (g *GatepostTarget) Start(ctx context.Context, opts StartOpts) (*StartResul
 		}
 	}
 
+	if len(gatepostCfg.InstallCommands) > 0 {
+		if err := runGatepostInstallCommands(ctx, runtime.agentName, controlURL, runtime.configDir, gatepostCfg.InstallCommands); err != nil {
+			if cleanupErr := cleanupWithLogs(gatepostCleanupMeta(runtime, 0)); cleanupErr != nil {
+				return nil, fmt.Errorf("%w; cleanup failed: %v", err, cleanupErr)
+			}
+			return nil, err
+		}
+	}
+
 	containerID, err := dockerOutput(ctx, "inspect", "--format", "{{.Id}}", runtime.agentName)
 	if err != nil {
 		if cleanupErr := cleanupWithLogs(gatepostCleanupMeta(runtime, 0)); cleanupErr != nil {
@@ -509,7 +510,32 @@ func (g *GatepostTarget) Start(ctx context.Context, opts StartOpts) (*StartResul
 		return nil, err
 	}
 	success = true
-	return &StartResult{Meta: session.TargetMeta{Type: "gatepost", ContainerID: containerID, ContainerName: runtime.agentName, NetworkName: runtime.internalNet, Image: agentImage, Gatepost: session.GatepostMeta{Enabled: true, Runtime: "docker-mitmproxy", ProxyContainerName: runtime.proxyName, InternalNetworkName: runtime.internalNet, EgressNetworkName: runtime.egressNet, PortsNetworkName: runtime.portsNet, SessionDir: runtime.sessionDir, AuditDir: runtime.auditDir, ConfigDir: runtime.configDir, AgentHomeDir: runtime.agentHomeDir, AuditLog: filepath.Join(runtime.auditDir, "audit.jsonl"), CompanionLog: filepath.Join(runtime.auditDir, "companion.jsonl"), ControlURL: controlURL, LogsURL: logs.PublicURL, LogsTokenPath: logsTokenPath, LogsPID: logs.PID, ProviderMode: providerBootstrap.Mode, ProviderCommand: providerBootstrap.Command, RegisteredProviders: providerBootstrap.Registered, ProviderWarnings: providerBootstrap.Warnings}}}, nil
+	return &StartResult{Meta: session.TargetMeta{Type: "gatepost", ContainerID: containerID, ContainerName: runtime.agentName, NetworkName: runtime.internalNet, Image: agentImage, Gatepost: session.GatepostMeta{Enabled: true, Runtime: "docker-mitmproxy", ProxyContainerName: runtime.proxyName, InternalNetworkName: runtime.internalNet, EgressNetworkName: runtime.egressNet, PortsNetworkName: runtime.portsNet, SessionDir: runtime.sessionDir, AuditDir: runtime.auditDir, ConfigDir: runtime.configDir, AgentHomeDir: runtime.agentHomeDir, AuditLog: filepath.Join(runtime.auditDir, "audit.jsonl"), CompanionLog: filepath.Join(runtime.auditDir, "companion.jsonl"), ControlURL: controlURL, Phase: "run", ConfiguredSecrets: gatepostSecretNames(gatepostCfg.Secrets), LogsURL: logs.PublicURL, LogsTokenPath: logsTokenPath, LogsPID: logs.PID, ProviderMode: providerBootstrap.Mode, ProviderCommand: providerBootstrap.Command, RegisteredProviders: providerBootstrap.Registered, ProviderWarnings: providerBootstrap.Warnings}}}, nil
+}
+
+// runGatepostInstallCommands switches the proxy to the install phase, runs
+// the configured dependency installation in the agent's /workspace, then
+// switches back to run.
+func runGatepostInstallCommands(ctx context.Context, agentName, controlURL, configDir string, commands []string) error {
+	meta := session.TargetMeta{Type: "gatepost", Gatepost: session.GatepostMeta{Enabled: true, ControlURL: controlURL, ConfigDir: configDir}}
+	if err := SetGatepostPhase(ctx, meta, "install", 0); err != nil {
+		return fmt.Errorf("switch to install phase: %w", err)
+	}
+	var runErr error
+	for _, c := range commands {
+		fmt.Printf("Running install command (install phase): %s\n", c)
+		if err := dockerRun(ctx, "exec", "-w", "/workspace", agentName, "bash", "-lc", c); err != nil {
+			runErr = fmt.Errorf("install command %q: %w", c, err)
+			break
+		}
+	}
+	if err := SetGatepostPhase(ctx, meta, "run", 0); err != nil {
+		if runErr != nil {
+			return fmt.Errorf("%w; switch back to run phase: %v", runErr, err)
+		}
+		return fmt.Errorf("switch to run phase after install: %w", err)
+	}
+	return runErr
 }
 
 func (g *GatepostTarget) Stop(ctx context.Context, meta session.TargetMeta) error {
@@ -830,10 +856,17 @@ func sanitizeRoleSegment(s string) string {
 	return result
 }
 
+// gatepostSecretRefreshInterval bounds how often ReprovisionGatepostSecrets
+// re-reads configured secrets, since file and command sources can be slow.
+const gatepostSecretRefreshInterval = time.Minute
+
 // ReprovisionGatepostSecrets checks if the proxy lost secrets (e.g. after a
-// container restart) and re-runs provider bootstrap to restore them.
+// container restart) and re-runs provider bootstrap to restore them. When
+// the secrets are present it re-reads the session's gatepost.secrets and
+// re-registers any whose value rotated.
 // cfg supplies trusted runtime config (logs command, root, etc.).
 func ReprovisionGatepostSecrets(gp session.GatepostMeta, cfg GatepostRuntimeConfig) {
+	cfg.Secrets = sessionGatepostSecrets(gp, cfg.Secrets)
 	if gp.ControlURL == "" || gp.ConfigDir == "" {
 		return
 	}
@@ -860,7 +893,18 @@ func ReprovisionGatepostSecrets(gp session.GatepostMeta, cfg GatepostRuntimeConf
 		return
 	}
 	if len(body.Secrets) > 0 {
-		return // secrets present, nothing to do
+		if len(cfg.Secrets) == 0 || !gatepostSecretDigests.due(gp.ControlURL, gatepostSecretRefreshInterval, time.Now()) {
+			return
+		}
+		meta := session.TargetMeta{Type: "gatepost", Gatepost: gp}
+		rotated, err := RefreshGatepostSecrets(context.Background(), meta, cfg.Secrets, false)
+		if err != nil {
+			log.Printf("gatepost reprovision: %v", err)
+		}
+		if len(rotated) > 0 {
+			log.Printf("gatepost reprovision: re-registered rotated secrets %s", strings.Join(rotated, ", "))
+		}
+		return
 	}
 	// Re-run provider bootstrap from the trusted configured Gatepost checkout.
 	log.Printf("gatepost reprovision: secrets empty for %s, re-bootstrapping", gp.ControlURL)
@@ -200,7 +200,7 @@ func TestPrepareGatepostStateDirsCreatesAgentHome(t *testing.T) {
 		t.Fatalf("newGatepostRuntime: %v", err)
 	}
 	policyPath := filepath.Join(r.configDir, "policy.gatepost.yaml")
-	if err := prepareGatepostStateDirs(r, policyPath); err != nil {
+	if err := prepareGatepostStateDirs(r, policyPath, nil); err != nil {
 		t.Fatalf("prepareGatepostStateDirs: %v", err)
 	}
 
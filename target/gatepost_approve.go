@@ -0,0 +1,182 @@
+package target
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/jfox85/devx/session"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadGatepostAuditFrom parses the complete audit lines written after offset
+// and returns the offset to resume from. A log that shrank (rotated or
+// recreated) is read from the start.
+func ReadGatepostAuditFrom(path string, offset int64) ([]GatepostAuditEntry, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, offset, err
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+
+	var entries []GatepostAuditEntry
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			// A line without its newline is still being written.
+			break
+		}
+		offset += int64(len(line))
+		if entry, ok := parseGatepostAuditLine(line); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, offset, nil
+}
+
+// AllowGatepostHostOnce asks the proxy to let the next request to host
+// through without changing the policy.
+func AllowGatepostHostOnce(ctx context.Context, meta session.TargetMeta, host string) error {
+	if meta.Type != "gatepost" || !meta.Gatepost.Enabled || meta.Gatepost.ControlURL == "" {
+		return fmt.Errorf("session has no gatepost control API")
+	}
+	status, err := gatepostControlPost(ctx, meta, "/rules/allow-once", map[string]string{"host": host})
+	if err != nil {
+		return fmt.Errorf("gatepost allow-once: %w", err)
+	}
+	switch {
+	case status == http.StatusNotFound:
+		return fmt.Errorf("gatepost allow-once: this proxy does not support one-time grants; allow the host for the session instead")
+	case status < 200 || status >= 300:
+		return fmt.Errorf("gatepost allow-once: status %d", status)
+	}
+	return nil
+}
+
+// AllowGatepostHost adds host to a phase of the session's running policy and
+// reloads it. Callers record the host in GatepostMeta.AllowedHosts so that
+// regenerating the session's policy keeps it; see SessionGatepostOverlay.
+func AllowGatepostHost(ctx context.Context, meta session.TargetMeta, phase, host string) error {
+	if !containsString(GatepostPolicyPhases, phase) {
+		return fmt.Errorf("unknown gatepost phase %q", phase)
+	}
+	path, err := GatepostPolicyPath(meta)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var policy GatepostPolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	policy.Apply(&GatepostPolicyOverlay{Phases: map[string]GatepostPolicyPhaseOverlay{phase: {Add: []string{host}}}})
+	out, err := policy.Marshal()
+	if err != nil {
+		return err
+	}
+	return ReloadGatepostPolicy(ctx, meta, out)
+}
+
+// SessionGatepostOverlay returns the hosts allowed for a session only as an
+// overlay on the project's policy, or nil when there are none.
+func SessionGatepostOverlay(gp session.GatepostMeta) *GatepostPolicyOverlay {
+	if len(gp.AllowedHosts) == 0 {
+		return nil
+	}
+	o := &GatepostPolicyOverlay{Phases: map[string]GatepostPolicyPhaseOverlay{}}
+	for phase, hosts := range gp.AllowedHosts {
+		o.Phases[phase] = GatepostPolicyPhaseOverlay{Add: hosts}
+	}
+	return o
+}
+
+// AddGatepostPolicyHost adds host to phases.<phase>.add of the overlay at
+// path, creating the file if needed. Existing content and comments are kept.
+func AddGatepostPolicyHost(path, phase, host string) error {
+	if !gatepostHostRe.MatchString(host) {
+		return fmt.Errorf("%q is not a hostname or *.domain pattern", host)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = []byte("version: 1\n")
+	}
+	if _, err := ParseGatepostPolicyOverlay(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	root := doc.Content[0]
+	add := yamlMappingChild(yamlMappingChild(yamlMappingChild(root, "phases", yaml.MappingNode), phase, yaml.MappingNode), "add", yaml.SequenceNode)
+	for _, n := range add.Content {
+		if n.Value == host {
+			return nil
+		}
+	}
+	add.Content = append(add.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: host})
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if _, err := ParseGatepostPolicyOverlay(buf.Bytes()); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// yamlMappingChild returns the value of key in a mapping node, adding an
+// empty node of the given kind when the key is missing or null.
+func yamlMappingChild(m *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			v := m.Content[i+1]
+			if v.Kind == yaml.ScalarNode && v.Tag == "!!null" {
+				*v = yaml.Node{Kind: kind}
+				if kind == yaml.SequenceNode {
+					v.Style = yaml.FlowStyle
+				}
+			}
+			return v
+		}
+	}
+	v := &yaml.Node{Kind: kind}
+	if kind == yaml.SequenceNode {
+		v.Style = yaml.FlowStyle
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+	return v
+}
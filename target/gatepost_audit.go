@@ -0,0 +1,229 @@
+package target
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jfox85/devx/internal/duration"
+)
+
+// GatepostAuditEntry is one egress decision from a Gatepost audit.jsonl.
+type GatepostAuditEntry struct {
+	Seq       int64     `json:"seq,omitempty"`
+	Time      time.Time `json:"time"`
+	Host      string    `json:"host"`
+	Method    string    `json:"method,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	Decision  string    `json:"decision"` // "allowed", "denied" or the proxy's raw value
+	Evaluator string    `json:"evaluator,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Phase     string    `json:"phase,omitempty"`
+}
+
+// GatepostAuditFilter selects audit entries. Zero values match everything.
+type GatepostAuditFilter struct {
+	Since    time.Time
+	Host     string // exact host or "*.example.com"
+	Decision string // "allowed" or "denied"
+}
+
+// GatepostHostCount is a host with its number of requests.
+type GatepostHostCount struct {
+	Host  string `json:"host"`
+	Count int    `json:"count"`
+}
+
+// GatepostAuditSummary aggregates audit entries.
+type GatepostAuditSummary struct {
+	Total     int                 `json:"total"`
+	Allowed   int                 `json:"allowed"`
+	Denied    int                 `json:"denied"`
+	TopHosts  []GatepostHostCount `json:"top_hosts"`
+	TopDenied []GatepostHostCount `json:"top_denied"`
+}
+
+// ReadGatepostAudit returns the matching entries of an audit log in file
+// order. Lines that are not egress decisions (events, malformed lines) are
+// skipped.
+func ReadGatepostAudit(path string, filter GatepostAuditFilter) ([]GatepostAuditEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	filter.Decision = normalizeGatepostDecision(filter.Decision)
+	var entries []GatepostAuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		entry, ok := parseGatepostAuditLine(scanner.Bytes())
+		if !ok || !filter.matches(entry) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func (f GatepostAuditFilter) matches(e GatepostAuditEntry) bool {
+	if !f.Since.IsZero() && !e.Time.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if f.Host != "" && !matchGatepostHost(f.Host, e.Host) {
+		return false
+	}
+	if f.Decision != "" && e.Decision != f.Decision {
+		return false
+	}
+	return true
+}
+
+// matchGatepostHost matches a host against an exact name or a "*." pattern,
+// which like the policy matches any subdomain but not the domain itself.
+func matchGatepostHost(pattern, host string) bool {
+	pattern, host = strings.ToLower(pattern), strings.ToLower(host)
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+	}
+	return host == pattern
+}
+
+// parseGatepostAuditLine reads the fields DevX needs from an audit record,
+// accepting the flat and nested (request/decision objects) layouts.
+func parseGatepostAuditLine(line []byte) (GatepostAuditEntry, bool) {
+	var raw map[string]any
+	if json.Unmarshal(line, &raw) != nil {
+		return GatepostAuditEntry{}, false
+	}
+	req, _ := raw["request"].(map[string]any)
+	dec, _ := raw["decision"].(map[string]any)
+	str := func(keys ...string) string {
+		for _, m := range []map[string]any{raw, req, dec} {
+			for _, k := range keys {
+				if v, ok := m[k].(string); ok && v != "" {
+					return v
+				}
+			}
+		}
+		return ""
+	}
+
+	e := GatepostAuditEntry{
+		Method:    str("method"),
+		URL:       str("url"),
+		Evaluator: str("evaluator"),
+		Reason:    str("reason"),
+		Phase:     str("phase"),
+	}
+	if v, ok := raw["decision"].(string); ok {
+		e.Decision = v
+	} else {
+		e.Decision = str("action", "verdict", "result")
+	}
+	e.Decision = normalizeGatepostDecision(e.Decision)
+	if seq, ok := raw["seq"].(float64); ok {
+		e.Seq = int64(seq)
+	}
+	e.Host = str("host")
+	if e.Host == "" && e.URL != "" {
+		if u, err := url.Parse(e.URL); err == nil {
+			e.Host = u.Host
+		}
+	}
+	if h, _, err := net.SplitHostPort(e.Host); err == nil {
+		e.Host = h
+	}
+	e.Host = strings.ToLower(e.Host)
+	if e.Host == "" || e.Decision == "" {
+		return GatepostAuditEntry{}, false
+	}
+	e.Time = parseGatepostAuditTime(raw)
+	return e, true
+}
+
+func parseGatepostAuditTime(raw map[string]any) time.Time {
+	for _, k := range []string{"ts", "timestamp", "time"} {
+		switch v := raw[k].(type) {
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t
+			}
+		case float64:
+			sec := int64(v)
+			return time.Unix(sec, int64((v-float64(sec))*1e9))
+		}
+	}
+	return time.Time{}
+}
+
+func normalizeGatepostDecision(d string) string {
+	switch d = strings.ToLower(strings.TrimSpace(d)); d {
+	case "allow", "allowed", "pass":
+		return "allowed"
+	case "deny", "denied", "block", "blocked", "reject", "rejected":
+		return "denied"
+	}
+	return d
+}
+
+// SummarizeGatepostAudit counts decisions and the n busiest and most-denied
+// hosts.
+func SummarizeGatepostAudit(entries []GatepostAuditEntry, n int) GatepostAuditSummary {
+	s := GatepostAuditSummary{Total: len(entries)}
+	hosts := make(map[string]int)
+	denied := make(map[string]int)
+	for _, e := range entries {
+		hosts[e.Host]++
+		switch e.Decision {
+		case "allowed":
+			s.Allowed++
+		case "denied":
+			s.Denied++
+			denied[e.Host]++
+		}
+	}
+	s.TopHosts = topGatepostHosts(hosts, n)
+	s.TopDenied = topGatepostHosts(denied, n)
+	return s
+}
+
+func topGatepostHosts(counts map[string]int, n int) []GatepostHostCount {
+	out := make([]GatepostHostCount, 0, len(counts))
+	for h, c := range counts {
+		out = append(out, GatepostHostCount{Host: h, Count: c})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Host < out[j].Host
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// ParseAuditSince parses a --since value: a duration ago ("90m", "1h",
+// "2d") or an RFC 3339 time.
+func ParseAuditSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := duration.Parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %q: use a duration like 1h or 2d, or an RFC 3339 time", s)
+	}
+	return now.Add(-d), nil
+}
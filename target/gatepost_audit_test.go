@@ -0,0 +1,104 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testGatepostAudit = `{"ts":"2026-10-18T10:00:00Z","audit_seq":1,"event":"startup","metadata":{}}
+{"ts":"2026-10-18T10:01:00Z","seq":2,"host":"api.github.com","method":"GET","decision":"allow","evaluator":"policy"}
+{"ts":"2026-10-18T10:02:00Z","seq":3,"request":{"url":"https://evil.example.com:443/x","method":"POST"},"decision":{"action":"deny","reason":"not in allowlist","evaluator":"smart"}}
+not json
+{"ts":"2026-10-18T10:03:00Z","seq":4,"host":"codeload.github.com","decision":"denied"}
+{"ts":"2026-10-18T10:04:00Z","seq":5,"host":"api.github.com","decision":"allowed"}
+`
+
+func writeTestAudit(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte(testGatepostAudit), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadGatepostAuditParsesLayouts(t *testing.T) {
+	entries, err := ReadGatepostAudit(writeTestAudit(t), GatepostAuditFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4: %+v", len(entries), entries)
+	}
+	nested := entries[1]
+	if nested.Host != "evil.example.com" || nested.Decision != "denied" || nested.Method != "POST" ||
+		nested.Reason != "not in allowlist" || nested.Evaluator != "smart" || nested.Seq != 3 {
+		t.Errorf("nested entry = %+v", nested)
+	}
+	if entries[0].Decision != "allowed" || entries[0].Time.Minute() != 1 {
+		t.Errorf("flat entry = %+v", entries[0])
+	}
+}
+
+func TestReadGatepostAuditFilters(t *testing.T) {
+	path := writeTestAudit(t)
+	since, _ := time.Parse(time.RFC3339, "2026-10-18T10:02:30Z")
+	for name, tc := range map[string]struct {
+		filter GatepostAuditFilter
+		want   int
+	}{
+		"since":           {GatepostAuditFilter{Since: since}, 2},
+		"wildcard host":   {GatepostAuditFilter{Host: "*.github.com"}, 3},
+		"exact host":      {GatepostAuditFilter{Host: "API.github.com"}, 2},
+		"wildcard denied": {GatepostAuditFilter{Host: "*.github.com", Decision: "denied"}, 1},
+		"denied":          {GatepostAuditFilter{Decision: "deny"}, 2},
+		"apex not glob":   {GatepostAuditFilter{Host: "*.api.github.com"}, 0},
+	} {
+		entries, err := ReadGatepostAudit(path, tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != tc.want {
+			t.Errorf("%s: got %d entries, want %d", name, len(entries), tc.want)
+		}
+	}
+}
+
+func TestSummarizeGatepostAudit(t *testing.T) {
+	entries, err := ReadGatepostAudit(writeTestAudit(t), GatepostAuditFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := SummarizeGatepostAudit(entries, 2)
+	if s.Total != 4 || s.Allowed != 2 || s.Denied != 2 {
+		t.Errorf("counts = %+v", s)
+	}
+	if len(s.TopHosts) != 2 || s.TopHosts[0] != (GatepostHostCount{Host: "api.github.com", Count: 2}) {
+		t.Errorf("top hosts = %+v", s.TopHosts)
+	}
+	if len(s.TopDenied) != 2 || s.TopDenied[0].Host != "codeload.github.com" {
+		t.Errorf("top denied = %+v", s.TopDenied)
+	}
+}
+
+func TestParseAuditSince(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"":                     {},
+		"90m":                  now.Add(-90 * time.Minute),
+		"2d":                   now.Add(-48 * time.Hour),
+		"2026-10-18T08:00:00Z": time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC),
+	} {
+		got, err := ParseAuditSince(in, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseAuditSince(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"yesterday", "-1h", "1w"} {
+		if _, err := ParseAuditSince(bad, now); err == nil {
+			t.Errorf("ParseAuditSince(%q) should fail", bad)
+		}
+	}
+}
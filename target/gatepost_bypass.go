@@ -0,0 +1,96 @@
+package target
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jfox85/devx/session"
+)
+
+// Bypass events DevX records in the session's companion log, next to the
+// proxy's own audit log.
+const (
+	GatepostBypassStart = "bypass_start"
+	GatepostBypassStop  = "bypass_stop"
+)
+
+// GatepostBypassEvent records a bypass being enabled or enforcement being
+// restored. Trigger is "manual" or "expired".
+type GatepostBypassEvent struct {
+	Time    time.Time  `json:"ts"`
+	Event   string     `json:"event"`
+	Reason  string     `json:"reason,omitempty"`
+	Until   *time.Time `json:"until,omitempty"`
+	Trigger string     `json:"trigger,omitempty"`
+}
+
+// gatepostCompanionLog is where DevX-side audit events for the session go.
+func gatepostCompanionLog(gp session.GatepostMeta) string {
+	if gp.CompanionLog != "" {
+		return gp.CompanionLog
+	}
+	if gp.AuditDir != "" {
+		return filepath.Join(gp.AuditDir, "companion.jsonl")
+	}
+	return ""
+}
+
+// RecordGatepostBypassEvent appends a bypass event to the session's
+// companion log.
+func RecordGatepostBypassEvent(gp session.GatepostMeta, ev GatepostBypassEvent) error {
+	path := gatepostCompanionLog(gp)
+	if path == "" {
+		return fmt.Errorf("gatepost runtime metadata has no audit directory")
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// ReadGatepostBypassEvents returns the bypass events in the session's
+// companion log at or after since, oldest first. Other lines are skipped.
+func ReadGatepostBypassEvents(gp session.GatepostMeta, since time.Time) ([]GatepostBypassEvent, error) {
+	path := gatepostCompanionLog(gp)
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var events []GatepostBypassEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev GatepostBypassEvent
+		if json.Unmarshal(scanner.Bytes(), &ev) != nil {
+			continue
+		}
+		if ev.Time.Before(since) {
+			continue
+		}
+		if ev.Event == GatepostBypassStart || ev.Event == GatepostBypassStop {
+			events = append(events, ev)
+		}
+	}
+	return events, scanner.Err()
+}
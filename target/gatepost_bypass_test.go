@@ -0,0 +1,42 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jfox85/devx/session"
+)
+
+func TestGatepostBypassEventsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	gp := session.GatepostMeta{AuditDir: dir}
+	if err := os.WriteFile(filepath.Join(dir, "companion.jsonl"), []byte("{\"event\":\"other\"}\nnot json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	until := start.Add(15 * time.Minute)
+	for _, ev := range []GatepostBypassEvent{
+		{Time: start.Add(-time.Hour), Event: GatepostBypassStart, Trigger: "manual"},
+		{Time: start, Event: GatepostBypassStart, Reason: "registry auth", Until: &until, Trigger: "manual"},
+		{Time: until, Event: GatepostBypassStop, Trigger: "expired"},
+	} {
+		if err := RecordGatepostBypassEvent(gp, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events, err := ReadGatepostBypassEvents(gp, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events since start, got %#v", events)
+	}
+	if events[0].Reason != "registry auth" || events[0].Until == nil || !events[0].Until.Equal(until) {
+		t.Fatalf("unexpected start event %#v", events[0])
+	}
+	if events[1].Event != GatepostBypassStop || events[1].Trigger != "expired" {
+		t.Fatalf("unexpected stop event %#v", events[1])
+	}
+}
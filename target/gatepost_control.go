@@ -3,7 +3,9 @@ package target
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/jfox85/devx/session"
 )
@@ -32,6 +34,37 @@ func SetGatepostBypass(ctx context.Context, meta session.TargetMeta, bypass bool
 	return nil
 }
 
+// SetGatepostPhase switches the policy phase the proxy enforces. A positive
+// revertAfter also asks the proxy to return to the run phase once it elapses;
+// DevX enforces the revert itself as proxies may ignore the hint.
+func SetGatepostPhase(ctx context.Context, meta session.TargetMeta, phase string, revertAfter time.Duration) error {
+	if meta.Type != "gatepost" || !meta.Gatepost.Enabled {
+		return fmt.Errorf("not a gatepost target")
+	}
+	if !containsString(GatepostPolicyPhases, phase) {
+		return fmt.Errorf("unknown gatepost phase %q (valid: %s)", phase, strings.Join(GatepostPolicyPhases, ", "))
+	}
+	if meta.Gatepost.ControlURL == "" {
+		return fmt.Errorf("gatepost runtime metadata is incomplete")
+	}
+	body := map[string]any{"phase": phase}
+	if revertAfter > 0 {
+		body["revert_to"] = "run"
+		body["revert_after_seconds"] = int(revertAfter.Round(time.Second).Seconds())
+	}
+	status, err := gatepostControlPost(ctx, meta, "/phase", body)
+	if err != nil {
+		return fmt.Errorf("gatepost phase switch: %w", err)
+	}
+	switch {
+	case status == http.StatusNotFound:
+		return fmt.Errorf("gatepost phase switch: this proxy does not support switching phases")
+	case status < 200 || status >= 300:
+		return fmt.Errorf("gatepost phase switch: status %d", status)
+	}
+	return nil
+}
+
 func isAlreadyConnected(err error) bool {
 	if err == nil {
 		return false
@@ -0,0 +1,49 @@
+package target
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jfox85/devx/session"
+)
+
+func TestSetGatepostPhase(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/phase" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		got = nil
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+	meta := session.TargetMeta{Type: "gatepost", Gatepost: session.GatepostMeta{Enabled: true, ControlURL: server.URL, ConfigDir: t.TempDir()}}
+	ctx := context.Background()
+
+	if err := SetGatepostPhase(ctx, meta, "install", 10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if got["phase"] != "install" || got["revert_to"] != "run" || got["revert_after_seconds"] != float64(600) {
+		t.Fatalf("timed switch body = %v", got)
+	}
+	if err := SetGatepostPhase(ctx, meta, "run", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["revert_after_seconds"]; ok || got["phase"] != "run" {
+		t.Fatalf("switch body = %v", got)
+	}
+	if err := SetGatepostPhase(ctx, meta, "build", 0); err == nil {
+		t.Fatal("unknown phase should fail")
+	}
+
+	meta.Gatepost.ControlURL = server.URL + "/old"
+	if err := SetGatepostPhase(ctx, meta, "run", 0); err == nil || !strings.Contains(err.Error(), "does not support") {
+		t.Fatalf("missing endpoint: %v", err)
+	}
+}
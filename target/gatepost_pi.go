@@ -2,6 +2,7 @@ package target
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -52,6 +53,29 @@ func piModelsFile() string {
 }
 
 func bootstrapGatepostProviderSecrets(cfg GatepostRuntimeConfig, gatepostRoot, controlURL, token string) (gatepostProviderBootstrap, error) {
+	result, err := bootstrapGatepostProviders(cfg, gatepostRoot, controlURL, token)
+	if err != nil {
+		return result, err
+	}
+	// Configured secrets go last so they replace a built-in secret of the
+	// same name.
+	registered, warnings, err := registerConfiguredGatepostSecrets(context.Background(), cfg.Secrets, controlURL, token)
+	result.Warnings = append(result.Warnings, warnings...)
+	for _, name := range registered {
+		if !containsString(result.Registered, name) {
+			result.Registered = append(result.Registered, name)
+		}
+	}
+	if err != nil {
+		return result, fmt.Errorf("register configured gatepost secrets: %w", err)
+	}
+	if err := requireGatepostProviders(cfg, result.Registered); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func bootstrapGatepostProviders(cfg GatepostRuntimeConfig, gatepostRoot, controlURL, token string) (gatepostProviderBootstrap, error) {
 	result := gatepostProviderBootstrap{Env: map[string]string{}}
 	cmdPath, args, mode, err := gatepostProviderBootstrapCommand(cfg, gatepostRoot, controlURL)
 	if err == nil {
@@ -67,9 +91,6 @@ func bootstrapGatepostProviderSecrets(cfg GatepostRuntimeConfig, gatepostRoot, c
 		cmd.Stderr = &stderr
 		if runErr := cmd.Run(); runErr == nil {
 			parseProviderBootstrapOutput(stdout.String(), &result)
-			if err := requireGatepostProviders(cfg, result.Registered); err != nil {
-				return result, err
-			}
 			return result, nil
 		} else {
 			result.Warnings = append(result.Warnings, fmt.Sprintf("%s failed with exit status; stderr suppressed", result.Mode))
@@ -90,9 +111,6 @@ func bootstrapGatepostProviderSecrets(cfg GatepostRuntimeConfig, gatepostRoot, c
 	if len(registered) > 0 && len(result.Warnings) > 0 {
 		result.Warnings = append(result.Warnings, "continued with host environment provider credentials")
 	}
-	if err := requireGatepostProviders(cfg, result.Registered); err != nil {
-		return result, err
-	}
 	return result, nil
 }
 
@@ -150,7 +168,7 @@ func requireGatepostProviders(cfg GatepostRuntimeConfig, registered []string) er
 	requiredRaw := getenvDefault("DEVX_GATEPOST_REQUIRED_PROVIDERS", cfg.RequiredProviders)
 	if requiredRaw == "" {
 		if len(registered) == 0 {
-			return fmt.Errorf("no Gatepost providers were registered; set OPENAI_API_KEY, GEMINI_API_KEY, CLIPROXYAPI_API_KEY, or configure Pi/Gatepost provider bootstrap")
+			return fmt.Errorf("no Gatepost providers were registered; set OPENAI_API_KEY, GEMINI_API_KEY, CLIPROXYAPI_API_KEY, configure gatepost.secrets, or configure Pi/Gatepost provider bootstrap")
 		}
 		return nil
 	}
@@ -1,6 +1,23 @@
 package target
 
-import "os"
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jfox85/devx/session"
+	"gopkg.in/yaml.v3"
+)
 
 const defaultGatepostPolicy = `version: 1
 
@@ -59,6 +76,374 @@ phases:
     unknown_action: smart
 `
 
-func writeGatepostPolicy(path string) error {
-	return os.WriteFile(path, []byte(defaultGatepostPolicy), 0o600)
+// GatepostPolicyFile is the name of the policy overlay in the global config
+// directory and in a project's .devx directory.
+const GatepostPolicyFile = "gatepost-policy.yaml"
+
+// GatepostPolicy is an effective egress policy as the proxy reads it.
+type GatepostPolicy struct {
+	Version  int                             `yaml:"version"`
+	Defaults GatepostPolicyDefaults          `yaml:"defaults"`
+	Phases   map[string]*GatepostPolicyPhase `yaml:"phases"`
+}
+
+// GatepostPolicyDefaults applies to hosts no phase rule matches.
+type GatepostPolicyDefaults struct {
+	UnknownAction string `yaml:"unknown_action,omitempty"`
+	FailMode      string `yaml:"fail_mode,omitempty"`
+}
+
+// GatepostPolicyPhase is the allow list of one phase.
+type GatepostPolicyPhase struct {
+	Allow         []GatepostPolicyRule `yaml:"allow"`
+	UnknownAction string               `yaml:"unknown_action,omitempty"`
+}
+
+// GatepostPolicyRule allows a host; "*." matches any subdomain.
+type GatepostPolicyRule struct {
+	Host string `yaml:"host"`
+}
+
+// GatepostPolicyOverlay is a global or project gatepost-policy.yaml. It edits
+// the default policy rather than replacing it:
+//
+//	version: 1
+//	phases:
+//	  run:
+//	    add: [npm.internal.example.com]
+//	    remove: [r.jina.ai]
+//	    unknown_action: deny
+type GatepostPolicyOverlay struct {
+	Version  int                                   `yaml:"version"`
+	Defaults GatepostPolicyDefaults                `yaml:"defaults"`
+	Phases   map[string]GatepostPolicyPhaseOverlay `yaml:"phases"`
+}
+
+// GatepostPolicyPhaseOverlay adds and removes hosts in one phase. Removals
+// apply before additions.
+type GatepostPolicyPhaseOverlay struct {
+	Add           []string `yaml:"add"`
+	Remove        []string `yaml:"remove"`
+	UnknownAction string   `yaml:"unknown_action"`
+}
+
+// GatepostPolicyPhases are the phases the proxy knows.
+var GatepostPolicyPhases = []string{"install", "run"}
+
+var (
+	gatepostUnknownActions = []string{"allow", "deny", "smart"}
+	gatepostFailModes      = []string{"closed", "open"}
+	gatepostHostRe         = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+)
+
+// DefaultGatepostPolicy returns the built-in policy.
+func DefaultGatepostPolicy() *GatepostPolicy {
+	var p GatepostPolicy
+	if err := yaml.Unmarshal([]byte(defaultGatepostPolicy), &p); err != nil {
+		panic("invalid built-in gatepost policy: " + err.Error())
+	}
+	return &p
+}
+
+// ParseGatepostPolicy decodes an effective policy as written for the proxy.
+func ParseGatepostPolicy(data []byte) (*GatepostPolicy, error) {
+	var p GatepostPolicy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GatepostPolicyPaths returns the global and project overlay paths. The
+// project overlay is read from the project checkout, not a session worktree,
+// so an agent cannot widen its own egress.
+func GatepostPolicyPaths(projectPath string) []string {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".config", "devx", GatepostPolicyFile))
+	}
+	if projectPath != "" {
+		paths = append(paths, filepath.Join(projectPath, ".devx", GatepostPolicyFile))
+	}
+	return paths
+}
+
+// LoadGatepostPolicyOverlay parses and validates an overlay file. A missing
+// file returns nil.
+func LoadGatepostPolicyOverlay(path string) (*GatepostPolicyOverlay, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	o, err := ParseGatepostPolicyOverlay(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return o, nil
+}
+
+// ParseGatepostPolicyOverlay decodes an overlay, rejecting unknown fields,
+// and validates it.
+func ParseGatepostPolicyOverlay(data []byte) (*GatepostPolicyOverlay, error) {
+	var o GatepostPolicyOverlay
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&o); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// Validate checks an overlay against the policy schema.
+func (o *GatepostPolicyOverlay) Validate() error {
+	var errs []error
+	if o.Version != 1 {
+		errs = append(errs, fmt.Errorf("version: must be 1, got %d", o.Version))
+	}
+	if v := o.Defaults.UnknownAction; v != "" && !containsString(gatepostUnknownActions, v) {
+		errs = append(errs, fmt.Errorf("defaults.unknown_action: %q is not one of %s", v, strings.Join(gatepostUnknownActions, ", ")))
+	}
+	if v := o.Defaults.FailMode; v != "" && !containsString(gatepostFailModes, v) {
+		errs = append(errs, fmt.Errorf("defaults.fail_mode: %q is not one of %s", v, strings.Join(gatepostFailModes, ", ")))
+	}
+	for _, name := range sortedKeys(o.Phases) {
+		phase := o.Phases[name]
+		if !containsString(GatepostPolicyPhases, name) {
+			errs = append(errs, fmt.Errorf("phases.%s: unknown phase (valid: %s)", name, strings.Join(GatepostPolicyPhases, ", ")))
+			continue
+		}
+		if v := phase.UnknownAction; v != "" && !containsString(gatepostUnknownActions, v) {
+			errs = append(errs, fmt.Errorf("phases.%s.unknown_action: %q is not one of %s", name, v, strings.Join(gatepostUnknownActions, ", ")))
+		}
+		for field, hosts := range map[string][]string{"add": phase.Add, "remove": phase.Remove} {
+			for _, h := range hosts {
+				if !gatepostHostRe.MatchString(h) {
+					errs = append(errs, fmt.Errorf("phases.%s.%s: %q is not a hostname or *.domain pattern", name, field, h))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Apply merges an overlay onto the policy and returns warnings for removals
+// that matched nothing.
+func (p *GatepostPolicy) Apply(o *GatepostPolicyOverlay) []string {
+	var warnings []string
+	if o.Defaults.UnknownAction != "" {
+		p.Defaults.UnknownAction = o.Defaults.UnknownAction
+	}
+	if o.Defaults.FailMode != "" {
+		p.Defaults.FailMode = o.Defaults.FailMode
+	}
+	for _, name := range sortedKeys(o.Phases) {
+		edit := o.Phases[name]
+		phase := p.Phases[name]
+		if phase == nil {
+			phase = &GatepostPolicyPhase{}
+			if p.Phases == nil {
+				p.Phases = make(map[string]*GatepostPolicyPhase)
+			}
+			p.Phases[name] = phase
+		}
+		for _, host := range edit.Remove {
+			kept := phase.Allow[:0]
+			for _, r := range phase.Allow {
+				if r.Host != host {
+					kept = append(kept, r)
+				}
+			}
+			if len(kept) == len(phase.Allow) {
+				warnings = append(warnings, fmt.Sprintf("phases.%s.remove: %s is not in the allow list", name, host))
+			}
+			phase.Allow = kept
+		}
+		for _, host := range edit.Add {
+			if !phase.allows(host) {
+				phase.Allow = append(phase.Allow, GatepostPolicyRule{Host: host})
+			}
+		}
+		if edit.UnknownAction != "" {
+			phase.UnknownAction = edit.UnknownAction
+		}
+	}
+	return warnings
+}
+
+func (ph *GatepostPolicyPhase) allows(host string) bool {
+	for _, r := range ph.Allow {
+		if r.Host == host {
+			return true
+		}
+	}
+	return false
+}
+
+// Decide applies the policy to a request for host in phase without the
+// Gatepost evaluator: allow-listed hosts pass, and other hosts follow the
+// phase's unknown_action, then the default one. "smart" needs Gatepost and is
+// treated as deny.
+func (p *GatepostPolicy) Decide(phase, host string) (bool, string) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	ph := p.Phases[phase]
+	action := p.Defaults.UnknownAction
+	if ph != nil {
+		for _, r := range ph.Allow {
+			if matchGatepostHost(r.Host, host) {
+				return true, "allow rule " + r.Host
+			}
+		}
+		if ph.UnknownAction != "" {
+			action = ph.UnknownAction
+		}
+	}
+	switch action {
+	case "allow":
+		return true, "unknown host allowed in " + phase + " phase"
+	case "smart":
+		return false, "not in the " + phase + " allow list (smart evaluation needs Gatepost)"
+	}
+	return false, "not in the " + phase + " allow list"
+}
+
+// EffectiveGatepostPolicy merges the global and project overlays onto the
+// default policy. Warnings describe removals that matched nothing.
+func EffectiveGatepostPolicy(projectPath string) (*GatepostPolicy, []string, error) {
+	policy := DefaultGatepostPolicy()
+	var warnings []string
+	for _, path := range GatepostPolicyPaths(projectPath) {
+		o, err := LoadGatepostPolicyOverlay(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if o == nil {
+			continue
+		}
+		for _, w := range policy.Apply(o) {
+			warnings = append(warnings, path+": "+w)
+		}
+	}
+	return policy, warnings, nil
+}
+
+// Marshal renders the policy as the proxy's YAML.
+func (p *GatepostPolicy) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeGatepostPolicy writes the session's policy file; an empty policy
+// writes the default.
+func writeGatepostPolicy(path string, policy []byte) error {
+	if len(policy) == 0 {
+		policy = []byte(defaultGatepostPolicy)
+	}
+	return os.WriteFile(path, policy, 0o600)
+}
+
+// GatepostPolicyPath is the policy file the session's proxy reads.
+func GatepostPolicyPath(meta session.TargetMeta) (string, error) {
+	if meta.Type != "gatepost" || !meta.Gatepost.Enabled {
+		return "", fmt.Errorf("not a gatepost target")
+	}
+	if meta.Gatepost.ConfigDir == "" {
+		return "", fmt.Errorf("gatepost runtime metadata is incomplete")
+	}
+	return filepath.Join(meta.Gatepost.ConfigDir, "policy.gatepost.yaml"), nil
+}
+
+// ReloadGatepostPolicy replaces a running session's policy and tells the
+// proxy to re-read it through the control API, falling back to SIGHUP for
+// proxies without the reload endpoint.
+func ReloadGatepostPolicy(ctx context.Context, meta session.TargetMeta, policy []byte) error {
+	path, err := GatepostPolicyPath(meta)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, policy, 0o600); err != nil {
+		return err
+	}
+	// The config dir is bind-mounted, so the rename is visible in the proxy.
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	if meta.Gatepost.ControlURL != "" {
+		status, err := gatepostControlPost(ctx, meta, "/policy/reload", nil)
+		if err == nil {
+			switch {
+			case status >= 200 && status < 300:
+				return nil
+			case status != http.StatusNotFound:
+				return fmt.Errorf("gatepost policy reload: status %d", status)
+			}
+		}
+	}
+	if meta.Gatepost.ProxyContainerName == "" {
+		return fmt.Errorf("gatepost policy reload: proxy container unknown")
+	}
+	if err := dockerRun(ctx, "kill", "--signal", "HUP", meta.Gatepost.ProxyContainerName); err != nil {
+		return fmt.Errorf("gatepost policy reload: %w", err)
+	}
+	return nil
+}
+
+// gatepostControlPost sends an authenticated POST to the session's control
+// API and returns the response status.
+func gatepostControlPost(ctx context.Context, meta session.TargetMeta, path string, body any) (int, error) {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		payload = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.Gatepost.ControlURL+path, payload)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	token, _ := os.ReadFile(filepath.Join(meta.Gatepost.ConfigDir, "control.token"))
+	if t := strings.TrimSpace(string(token)); t != "" {
+		req.Header.Set("Authorization", "Bearer "+t)
+	}
+	resp, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
@@ -2,8 +2,11 @@ package target
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestSanitizeRoleSegment(t *testing.T) {
@@ -25,7 +28,7 @@ func TestSanitizeRoleSegment(t *testing.T) {
 
 func TestWriteGatepostPolicyIncludesProvidersAndSmart(t *testing.T) {
 	path := t.TempDir() + "/policy.gatepost.yaml"
-	if err := writeGatepostPolicy(path); err != nil {
+	if err := writeGatepostPolicy(path, nil); err != nil {
 		t.Fatal(err)
 	}
 	data, err := os.ReadFile(path)
@@ -39,3 +42,117 @@ func TestWriteGatepostPolicyIncludesProvidersAndSmart(t *testing.T) {
 		}
 	}
 }
+
+func TestGatepostPolicyOverlayMerge(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(home, ".config", "devx", GatepostPolicyFile), `version: 1
+phases:
+  run:
+    add: [npm.internal.example.com]
+    remove: [r.jina.ai]
+`)
+	write(filepath.Join(project, ".devx", GatepostPolicyFile), `version: 1
+defaults:
+  fail_mode: open
+phases:
+  run:
+    add: ["*.corp.example.com", npm.internal.example.com]
+    remove: [r.jina.ai, api.search.brave.com]
+    unknown_action: deny
+`)
+	policy, warnings, err := EffectiveGatepostPolicy(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := policy.Phases["run"]
+	if !run.allows("npm.internal.example.com") || !run.allows("*.corp.example.com") {
+		t.Errorf("added hosts missing: %+v", run.Allow)
+	}
+	if run.allows("r.jina.ai") || run.allows("api.search.brave.com") {
+		t.Errorf("removed hosts still allowed: %+v", run.Allow)
+	}
+	n := 0
+	for _, r := range run.Allow {
+		if r.Host == "npm.internal.example.com" {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("host added twice should appear once, got %d", n)
+	}
+	if run.UnknownAction != "deny" || policy.Defaults.FailMode != "open" || policy.Defaults.UnknownAction != "smart" {
+		t.Errorf("actions = %q / %+v", run.UnknownAction, policy.Defaults)
+	}
+	if !policy.Phases["install"].allows("pypi.org") || policy.Phases["install"].allows("npm.internal.example.com") {
+		t.Error("install phase should be untouched")
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "r.jina.ai") {
+		t.Errorf("warnings = %v", warnings)
+	}
+
+	data, err := policy.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reparsed GatepostPolicy
+	if err := yaml.Unmarshal(data, &reparsed); err != nil {
+		t.Fatal(err)
+	}
+	if !reparsed.Phases["run"].allows("*.corp.example.com") {
+		t.Errorf("marshaled policy lost rules:\n%s", data)
+	}
+}
+
+func TestGatepostPolicyOverlayValidation(t *testing.T) {
+	for name, content := range map[string]string{
+		"missing version": "phases: {}\n",
+		"unknown field":   "version: 1\nphases:\n  run:\n    allow: [x.com]\n",
+		"unknown phase":   "version: 1\nphases:\n  build:\n    add: [x.com]\n",
+		"bad host":        "version: 1\nphases:\n  run:\n    add: [\"https://x.com/path\"]\n",
+		"bad action":      "version: 1\nphases:\n  run:\n    unknown_action: maybe\n",
+		"bad fail mode":   "version: 1\ndefaults:\n  fail_mode: sometimes\n",
+	} {
+		if _, err := ParseGatepostPolicyOverlay([]byte(content)); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+	if _, err := ParseGatepostPolicyOverlay([]byte("version: 1\nphases:\n  install:\n    add: [\"*.pkg.dev\"]\n")); err != nil {
+		t.Errorf("valid overlay rejected: %v", err)
+	}
+}
+
+func TestGatepostPolicyDecide(t *testing.T) {
+	policy := DefaultGatepostPolicy()
+	policy.Phases["run"].UnknownAction = "deny"
+	for _, tc := range []struct {
+		phase, host string
+		want        bool
+	}{
+		{"run", "api.github.com", true},
+		{"run", "registry.npmjs.org", true},
+		{"run", "npmjs.org", false},
+		{"run", "API.GITHUB.COM.", true},
+		{"run", "example.com", false},
+		{"install", "example.com", true},
+		{"missing", "example.com", false},
+	} {
+		if got, reason := policy.Decide(tc.phase, tc.host); got != tc.want {
+			t.Errorf("Decide(%q, %q) = %v (%s), want %v", tc.phase, tc.host, got, reason, tc.want)
+		}
+	}
+	policy.Phases["run"].UnknownAction = "smart"
+	if ok, _ := policy.Decide("run", "example.com"); ok {
+		t.Error("smart should deny without Gatepost")
+	}
+}
@@ -2,13 +2,308 @@ package target
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/jfox85/devx/session"
 )
 
+// GatepostSecretSpec is a gatepost.secrets entry: a credential read on the
+// host and injected by the proxy into requests to Host, so the agent never
+// sees the value. Exactly one of Env, File and Command supplies the value.
+type GatepostSecretSpec struct {
+	Name     string
+	Env      string   // host environment variable
+	File     string   // file whose trimmed contents are the value
+	Command  string   // shell command whose trimmed stdout is the value
+	Host     string   // destination host pattern, e.g. api.example.com or *.example.com
+	Scheme   string   // bearer (default), header or basic
+	Header   string   // header to set; Authorization for bearer and basic
+	Username string   // basic auth user
+	Projects []string // only sessions of these project aliases; all when empty
+	Presets  []string // only sessions using these security presets; all when empty
+}
+
+// GatepostSecretSchemes are the ways the proxy can present a secret.
+var GatepostSecretSchemes = []string{"bearer", "header", "basic"}
+
+// Source describes where the value comes from without revealing it.
+func (s GatepostSecretSpec) Source() string {
+	switch {
+	case s.Env != "":
+		return "env " + s.Env
+	case s.File != "":
+		return "file " + s.File
+	case s.Command != "":
+		return "command"
+	}
+	return ""
+}
+
+// AppliesTo reports whether the secret is scoped to a session of the given
+// project alias and security preset.
+func (s GatepostSecretSpec) AppliesTo(project, preset string) bool {
+	if len(s.Projects) > 0 && !containsFold(s.Projects, project) {
+		return false
+	}
+	if len(s.Presets) > 0 && !containsFold(s.Presets, preset) {
+		return false
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateGatepostSecrets checks gatepost.secrets entries and fills in the
+// default scheme and header.
+func ValidateGatepostSecrets(specs []GatepostSecretSpec) error {
+	seen := map[string]bool{}
+	for i := range specs {
+		s := &specs[i]
+		if s.Name == "" {
+			return fmt.Errorf("secret %d has no name", i+1)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("secret %q is defined twice", s.Name)
+		}
+		seen[s.Name] = true
+		sources := 0
+		for _, v := range []string{s.Env, s.File, s.Command} {
+			if v != "" {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return fmt.Errorf("secret %q needs exactly one of env, file or command", s.Name)
+		}
+		if !gatepostHostRe.MatchString(s.Host) {
+			return fmt.Errorf("secret %q has an invalid host %q", s.Name, s.Host)
+		}
+		if s.Scheme == "" {
+			s.Scheme = "bearer"
+		}
+		if !containsString(GatepostSecretSchemes, s.Scheme) {
+			return fmt.Errorf("secret %q has unknown scheme %q (valid: %s)", s.Name, s.Scheme, strings.Join(GatepostSecretSchemes, ", "))
+		}
+		if s.Header == "" {
+			if s.Scheme == "header" {
+				return fmt.Errorf("secret %q uses the header scheme but sets no header", s.Name)
+			}
+			s.Header = "Authorization"
+		}
+	}
+	return nil
+}
+
+// SelectGatepostSecrets returns the secrets scoped to a session of the given
+// project alias and security preset.
+func SelectGatepostSecrets(specs []GatepostSecretSpec, project, preset string) []GatepostSecretSpec {
+	var out []GatepostSecretSpec
+	for _, s := range specs {
+		if s.AppliesTo(project, preset) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// resolveGatepostSecret reads the current value of a configured secret. An
+// empty value (e.g. an unset variable) is not an error; the secret is skipped.
+func resolveGatepostSecret(ctx context.Context, spec GatepostSecretSpec) (gatepostSecret, error) {
+	secret := gatepostSecret{Name: spec.Name, Host: spec.Host, Scheme: spec.Scheme, Header: spec.Header, Username: spec.Username}
+	switch {
+	case spec.Env != "":
+		secret.Value = os.Getenv(spec.Env)
+	case spec.File != "":
+		data, err := os.ReadFile(spec.File)
+		if err != nil {
+			return secret, fmt.Errorf("secret %s: %w", spec.Name, err)
+		}
+		secret.Value = string(data)
+	case spec.Command != "":
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "sh", "-c", spec.Command).Output()
+		if err != nil {
+			// stderr may echo the secret; report only the exit status.
+			return secret, fmt.Errorf("secret %s: command failed: %v", spec.Name, err)
+		}
+		secret.Value = string(out)
+	}
+	secret.Value = strings.TrimSpace(secret.Value)
+	return secret, nil
+}
+
+// registerConfiguredGatepostSecrets reads and registers each configured
+// secret. Secrets that cannot be read become warnings so one broken source
+// does not block the session; a proxy that rejects a secret is an error.
+func registerConfiguredGatepostSecrets(ctx context.Context, specs []GatepostSecretSpec, controlURL, token string) (registered, warnings []string, err error) {
+	for _, spec := range specs {
+		secret, readErr := resolveGatepostSecret(ctx, spec)
+		if readErr != nil {
+			warnings = append(warnings, readErr.Error())
+			continue
+		}
+		if secret.Value == "" {
+			continue
+		}
+		if err := postGatepostSecret(controlURL, token, secret); err != nil {
+			return registered, warnings, err
+		}
+		gatepostSecretDigests.remember(controlURL, secret)
+		registered = append(registered, secret.Name)
+	}
+	return registered, warnings, nil
+}
+
+// gatepostSecretDigests remembers a digest of the last value registered with
+// each proxy so rotation re-registers only secrets whose value changed.
+var gatepostSecretDigests = &secretDigests{seen: map[string]string{}, refreshed: map[string]time.Time{}}
+
+type secretDigests struct {
+	mu        sync.Mutex
+	seen      map[string]string
+	refreshed map[string]time.Time
+}
+
+func secretDigestKey(controlURL, name string) string { return controlURL + "\x00" + name }
+
+func secretDigest(secret gatepostSecret) string {
+	sum := sha256.Sum256([]byte(secret.Host + "\x00" + secret.Scheme + "\x00" + secret.Header + "\x00" + secret.Username + "\x00" + secret.Value))
+	return hex.EncodeToString(sum[:])
+}
+
+func (d *secretDigests) remember(controlURL string, secret gatepostSecret) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.seen[secretDigestKey(controlURL, secret.Name)] = secretDigest(secret)
+}
+
+func (d *secretDigests) changed(controlURL string, secret gatepostSecret) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.seen[secretDigestKey(controlURL, secret.Name)] != secretDigest(secret)
+}
+
+// due reports whether controlURL's secrets were last refreshed more than
+// interval ago, and if so marks them refreshed now.
+func (d *secretDigests) due(controlURL string, interval time.Duration, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if last, ok := d.refreshed[controlURL]; ok && now.Sub(last) < interval {
+		return false
+	}
+	d.refreshed[controlURL] = now
+	return true
+}
+
+// RefreshGatepostSecrets re-reads the session's configured secrets and
+// registers those whose value changed since they were last registered, or all
+// of them when force is set. It returns the names it registered.
+func RefreshGatepostSecrets(ctx context.Context, meta session.TargetMeta, specs []GatepostSecretSpec, force bool) ([]string, error) {
+	if meta.Type != "gatepost" || !meta.Gatepost.Enabled {
+		return nil, fmt.Errorf("not a gatepost target")
+	}
+	if meta.Gatepost.ControlURL == "" || meta.Gatepost.ConfigDir == "" {
+		return nil, fmt.Errorf("gatepost runtime metadata is incomplete")
+	}
+	token, _ := os.ReadFile(filepath.Join(meta.Gatepost.ConfigDir, "control.token"))
+	controlURL := meta.Gatepost.ControlURL
+	var registered []string
+	var errs []string
+	for _, spec := range sessionGatepostSecrets(meta.Gatepost, specs) {
+		secret, err := resolveGatepostSecret(ctx, spec)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if secret.Value == "" || (!force && !gatepostSecretDigests.changed(controlURL, secret)) {
+			continue
+		}
+		if err := postGatepostSecret(controlURL, strings.TrimSpace(string(token)), secret); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		gatepostSecretDigests.remember(controlURL, secret)
+		registered = append(registered, secret.Name)
+	}
+	if len(errs) > 0 {
+		return registered, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return registered, nil
+}
+
+// sessionGatepostSecrets narrows the configured secrets to those selected
+// for the session when it started.
+func sessionGatepostSecrets(gp session.GatepostMeta, specs []GatepostSecretSpec) []GatepostSecretSpec {
+	var out []GatepostSecretSpec
+	for _, s := range specs {
+		if containsString(gp.ConfiguredSecrets, s.Name) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func gatepostSecretNames(specs []GatepostSecretSpec) []string {
+	var names []string
+	for _, s := range specs {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// ListGatepostSecrets returns the names of the secrets registered with the
+// session's proxy. The proxy never returns values.
+func ListGatepostSecrets(ctx context.Context, meta session.TargetMeta) ([]string, error) {
+	if meta.Type != "gatepost" || !meta.Gatepost.Enabled {
+		return nil, fmt.Errorf("not a gatepost target")
+	}
+	if meta.Gatepost.ControlURL == "" {
+		return nil, fmt.Errorf("gatepost runtime metadata is incomplete")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, meta.Gatepost.ControlURL+"/secrets", nil)
+	if err != nil {
+		return nil, err
+	}
+	token, _ := os.ReadFile(filepath.Join(meta.Gatepost.ConfigDir, "control.token"))
+	if t := strings.TrimSpace(string(token)); t != "" {
+		req.Header.Set("Authorization", "Bearer "+t)
+	}
+	resp, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gatepost secrets: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("gatepost secrets: status %d", resp.StatusCode)
+	}
+	var body struct {
+		Secrets []string `json:"secrets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("gatepost secrets: %w", err)
+	}
+	return body.Secrets, nil
+}
+
 type gatepostSecret struct {
 	Name     string `json:"name"`
 	Host     string `json:"host"`
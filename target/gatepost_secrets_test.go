@@ -1,10 +1,15 @@
 package target
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/jfox85/devx/session"
 )
 
 func TestRegisterGatepostSecretsFromHostEnv(t *testing.T) {
@@ -38,3 +43,107 @@ func TestRegisterGatepostSecretsFromHostEnv(t *testing.T) {
 		t.Fatalf("expected secret values")
 	}
 }
+
+func TestValidateGatepostSecrets(t *testing.T) {
+	specs := []GatepostSecretSpec{
+		{Name: "gh", Env: "GH_TOKEN", Host: "api.github.com"},
+		{Name: "internal", File: "/tmp/key", Host: "*.corp.example.com", Scheme: "header", Header: "X-Api-Key"},
+	}
+	if err := ValidateGatepostSecrets(specs); err != nil {
+		t.Fatal(err)
+	}
+	if specs[0].Scheme != "bearer" || specs[0].Header != "Authorization" {
+		t.Fatalf("expected bearer defaults, got %#v", specs[0])
+	}
+	bad := map[string]GatepostSecretSpec{
+		"no source":     {Name: "a", Host: "a.example.com"},
+		"two sources":   {Name: "a", Env: "A", Command: "echo a", Host: "a.example.com"},
+		"bad host":      {Name: "a", Env: "A", Host: "https://a.example.com"},
+		"bad scheme":    {Name: "a", Env: "A", Host: "a.example.com", Scheme: "digest"},
+		"header scheme": {Name: "a", Env: "A", Host: "a.example.com", Scheme: "header"},
+	}
+	for name, spec := range bad {
+		if err := ValidateGatepostSecrets([]GatepostSecretSpec{spec}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	dup := []GatepostSecretSpec{{Name: "a", Env: "A", Host: "a.example.com"}, {Name: "a", Env: "B", Host: "b.example.com"}}
+	if err := ValidateGatepostSecrets(dup); err == nil {
+		t.Error("expected duplicate names to be rejected")
+	}
+}
+
+func TestSelectGatepostSecretsByProjectAndPreset(t *testing.T) {
+	specs := []GatepostSecretSpec{
+		{Name: "everywhere"},
+		{Name: "web-only", Projects: []string{"Web"}},
+		{Name: "strict-only", Presets: []string{"strict"}},
+	}
+	got := gatepostSecretNames(SelectGatepostSecrets(specs, "web", ""))
+	if len(got) != 2 || got[0] != "everywhere" || got[1] != "web-only" {
+		t.Fatalf("unexpected selection for web: %#v", got)
+	}
+	got = gatepostSecretNames(SelectGatepostSecrets(specs, "api", "strict"))
+	if len(got) != 2 || got[0] != "everywhere" || got[1] != "strict-only" {
+		t.Fatalf("unexpected selection for api/strict: %#v", got)
+	}
+}
+
+func TestConfiguredGatepostSecretsRegisterAndRotate(t *testing.T) {
+	var posted []gatepostSecret
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var secret gatepostSecret
+		if err := json.NewDecoder(r.Body).Decode(&secret); err != nil {
+			t.Fatal(err)
+		}
+		posted = append(posted, secret)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte("file-value\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DEVX_TEST_SECRET", "env-value")
+	specs := []GatepostSecretSpec{
+		{Name: "from-env", Env: "DEVX_TEST_SECRET", Host: "a.example.com"},
+		{Name: "from-file", File: keyFile, Host: "b.example.com", Scheme: "basic", Username: "ci"},
+		{Name: "from-command", Command: "printf command-value", Host: "c.example.com", Scheme: "header", Header: "X-Key"},
+		{Name: "unset", Env: "DEVX_TEST_SECRET_UNSET", Host: "d.example.com"},
+		{Name: "missing-file", File: filepath.Join(dir, "missing"), Host: "e.example.com"},
+	}
+	if err := ValidateGatepostSecrets(specs); err != nil {
+		t.Fatal(err)
+	}
+	registered, warnings, err := registerConfiguredGatepostSecrets(context.Background(), specs, server.URL, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registered) != 3 || len(warnings) != 1 {
+		t.Fatalf("registered %#v, warnings %#v", registered, warnings)
+	}
+	values := map[string]string{}
+	for _, s := range posted {
+		values[s.Name] = s.Value
+	}
+	if values["from-env"] != "env-value" || values["from-file"] != "file-value" || values["from-command"] != "command-value" {
+		t.Fatalf("unexpected values: %#v", values)
+	}
+
+	// Only the rotated secret is registered again.
+	configDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(configDir, "control.token"), []byte("token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	meta := session.TargetMeta{Type: "gatepost", Gatepost: session.GatepostMeta{Enabled: true, ControlURL: server.URL, ConfigDir: configDir, ConfiguredSecrets: []string{"from-env", "from-file", "from-command"}}}
+	t.Setenv("DEVX_TEST_SECRET", "rotated")
+	posted = nil
+	rotated, err := RefreshGatepostSecrets(context.Background(), meta, specs, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 1 || rotated[0] != "from-env" || len(posted) != 1 || posted[0].Value != "rotated" {
+		t.Fatalf("expected only from-env to rotate, got %#v", rotated)
+	}
+}
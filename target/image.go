@@ -0,0 +1,618 @@
+package target
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jfox85/devx/caddy"
+)
+
+// Image labels DevX puts on the images it builds, so they can be listed and
+// pruned without touching anything else in the local image store.
+const (
+	ImageLabelKind    = "devx.image"         // "project" or "devcontainer"
+	ImageLabelProject = "devx.image.project" // project the image was built for
+	ImageLabelHash    = "devx.image.hash"    // hash of the build inputs
+)
+
+// ImageBuildSpec describes a project's session image build. Paths are
+// absolute; Dockerfile and ContextDir must be inside Root.
+type ImageBuildSpec struct {
+	Project    string
+	Root       string // checkout the image is built from
+	ContextDir string
+	Dockerfile string
+	Args       map[string]string
+}
+
+// DevxImage is an image built by DevX.
+type DevxImage struct {
+	Ref          string `json:"ref"`
+	ID           string `json:"id"`
+	Kind         string `json:"kind"`
+	Project      string `json:"project,omitempty"`
+	Hash         string `json:"hash,omitempty"`
+	Size         string `json:"size"`
+	CreatedSince string `json:"created_since"`
+}
+
+// NewImageBuildSpec resolves a project's build settings against a checkout.
+// contextDir defaults to the checkout root and dockerfile to
+// <context>/Dockerfile; both are relative to root.
+func NewImageBuildSpec(project, root, contextDir, dockerfile string, args map[string]string) (ImageBuildSpec, error) {
+	spec := ImageBuildSpec{Project: project, Root: root, Args: args}
+	if contextDir == "" {
+		contextDir = "."
+	}
+	var err error
+	if spec.ContextDir, err = pathInsideRoot(root, contextDir); err != nil {
+		return spec, fmt.Errorf("docker.build.context: %w", err)
+	}
+	if dockerfile == "" {
+		spec.Dockerfile = filepath.Join(spec.ContextDir, "Dockerfile")
+	} else if spec.Dockerfile, err = pathInsideRoot(root, dockerfile); err != nil {
+		return spec, fmt.Errorf("docker.build.dockerfile: %w", err)
+	}
+	return spec, nil
+}
+
+func pathInsideRoot(root, rel string) (string, error) {
+	if filepath.IsAbs(rel) {
+		return "", fmt.Errorf("path must be relative to the project: %s", rel)
+	}
+	path := filepath.Join(root, rel)
+	if r, err := filepath.Rel(root, path); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path must be inside the project: %s", rel)
+	}
+	return path, nil
+}
+
+// ProjectImageRepo is the repository a project's session images are tagged in.
+func ProjectImageRepo(project string) string {
+	return "devx-" + caddy.SanitizeHostname(project)
+}
+
+// ProjectImageTag is the content-addressed tag for a project image.
+func ProjectImageTag(project, hash string) string {
+	if len(hash) > 16 {
+		hash = hash[:16]
+	}
+	return ProjectImageRepo(project) + ":" + hash
+}
+
+// ImageInputsHash hashes everything that determines a project image: the
+// Dockerfile, build args and every file in the build context not excluded by
+// .dockerignore. In a git checkout only the files committed at HEAD count, so
+// uncommitted edits and files devx generates in session worktrees leave the
+// hash alone; other checkouts are hashed from disk.
+func ImageInputsHash(spec ImageBuildSpec) (string, error) {
+	h := sha256.New()
+	var err error
+	if isGitCheckout(spec.Root) {
+		err = hashCommittedInputs(h, spec)
+	} else {
+		err = hashWorkingInputs(h, spec)
+	}
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashBuildArgs(w io.Writer, args map[string]string) {
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "arg %q=%q\n", k, args[k])
+	}
+}
+
+// hashCommittedInputs hashes the Dockerfile and build context from the HEAD
+// tree by object id, without reading the files themselves.
+func hashCommittedInputs(w io.Writer, spec ImageBuildSpec) error {
+	contextRel, dockerfileRel, err := spec.relPaths()
+	if err != nil {
+		return err
+	}
+	dockerfile, err := gitTreeEntries(spec.Root, dockerfileRel)
+	if err != nil {
+		return err
+	}
+	if len(dockerfile) != 1 || dockerfile[0].path != dockerfileRel {
+		return fmt.Errorf("Dockerfile %s is not committed", dockerfileRel)
+	}
+	fmt.Fprintf(w, "dockerfile %s\n", dockerfile[0].oid)
+	hashBuildArgs(w, spec.Args)
+
+	entries, err := gitTreeEntries(spec.Root, contextRel)
+	if err != nil {
+		return err
+	}
+	prefix := ""
+	if contextRel != "." {
+		prefix = contextRel + "/"
+	}
+	ignore := &dockerignore{}
+	for _, e := range entries {
+		if e.path == prefix+".dockerignore" {
+			data, err := gitOutput(spec.Root, "cat-file", "blob", e.oid)
+			if err != nil {
+				return fmt.Errorf("read .dockerignore: %w", err)
+			}
+			ignore = parseDockerignore(data)
+		}
+	}
+	for _, e := range entries {
+		rel := strings.TrimPrefix(e.path, prefix)
+		if ignore.excludes(rel) {
+			continue
+		}
+		fmt.Fprintf(w, "entry %s %s %q\n", e.mode, e.oid, rel)
+	}
+	return nil
+}
+
+// hashWorkingInputs hashes the Dockerfile and build context as they are on
+// disk.
+func hashWorkingInputs(w io.Writer, spec ImageBuildSpec) error {
+	dockerfile, err := os.ReadFile(spec.Dockerfile)
+	if err != nil {
+		return fmt.Errorf("read Dockerfile: %w", err)
+	}
+	fmt.Fprintf(w, "dockerfile %d\n", len(dockerfile))
+	w.Write(dockerfile)
+	hashBuildArgs(w, spec.Args)
+
+	ignore, err := loadDockerignore(spec.ContextDir)
+	if err != nil {
+		return err
+	}
+	err = filepath.WalkDir(spec.ContextDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(spec.ContextDir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" || ignore.excludesDir(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignore.excludes(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "link %q %q\n", rel, target)
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		fmt.Fprintf(w, "file %q %o %d\n", rel, info.Mode().Perm()&0o111, info.Size())
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("hash build context: %w", err)
+	}
+	return nil
+}
+
+// EnsureProjectImage returns the tag for the project's current build inputs,
+// building the image when no image with that tag exists. In a git checkout
+// the image is built from the committed inputs the tag was hashed from.
+// built reports whether a build ran.
+func EnsureProjectImage(ctx context.Context, spec ImageBuildSpec) (tag string, built bool, err error) {
+	hash, err := ImageInputsHash(spec)
+	if err != nil {
+		return "", false, err
+	}
+	tag = ProjectImageTag(spec.Project, hash)
+	if ImageExists(tag) {
+		return tag, false, nil
+	}
+	if isGitCheckout(spec.Root) {
+		exported, cleanup, err := exportCommittedInputs(ctx, spec)
+		if err != nil {
+			return "", false, err
+		}
+		defer cleanup()
+		spec = exported
+	}
+	args := []string{"-f", spec.Dockerfile,
+		"--label", ImageLabelKind + "=project",
+		"--label", ImageLabelProject + "=" + spec.Project,
+		"--label", ImageLabelHash + "=" + hash,
+	}
+	keys := make([]string, 0, len(spec.Args))
+	for k := range spec.Args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--build-arg", k+"="+spec.Args[k])
+	}
+	if err := BuildImage(ctx, tag, spec.ContextDir, args...); err != nil {
+		return "", false, fmt.Errorf("build %s: %w", tag, err)
+	}
+	return tag, true, nil
+}
+
+// relPaths returns the build context and Dockerfile relative to the
+// checkout, slash-separated.
+func (spec ImageBuildSpec) relPaths() (contextRel, dockerfileRel string, err error) {
+	if contextRel, err = filepath.Rel(spec.Root, spec.ContextDir); err != nil {
+		return "", "", err
+	}
+	if dockerfileRel, err = filepath.Rel(spec.Root, spec.Dockerfile); err != nil {
+		return "", "", err
+	}
+	return filepath.ToSlash(contextRel), filepath.ToSlash(dockerfileRel), nil
+}
+
+// exportCommittedInputs writes the committed build context and Dockerfile of
+// spec to a temporary directory and returns spec rebased onto it.
+func exportCommittedInputs(ctx context.Context, spec ImageBuildSpec) (ImageBuildSpec, func(), error) {
+	contextRel, dockerfileRel, err := spec.relPaths()
+	if err != nil {
+		return spec, nil, err
+	}
+	dir, err := os.MkdirTemp("", "devx-image-*")
+	if err != nil {
+		return spec, nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	cmd := exec.CommandContext(ctx, "git", "-C", spec.Root, "archive", "--format=tar", "HEAD", "--", contextRel, dockerfileRel)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		cleanup()
+		return spec, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		cleanup()
+		return spec, nil, err
+	}
+	extractErr := extractTar(out, dir)
+	if extractErr != nil {
+		io.Copy(io.Discard, out)
+	}
+	if err := cmd.Wait(); err != nil {
+		cleanup()
+		return spec, nil, fmt.Errorf("git archive: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if extractErr != nil {
+		cleanup()
+		return spec, nil, fmt.Errorf("export build context: %w", extractErr)
+	}
+	exported := spec
+	exported.Root = dir
+	exported.ContextDir = filepath.Join(dir, filepath.FromSlash(contextRel))
+	exported.Dockerfile = filepath.Join(dir, filepath.FromSlash(dockerfileRel))
+	return exported, cleanup, nil
+}
+
+// extractTar unpacks the directories, files and symlinks of a tar stream
+// into dir.
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(strings.TrimSuffix(hdr.Name, "/"))
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("unsafe path %q in archive", hdr.Name)
+		}
+		path := filepath.Join(dir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0o755)
+		case tar.TypeReg:
+			err = extractTarFile(tr, path, fs.FileMode(hdr.Mode).Perm())
+		case tar.TypeSymlink:
+			if err = os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+				err = os.Symlink(hdr.Linkname, path)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractTarFile(r io.Reader, path string, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// gitTreeEntry is one blob, symlink or submodule of a git tree listing.
+type gitTreeEntry struct {
+	mode, oid, path string
+}
+
+// gitTreeEntries lists the entries under path in the HEAD tree of a
+// checkout, recursively and sorted by path.
+func gitTreeEntries(root, path string) ([]gitTreeEntry, error) {
+	out, err := gitOutput(root, "ls-tree", "-r", "-z", "HEAD", "--", path)
+	if err != nil {
+		return nil, fmt.Errorf("list committed build inputs: %w", err)
+	}
+	var entries []gitTreeEntry
+	for _, line := range strings.Split(string(out), "\x00") {
+		meta, p, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected git ls-tree output %q", line)
+		}
+		entries = append(entries, gitTreeEntry{mode: fields[0], oid: fields[2], path: p})
+	}
+	return entries, nil
+}
+
+// isGitCheckout reports whether dir is a git checkout with a commit.
+func isGitCheckout(dir string) bool {
+	return exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", "HEAD").Run() == nil
+}
+
+func gitOutput(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// ListDevxImages returns every image carrying the devx.image label.
+func ListDevxImages(ctx context.Context) ([]DevxImage, error) {
+	out, err := dockerOutput(ctx, "image", "ls", "--filter", "label="+ImageLabelKind, "--format", "{{json .}}")
+	if err != nil {
+		return nil, fmt.Errorf("docker image ls: %w", err)
+	}
+	images, err := parseImageList(out)
+	if err != nil || len(images) == 0 {
+		return images, err
+	}
+	// image ls has no labels column; inspect prints one line per ID, in order.
+	var ids []string
+	seen := make(map[string]bool)
+	for _, img := range images {
+		if !seen[img.ID] {
+			seen[img.ID] = true
+			ids = append(ids, img.ID)
+		}
+	}
+	out, err = dockerOutput(ctx, append([]string{"image", "inspect", "--format", "{{json .Config.Labels}}"}, ids...)...)
+	if err != nil {
+		return nil, fmt.Errorf("docker image inspect: %w", err)
+	}
+	labels, err := parseImageLabels(ids, out)
+	if err != nil {
+		return nil, err
+	}
+	for i := range images {
+		l := labels[images[i].ID]
+		images[i].Kind = l[ImageLabelKind]
+		images[i].Project = l[ImageLabelProject]
+		images[i].Hash = l[ImageLabelHash]
+	}
+	return images, nil
+}
+
+func parseImageList(out string) ([]DevxImage, error) {
+	var images []DevxImage
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry struct {
+			Repository   string
+			Tag          string
+			ID           string
+			Size         string
+			CreatedSince string
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("parse docker image ls output: %w", err)
+		}
+		ref := entry.Repository + ":" + entry.Tag
+		if entry.Repository == "<none>" || entry.Tag == "<none>" {
+			ref = entry.ID
+		}
+		images = append(images, DevxImage{
+			Ref:          ref,
+			ID:           entry.ID,
+			Size:         entry.Size,
+			CreatedSince: entry.CreatedSince,
+		})
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Ref < images[j].Ref })
+	return images, scanner.Err()
+}
+
+// parseImageLabels maps each of ids to its labels from the output of
+// `docker image inspect --format '{{json .Config.Labels}}' ids...`.
+func parseImageLabels(ids []string, out string) (map[string]map[string]string, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != len(ids) {
+		return nil, fmt.Errorf("docker image inspect: got %d results for %d images", len(lines), len(ids))
+	}
+	labels := make(map[string]map[string]string, len(ids))
+	for i, line := range lines {
+		var l map[string]string
+		if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &l); err != nil {
+			return nil, fmt.Errorf("parse docker image inspect output: %w", err)
+		}
+		labels[ids[i]] = l
+	}
+	return labels, nil
+}
+
+// RemoveImage deletes a local image by reference.
+func RemoveImage(ctx context.Context, ref string) error {
+	return dockerRun(ctx, "image", "rm", ref)
+}
+
+// dockerignore holds .dockerignore patterns; the last matching pattern wins
+// and "!" patterns re-include paths.
+type dockerignore struct {
+	patterns []string
+	negate   []bool
+}
+
+func loadDockerignore(contextDir string) (*dockerignore, error) {
+	data, err := os.ReadFile(filepath.Join(contextDir, ".dockerignore"))
+	if os.IsNotExist(err) {
+		return &dockerignore{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseDockerignore(data), nil
+}
+
+func parseDockerignore(data []byte) *dockerignore {
+	di := &dockerignore{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		neg := strings.HasPrefix(line, "!")
+		line = strings.TrimPrefix(line, "!")
+		line = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(line)), "/")
+		di.patterns = append(di.patterns, line)
+		di.negate = append(di.negate, neg)
+	}
+	return di
+}
+
+// excludes reports whether a context-relative path is ignored. A pattern that
+// matches a parent directory excludes everything under it.
+func (di *dockerignore) excludes(rel string) bool {
+	excluded := false
+	for i, pattern := range di.patterns {
+		if ignorePatternMatches(pattern, rel) {
+			excluded = !di.negate[i]
+		}
+	}
+	return excluded
+}
+
+// excludesDir reports whether a whole directory can be skipped: it is
+// excluded and no negated pattern could re-include something beneath it.
+func (di *dockerignore) excludesDir(rel string) bool {
+	if !di.excludes(rel) {
+		return false
+	}
+	for i, pattern := range di.patterns {
+		if di.negate[i] && (strings.HasPrefix(pattern, rel+"/") || strings.Contains(pattern, "*")) {
+			return false
+		}
+	}
+	return true
+}
+
+func ignorePatternMatches(pattern, rel string) bool {
+	for p := rel; p != "." && p != "/" && p != ""; p = parentPath(p) {
+		if matchIgnorePattern(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func parentPath(p string) string {
+	i := strings.LastIndex(p, "/")
+	if i < 0 {
+		return ""
+	}
+	return p[:i]
+}
+
+// matchIgnorePattern matches with filepath.Match semantics, plus "**" for any
+// number of directories.
+func matchIgnorePattern(pattern, path string) bool {
+	if !strings.Contains(pattern, "**") {
+		ok, _ := filepath.Match(pattern, path)
+		return ok
+	}
+	pparts := strings.Split(pattern, "/")
+	parts := strings.Split(path, "/")
+	var match func(pi, si int) bool
+	match = func(pi, si int) bool {
+		if pi == len(pparts) {
+			return si == len(parts)
+		}
+		if pparts[pi] == "**" {
+			for k := si; k <= len(parts); k++ {
+				if match(pi+1, k) {
+					return true
+				}
+			}
+			return false
+		}
+		if si == len(parts) {
+			return false
+		}
+		ok, _ := filepath.Match(pparts[pi], parts[si])
+		return ok && match(pi+1, si+1)
+	}
+	return match(0, 0)
+}
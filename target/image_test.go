@@ -0,0 +1,226 @@
+package target
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestImageInputsHashTracksBuildInputs(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"docker/Dockerfile":          "FROM alpine\nCOPY . /src\n",
+		"docker/setup.sh":            "echo setup\n",
+		"docker/.dockerignore":       "cache\n**/*.log\n",
+		"docker/cache/big.bin":       "x",
+		"docker/sub/debug.log":       "x",
+		"app/main.go":                "package main\n",
+		"docker/sub/keep/install.sh": "echo ok\n",
+	})
+	spec, err := NewImageBuildSpec("myapp", root, "docker", "", map[string]string{"GO_VERSION": "1.23"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Dockerfile != filepath.Join(root, "docker", "Dockerfile") {
+		t.Fatalf("default Dockerfile = %s", spec.Dockerfile)
+	}
+	base, err := ImageInputsHash(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rehash := func() string {
+		t.Helper()
+		h, err := ImageInputsHash(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	// Ignored files and files outside the context do not affect the hash.
+	writeFiles(t, root, map[string]string{"docker/cache/big.bin": "y", "docker/sub/debug.log": "y", "app/main.go": "changed"})
+	if got := rehash(); got != base {
+		t.Fatal("ignored or out-of-context changes should not change the hash")
+	}
+
+	writeFiles(t, root, map[string]string{"docker/setup.sh": "echo changed\n"})
+	changed := rehash()
+	if changed == base {
+		t.Fatal("a context file change should change the hash")
+	}
+
+	spec.Args["GO_VERSION"] = "1.24"
+	if rehash() == changed {
+		t.Fatal("a build arg change should change the hash")
+	}
+}
+
+func TestImageInputsHashUsesCommittedInputs(t *testing.T) {
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-C", root, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-b", "main")
+	writeFiles(t, root, map[string]string{
+		"docker/Dockerfile":    "FROM alpine\nCOPY . /src\n",
+		"docker/setup.sh":      "echo setup\n",
+		"docker/.dockerignore": "*.log\n",
+		"docker/debug.log":     "x",
+		"app/main.go":          "package main\n",
+	})
+	git("add", ".")
+	git("commit", "-m", "init")
+	spec, err := NewImageBuildSpec("myapp", root, "docker", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rehash := func() string {
+		t.Helper()
+		h, err := ImageInputsHash(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	base := rehash()
+
+	// Uncommitted edits, generated files and ignored or out-of-context
+	// commits leave the hash alone.
+	writeFiles(t, root, map[string]string{"docker/setup.sh": "echo edited\n", "docker/.envrc": "export X=1\n"})
+	if got := rehash(); got != base {
+		t.Fatal("uncommitted changes should not change the hash")
+	}
+	writeFiles(t, root, map[string]string{"docker/setup.sh": "echo setup\n", "docker/debug.log": "y", "app/main.go": "changed"})
+	git("commit", "-am", "ignored")
+	if got := rehash(); got != base {
+		t.Fatal("ignored or out-of-context commits should not change the hash")
+	}
+
+	writeFiles(t, root, map[string]string{"docker/setup.sh": "echo changed\n", "docker/.envrc": "export X=2\n"})
+	git("commit", "-am", "setup")
+	if rehash() == base {
+		t.Fatal("a committed context change should change the hash")
+	}
+
+	exported, cleanup, err := exportCommittedInputs(context.Background(), spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	if data, err := os.ReadFile(filepath.Join(exported.ContextDir, "setup.sh")); err != nil || string(data) != "echo changed\n" {
+		t.Fatalf("exported setup.sh = %q, %v", data, err)
+	}
+	if _, err := os.Stat(exported.Dockerfile); err != nil {
+		t.Fatalf("exported Dockerfile: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(exported.ContextDir, ".envrc")); !os.IsNotExist(err) {
+		t.Fatalf("untracked .envrc should not be exported: %v", err)
+	}
+}
+
+func TestNewImageBuildSpecRejectsOutsidePaths(t *testing.T) {
+	for _, tc := range []struct{ context, dockerfile string }{
+		{"..", ""},
+		{".", "../Dockerfile"},
+		{"/etc", ""},
+	} {
+		if _, err := NewImageBuildSpec("p", "/src/p", tc.context, tc.dockerfile, nil); err == nil {
+			t.Errorf("context %q dockerfile %q should be rejected", tc.context, tc.dockerfile)
+		}
+	}
+}
+
+func TestProjectImageTag(t *testing.T) {
+	tag := ProjectImageTag("My_App", strings.Repeat("ab", 32))
+	if tag != ProjectImageRepo("My_App")+":abababababababab" {
+		t.Fatalf("tag = %q", tag)
+	}
+	if !strings.HasPrefix(tag, "devx-") {
+		t.Fatalf("tag should use the devx- prefix: %q", tag)
+	}
+}
+
+func TestParseImageList(t *testing.T) {
+	out := `{"Containers":"N/A","CreatedAt":"2026-10-16 09:12:44 +0000 UTC","CreatedSince":"2 days ago","Digest":"\u003cnone\u003e","ID":"3b418d7b466a","Repository":"devx-myapp","SharedSize":"N/A","Size":"1.2GB","Tag":"0123456789abcdef","UniqueSize":"N/A","VirtualSize":"1.2GB"}
+{"Containers":"N/A","CreatedAt":"2026-10-18 08:02:10 +0000 UTC","CreatedSince":"1 hour ago","Digest":"\u003cnone\u003e","ID":"9c0d4a1e2f37","Repository":"devx-devcontainer","SharedSize":"N/A","Size":"900MB","Tag":"aaaa","UniqueSize":"N/A","VirtualSize":"900MB"}
+`
+	images, err := parseImageList(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 2 {
+		t.Fatalf("images = %+v", images)
+	}
+	if images[0].Ref != "devx-devcontainer:aaaa" || images[0].ID != "9c0d4a1e2f37" || images[0].Size != "900MB" {
+		t.Errorf("images[0] = %+v", images[0])
+	}
+	if images[1].Ref != "devx-myapp:0123456789abcdef" || images[1].CreatedSince != "2 days ago" {
+		t.Errorf("images[1] = %+v", images[1])
+	}
+}
+
+func TestParseImageLabels(t *testing.T) {
+	out := `{"devx.image":"project","devx.image.hash":"0123456789abcdef00","devx.image.project":"myapp"}
+{"devx.image":"devcontainer"}
+`
+	labels, err := parseImageLabels([]string{"3b418d7b466a", "9c0d4a1e2f37"}, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := labels["3b418d7b466a"]; l[ImageLabelKind] != "project" || l[ImageLabelProject] != "myapp" || l[ImageLabelHash] != "0123456789abcdef00" {
+		t.Errorf("project labels = %v", l)
+	}
+	if l := labels["9c0d4a1e2f37"]; l[ImageLabelKind] != "devcontainer" {
+		t.Errorf("devcontainer labels = %v", l)
+	}
+	if _, err := parseImageLabels([]string{"3b418d7b466a"}, out); err == nil {
+		t.Error("a result count that does not match the IDs should fail")
+	}
+}
+
+func TestMatchIgnorePattern(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.log", "a.log", true},
+		{"*.log", "sub/a.log", false},
+		{"**/*.log", "sub/deep/a.log", true},
+		{"**/*.log", "a.log", true},
+		{"node_modules", "node_modules", true},
+		{"docs/**", "docs/a/b.md", true},
+	}
+	for _, tt := range tests {
+		if got := matchIgnorePattern(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchIgnorePattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+
+	di := &dockerignore{patterns: []string{"build", "build/keep.txt"}, negate: []bool{false, true}}
+	if !di.excludes("build/out.o") || di.excludes("build/keep.txt") {
+		t.Error("negated pattern should re-include build/keep.txt only")
+	}
+	if di.excludesDir("build") {
+		t.Error("a directory with re-included files must still be walked")
+	}
+}
@@ -0,0 +1,315 @@
+package target
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jfox85/devx/session"
+)
+
+// External target plugins are executables named devx-target-<name> on PATH.
+// DevX runs the plugin once per operation with a JSON PluginRequest on stdin
+// and reads a JSON PluginResponse from stdout; stderr is passed through to
+// the user. A session created with --target <name> stores the plugin's opaque
+// metadata in TargetMeta.Plugin and hands it back on every later operation.
+
+// PluginProtocolVersion is the protocol version DevX speaks. Plugins must
+// echo it in every response.
+const PluginProtocolVersion = 1
+
+// PluginPrefix is the executable name prefix plugins are discovered by.
+const PluginPrefix = "devx-target-"
+
+// Plugin operations.
+const (
+	PluginOpStart       = "start"        // start the runtime; returns data to persist
+	PluginOpStop        = "stop"         // tear the runtime down
+	PluginOpIsRunning   = "is-running"   // returns running
+	PluginOpExecCommand = "exec-command" // returns the argv that runs request.command in the session
+	PluginOpTmuxEnsure  = "tmux-ensure"  // create the session's tmux environment if missing
+	PluginOpTmuxAttach  = "tmux-attach"  // returns the argv that attaches a terminal to it
+	PluginOpTmuxKill    = "tmux-kill"    // stop any plugin-owned tmux server
+)
+
+// pluginTimeout bounds every operation except start, which may provision a VM
+// or a pod.
+const (
+	pluginTimeout      = 30 * time.Second
+	pluginStartTimeout = 10 * time.Minute
+)
+
+var pluginNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// PluginStartOpts is the JSON form of StartOpts sent to plugins. Host-side
+// runtime configuration for the built-in targets is not included.
+type PluginStartOpts struct {
+	SessionName  string             `json:"session_name"`
+	WorktreePath string             `json:"worktree_path"`
+	HostPorts    map[string]int     `json:"host_ports,omitempty"`
+	Image        string             `json:"image,omitempty"`
+	Env          map[string]string  `json:"env,omitempty"`
+	Labels       map[string]string  `json:"labels,omitempty"`
+	Security     PluginSecurityOpts `json:"security"`
+	Caches       []PluginCacheMount `json:"caches,omitempty"`
+}
+
+// PluginSecurityOpts is the JSON form of SecurityOpts.
+type PluginSecurityOpts struct {
+	Memory       string   `json:"memory,omitempty"`
+	CPUs         string   `json:"cpus,omitempty"`
+	Pids         int      `json:"pids,omitempty"`
+	ReadOnlyRoot bool     `json:"read_only_root,omitempty"`
+	CapDrop      []string `json:"cap_drop,omitempty"`
+	CapAdd       []string `json:"cap_add,omitempty"`
+	NoNewPrivs   bool     `json:"no_new_privileges,omitempty"`
+	Tmpfs        []string `json:"tmpfs,omitempty"`
+}
+
+// PluginCacheMount is the JSON form of CacheMount.
+type PluginCacheMount struct {
+	Volume   string `json:"volume"`
+	Target   string `json:"target"`
+	ReadOnly bool   `json:"read_only,omitempty"`
+}
+
+// PluginRequest is written to a plugin's stdin.
+type PluginRequest struct {
+	Version     int                 `json:"version"`
+	Op          string              `json:"op"`
+	Session     string              `json:"session"`
+	Opts        *PluginStartOpts    `json:"opts,omitempty"`        // start
+	Meta        *session.TargetMeta `json:"meta,omitempty"`        // every op but start
+	Worktree    string              `json:"worktree,omitempty"`    // tmux ops
+	Command     []string            `json:"command,omitempty"`     // exec-command
+	Interactive bool                `json:"interactive,omitempty"` // exec-command
+}
+
+// PluginResponse is read from a plugin's stdout.
+type PluginResponse struct {
+	Version int             `json:"version"`
+	Error   string          `json:"error,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`    // start: opaque metadata to persist
+	Running bool            `json:"running,omitempty"` // is-running
+	Command []string        `json:"command,omitempty"` // exec-command, tmux-attach
+}
+
+// PluginTarget is a Target implemented by an external executable.
+type PluginTarget struct {
+	Name string
+	Path string
+}
+
+// IsValidPluginName reports whether name can name a plugin target. Names of
+// built-in targets are reserved.
+func IsValidPluginName(name string) bool {
+	switch name {
+	case "", "host", "docker", "podman", "compose", "gatepost", "sandbox", "remote":
+		return false
+	}
+	return pluginNameRe.MatchString(name)
+}
+
+// LookupPlugin finds the devx-target-<name> executable on PATH.
+func LookupPlugin(name string) (*PluginTarget, error) {
+	if !IsValidPluginName(name) {
+		return nil, fmt.Errorf("invalid target plugin name %q", name)
+	}
+	path, err := exec.LookPath(PluginPrefix + name)
+	if err != nil {
+		return nil, fmt.Errorf("target plugin %s%s not found on PATH", PluginPrefix, name)
+	}
+	return &PluginTarget{Name: name, Path: path}, nil
+}
+
+// ListPlugins returns the names of target plugins on PATH.
+func ListPlugins() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), PluginPrefix)
+			if !ok || seen[name] || !IsValidPluginName(name) {
+				continue
+			}
+			if _, err := LookupPlugin(name); err != nil {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *PluginTarget) Type() string { return p.Name }
+
+func (p *PluginTarget) Start(ctx context.Context, opts StartOpts) (*StartResult, error) {
+	resp, err := p.call(ctx, pluginStartTimeout, PluginRequest{
+		Op:      PluginOpStart,
+		Session: opts.SessionName,
+		Opts:    pluginStartOpts(opts),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &StartResult{Meta: session.TargetMeta{
+		Type:   p.Name,
+		Plugin: session.PluginMeta{Name: p.Name, Session: opts.SessionName, Data: resp.Data},
+	}}, nil
+}
+
+func (p *PluginTarget) Stop(ctx context.Context, meta session.TargetMeta) error {
+	_, err := p.call(ctx, pluginTimeout, PluginRequest{Op: PluginOpStop, Meta: &meta})
+	return err
+}
+
+// call runs one plugin operation and checks the response.
+func (p *PluginTarget) call(ctx context.Context, timeout time.Duration, req PluginRequest) (*PluginResponse, error) {
+	req.Version = PluginProtocolVersion
+	if req.Session == "" && req.Meta != nil {
+		req.Session = req.Meta.Plugin.Session
+	}
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, p.Path, req.Op)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stderr = os.Stderr
+	out, runErr := cmd.Output()
+
+	var resp PluginResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("target plugin %s %s: %w", p.Name, req.Op, runErr)
+		}
+		return nil, fmt.Errorf("target plugin %s %s: invalid response: %w", p.Name, req.Op, err)
+	}
+	if resp.Version != PluginProtocolVersion {
+		return nil, fmt.Errorf("target plugin %s speaks protocol version %d; devx needs %d", p.Name, resp.Version, PluginProtocolVersion)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("target plugin %s %s: %s", p.Name, req.Op, resp.Error)
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("target plugin %s %s: %w", p.Name, req.Op, runErr)
+	}
+	return &resp, nil
+}
+
+func pluginStartOpts(opts StartOpts) *PluginStartOpts {
+	sec := opts.Security
+	out := &PluginStartOpts{
+		SessionName:  opts.SessionName,
+		WorktreePath: opts.WorktreePath,
+		HostPorts:    opts.HostPorts,
+		Image:        opts.Image,
+		Env:          opts.Env,
+		Labels:       opts.Labels,
+		Security: PluginSecurityOpts{
+			Memory:       sec.MemoryLimit,
+			CPUs:         sec.CPULimit,
+			Pids:         sec.PidsLimit,
+			ReadOnlyRoot: sec.ReadOnlyRoot,
+			CapDrop:      sec.CapDrop,
+			CapAdd:       sec.CapAdd,
+			NoNewPrivs:   sec.NoNewPrivs,
+			Tmpfs:        sec.TmpfsMounts,
+		},
+	}
+	for _, c := range opts.Caches {
+		out.Caches = append(out.Caches, PluginCacheMount{Volume: c.Volume, Target: c.Target, ReadOnly: c.ReadOnly})
+	}
+	return out
+}
+
+// pluginExecCommand asks the plugin how to run cmd in the session. Errors are
+// reported through the returned Cmd's Err, so they surface when it starts.
+func pluginExecCommand(meta session.TargetMeta, cmd []string, interactive bool) *exec.Cmd {
+	p, err := LookupPlugin(meta.Type)
+	if err == nil {
+		var resp *PluginResponse
+		resp, err = p.call(context.Background(), pluginTimeout, PluginRequest{
+			Op: PluginOpExecCommand, Meta: &meta, Command: cmd, Interactive: interactive,
+		})
+		if err == nil && len(resp.Command) == 0 {
+			err = fmt.Errorf("target plugin %s returned no command", p.Name)
+		}
+		if err == nil {
+			return exec.Command(resp.Command[0], resp.Command[1:]...)
+		}
+	}
+	c := exec.Command(cmd[0], cmd[1:]...)
+	c.Err = err
+	return c
+}
+
+// pluginSessionOperator drives tmux for plugin sessions through the plugin.
+type pluginSessionOperator struct{ p *PluginTarget }
+
+func (op pluginSessionOperator) IsRunning(meta session.TargetMeta) bool {
+	resp, err := op.p.call(context.Background(), pluginTimeout, PluginRequest{Op: PluginOpIsRunning, Meta: &meta})
+	return err == nil && resp.Running
+}
+
+func (op pluginSessionOperator) EnsureTmuxSession(name string, sess *session.Session) error {
+	_, err := op.p.call(context.Background(), pluginTimeout, PluginRequest{
+		Op: PluginOpTmuxEnsure, Session: name, Meta: &sess.Target, Worktree: sess.Path,
+	})
+	return err
+}
+
+func (op pluginSessionOperator) AttachTmuxSession(name string, sess *session.Session) error {
+	if err := op.EnsureTmuxSession(name, sess); err != nil {
+		return err
+	}
+	wait, err := op.StartReadyTmuxSession(name, sess)
+	if err != nil {
+		return err
+	}
+	return wait()
+}
+
+func (op pluginSessionOperator) StartReadyTmuxSession(name string, sess *session.Session) (func() error, error) {
+	req := PluginRequest{Op: PluginOpTmuxAttach, Session: name}
+	if sess != nil {
+		req.Meta = &sess.Target
+		req.Worktree = sess.Path
+	}
+	resp, err := op.p.call(context.Background(), pluginTimeout, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Command) == 0 {
+		return nil, fmt.Errorf("target plugin %s returned no attach command", op.p.Name)
+	}
+	cmd := exec.Command(resp.Command[0], resp.Command[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd.Wait, nil
+}
+
+func (op pluginSessionOperator) KillTmuxServer(meta session.TargetMeta) error {
+	_, err := op.p.call(context.Background(), pluginTimeout, PluginRequest{Op: PluginOpTmuxKill, Meta: &meta})
+	return err
+}
@@ -0,0 +1,178 @@
+package target
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jfox85/devx/session"
+)
+
+// The test binary doubles as a fake plugin: installed on PATH as
+// devx-target-fake, it serves the protocol when DEVX_FAKE_TARGET_PLUGIN is set.
+func TestMain(m *testing.M) {
+	if os.Getenv("DEVX_FAKE_TARGET_PLUGIN") != "" {
+		runFakePlugin()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func runFakePlugin() {
+	var req PluginRequest
+	resp := PluginResponse{Version: PluginProtocolVersion}
+	if v := os.Getenv("DEVX_FAKE_TARGET_VERSION"); v != "" {
+		_ = json.Unmarshal([]byte(v), &resp.Version)
+	}
+	state := os.Getenv("DEVX_FAKE_TARGET_STATE")
+	running := filepath.Join(state, "running")
+	tmux := filepath.Join(state, "tmux")
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil || req.Version != PluginProtocolVersion || req.Op != os.Args[1] {
+		resp.Error = "bad request"
+	} else {
+		switch req.Op {
+		case PluginOpStart:
+			if req.Opts.WorktreePath == "" || req.Opts.Env["SESSION_NAME"] != req.Session {
+				resp.Error = "missing start opts"
+				break
+			}
+			resp.Data, _ = json.Marshal(map[string]string{"vm": "vm-" + req.Session})
+			_ = os.WriteFile(running, []byte(req.Session), 0o644)
+		case PluginOpStop:
+			_ = os.Remove(running)
+		case PluginOpIsRunning:
+			_, err := os.Stat(running)
+			resp.Running = err == nil
+		case PluginOpExecCommand:
+			var data map[string]string
+			_ = json.Unmarshal(req.Meta.Plugin.Data, &data)
+			resp.Command = append([]string{"env", "FAKE_VM=" + data["vm"]}, req.Command...)
+		case PluginOpTmuxEnsure:
+			_ = os.WriteFile(tmux, []byte(req.Worktree), 0o644)
+		case PluginOpTmuxAttach:
+			resp.Command = []string{"true"}
+		case PluginOpTmuxKill:
+			_ = os.Remove(tmux)
+		default:
+			resp.Error = "unsupported op " + req.Op
+		}
+	}
+	_ = json.NewEncoder(os.Stdout).Encode(resp)
+}
+
+func installFakePlugin(t *testing.T) string {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	if err := os.Symlink(exe, filepath.Join(bin, PluginPrefix+"fake")); err != nil {
+		t.Fatal(err)
+	}
+	state := t.TempDir()
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("DEVX_FAKE_TARGET_PLUGIN", "1")
+	t.Setenv("DEVX_FAKE_TARGET_STATE", state)
+	return state
+}
+
+func TestPluginTargetLifecycle(t *testing.T) {
+	state := installFakePlugin(t)
+	if got := ListPlugins(); len(got) != 1 || got[0] != "fake" {
+		t.Fatalf("ListPlugins = %v", got)
+	}
+	tgt, err := Resolve("fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	result, err := tgt.Start(ctx, StartOpts{
+		SessionName:  "feat",
+		WorktreePath: "/wt/feat",
+		HostPorts:    map[string]int{"web": 41000},
+		Env:          map[string]string{"SESSION_NAME": "feat"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := result.Meta
+	if meta.Type != "fake" || meta.Plugin.Name != "fake" || meta.Plugin.Session != "feat" || string(meta.Plugin.Data) != `{"vm":"vm-feat"}` {
+		t.Fatalf("meta = %+v", meta)
+	}
+
+	// Metadata must survive a round trip through sessions.json.
+	raw, _ := json.Marshal(meta)
+	var stored session.TargetMeta
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if !IsRunning(stored) {
+		t.Fatal("plugin should report the session running")
+	}
+
+	out, err := ExecInSession(stored, []string{"sh", "-c", "echo $FAKE_VM"}, false).Output()
+	if err != nil || strings.TrimSpace(string(out)) != "vm-feat" {
+		t.Fatalf("exec output = %q, %v", out, err)
+	}
+
+	sess := &session.Session{Name: "feat", Path: "/wt/feat", Target: stored}
+	if err := EnsureTmuxSession("feat", sess); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(state, "tmux")); string(data) != "/wt/feat" {
+		t.Fatalf("tmux-ensure worktree = %q", data)
+	}
+	wait, err := StartReadyTmuxSession("feat", sess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wait(); err != nil {
+		t.Fatal(err)
+	}
+	if err := KillTmuxServer(stored); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tgt.Stop(ctx, stored); err != nil {
+		t.Fatal(err)
+	}
+	if IsRunning(stored) {
+		t.Fatal("plugin should report the session stopped")
+	}
+}
+
+func TestPluginTargetErrors(t *testing.T) {
+	installFakePlugin(t)
+	tgt, err := Resolve("fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tgt.Start(context.Background(), StartOpts{SessionName: "feat"}); err == nil || !strings.Contains(err.Error(), "missing start opts") {
+		t.Fatalf("plugin error not surfaced: %v", err)
+	}
+
+	t.Setenv("DEVX_FAKE_TARGET_VERSION", "2")
+	if err := tgt.Stop(context.Background(), session.TargetMeta{Type: "fake"}); err == nil || !strings.Contains(err.Error(), "protocol version 2") {
+		t.Fatalf("version mismatch not detected: %v", err)
+	}
+	cmd := ExecInSession(session.TargetMeta{Type: "fake", Plugin: session.PluginMeta{Name: "fake"}}, []string{"true"}, false)
+	if err := cmd.Run(); err == nil {
+		t.Fatal("exec should fail when the plugin does")
+	}
+}
+
+func TestResolveRejectsMissingAndReservedPlugins(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	if _, err := Resolve("firecracker"); err == nil || !strings.Contains(err.Error(), PluginPrefix) {
+		t.Fatalf("missing plugin: %v", err)
+	}
+	for _, name := range []string{"docker", "../x", "Fire"} {
+		if IsValidPluginName(name) {
+			t.Errorf("%q should not be a valid plugin name", name)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package target
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/jfox85/devx/session"
+)
+
+// PodmanTarget runs sessions inside rootless Podman containers. It mirrors
+// DockerTarget, adding --userns=keep-id so files the session writes to the
+// bind-mounted worktree stay owned by the invoking user.
+type PodmanTarget struct{}
+
+func (p *PodmanTarget) Type() string { return "podman" }
+
+func (p *PodmanTarget) Start(ctx context.Context, opts StartOpts) (*StartResult, error) {
+	name := ContainerName(opts.SessionName)
+	netName := NetworkName(opts.SessionName)
+
+	// Create per-session network
+	if err := podmanRun(ctx, "network", "create", netName); err != nil {
+		return nil, fmt.Errorf("create network: %w", err)
+	}
+
+	if err := ensureCacheVolumes(ctx, "podman", opts.Caches); err != nil {
+		_ = cliRunIgnore(ctx, "podman", "network", "rm", netName)
+		return nil, err
+	}
+	args, image := containerRunArgs(name, netName, opts, "--userns=keep-id")
+	if err := podmanRun(ctx, args...); err != nil {
+		// Clean up network on failure
+		_ = cliRunIgnore(ctx, "podman", "network", "rm", netName)
+		return nil, fmt.Errorf("create container: %w", err)
+	}
+
+	// Get container ID
+	containerID, err := cliOutput(ctx, "podman", "inspect", "--format", "{{.Id}}", name)
+	if err != nil {
+		return nil, fmt.Errorf("inspect container: %w", err)
+	}
+
+	return &StartResult{
+		Meta: session.TargetMeta{
+			Type:          "podman",
+			ContainerID:   containerID,
+			ContainerName: name,
+			NetworkName:   netName,
+			Image:         image,
+		},
+	}, nil
+}
+
+func (p *PodmanTarget) Stop(ctx context.Context, meta session.TargetMeta) error {
+	if errs := stopContainer(ctx, "podman", meta); len(errs) > 0 {
+		return fmt.Errorf("podman teardown: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// IsPodmanRunning checks if a Podman container is alive.
+func IsPodmanRunning(meta session.TargetMeta) bool {
+	if meta.ContainerName == "" {
+		return false
+	}
+	out, err := cliOutput(context.Background(), "podman", "inspect", "--format", "{{.State.Running}}", meta.ContainerName)
+	if err != nil {
+		return false
+	}
+	return out == "true"
+}
+
+// CheckPodmanAvailable returns nil if Podman is installed and usable, or an
+// error with a clear message.
+func CheckPodmanAvailable() error {
+	cmd := exec.Command("podman", "info")
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Podman is not available. Install podman (rootless) to use --target podman")
+	}
+	return nil
+}
+
+// PodmanImageExists checks if an image exists in the user's Podman storage.
+func PodmanImageExists(image string) bool {
+	cmd := exec.Command("podman", "image", "exists", image)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	return cmd.Run() == nil
+}
+
+// podmanRun executes a podman command and returns any error.
+func podmanRun(ctx context.Context, args ...string) error {
+	return cliRun(ctx, "podman", args...)
+}
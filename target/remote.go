@@ -0,0 +1,358 @@
+package target
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jfox85/devx/session"
+)
+
+// RemoteTarget runs sessions on an SSH host. The worktree and tmux session
+// live on the remote machine; a local ssh control master multiplexes every
+// command and forwards the session's ports back to 127.0.0.1 so Caddy routes
+// keep working locally.
+type RemoteTarget struct{}
+
+// RemoteRuntimeConfig describes the remote host and paths for a new remote
+// session. It comes from trusted (user-global) configuration.
+type RemoteRuntimeConfig struct {
+	Name         string
+	Host         string
+	Port         int
+	IdentityFile string
+	RemoteRepo   string   // project checkout on the remote host
+	RemotePath   string   // session worktree to create on the remote host
+	SyncFiles    []string // worktree-relative files copied to the remote after creation
+}
+
+func (r *RemoteTarget) Type() string { return "remote" }
+
+func (r *RemoteTarget) Start(ctx context.Context, opts StartOpts) (*StartResult, error) {
+	cfg := opts.Remote
+	if cfg.Host == "" || cfg.RemoteRepo == "" || cfg.RemotePath == "" {
+		return nil, fmt.Errorf("remote target requires a host, remote repo and remote path")
+	}
+	meta := session.RemoteMeta{
+		Name:          cfg.Name,
+		Host:          cfg.Host,
+		Port:          cfg.Port,
+		IdentityFile:  cfg.IdentityFile,
+		RemoteRepo:    cfg.RemoteRepo,
+		RemotePath:    cfg.RemotePath,
+		ControlSocket: RemoteControlSocket(opts.SessionName),
+		TmuxSession:   opts.SessionName,
+		Synced:        cfg.SyncFiles,
+	}
+	for _, port := range opts.HostPorts {
+		meta.Ports = append(meta.Ports, port)
+	}
+
+	if err := startRemoteMaster(ctx, meta); err != nil {
+		return nil, err
+	}
+	if err := remoteRun(ctx, meta, remoteWorktreeScript(meta.RemoteRepo, meta.RemotePath, opts.SessionName), nil); err != nil {
+		_ = stopRemoteMaster(meta)
+		return nil, fmt.Errorf("create remote worktree: %w", err)
+	}
+	if err := syncRemoteFiles(ctx, meta, opts.WorktreePath, cfg.SyncFiles); err != nil {
+		_ = stopRemoteMaster(meta)
+		return nil, fmt.Errorf("sync files to remote: %w", err)
+	}
+
+	return &StartResult{
+		Meta: session.TargetMeta{Type: "remote", Remote: meta},
+	}, nil
+}
+
+// Stop kills the remote tmux session, removes the remote worktree if it has no
+// uncommitted changes, and closes the control master. A dirty remote worktree
+// is left in place and reported so work is never discarded silently.
+func (r *RemoteTarget) Stop(ctx context.Context, meta session.TargetMeta) error {
+	rm := meta.Remote
+	if rm.Host == "" {
+		return nil
+	}
+	var errs []string
+	if err := remoteRun(ctx, rm, remoteTeardownScript(rm), nil); err != nil {
+		errs = append(errs, fmt.Sprintf("remove remote worktree %s (left in place): %v", rm.RemotePath, err))
+	}
+	if err := stopRemoteMaster(rm); err != nil {
+		errs = append(errs, fmt.Sprintf("close control master: %v", err))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("remote teardown: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// remoteTeardownScript kills the remote tmux session and removes the remote
+// worktree unless it holds changes other than the files devx synced into it.
+// Commits stay on the branch in the remote repo.
+func remoteTeardownScript(meta session.RemoteMeta) string {
+	filter := "cat"
+	if len(meta.Synced) > 0 {
+		filter = "grep -v -x -F"
+		for _, rel := range meta.Synced {
+			filter += " -e " + shellQuote("?? "+filepath.ToSlash(filepath.Clean(rel)))
+		}
+	}
+	return fmt.Sprintf(`tmux kill-session -t %[1]s 2>/dev/null || true
+[ -d %[2]s ] || exit 0
+dirty=$(git -C %[2]s status --porcelain | %[4]s || true)
+if [ -n "$dirty" ]; then
+  echo "uncommitted changes:"
+  echo "$dirty"
+  exit 1
+fi
+git -C %[3]s worktree remove --force %[2]s`, shellQuote("="+meta.TmuxSession), shellQuote(meta.RemotePath), shellQuote(meta.RemoteRepo), filter)
+}
+
+// CheckRemoteAvailable verifies the remote host is reachable without prompts
+// and resolves a leading ~ in the remote repo and worktree paths against the
+// remote $HOME.
+func CheckRemoteAvailable(ctx context.Context, cfg *RemoteRuntimeConfig) error {
+	meta := session.RemoteMeta{Host: cfg.Host, Port: cfg.Port, IdentityFile: cfg.IdentityFile}
+	args := append(sshArgs(meta), "-o", "BatchMode=yes", "-o", "ConnectTimeout=10", meta.Host, `printf %s "$HOME"`)
+	out, err := exec.CommandContext(ctx, "ssh", args...).Output()
+	if err != nil {
+		return fmt.Errorf("cannot reach remote %q (%s) over ssh: %w", cfg.Name, cfg.Host, err)
+	}
+	home := strings.TrimSpace(string(out))
+	cfg.RemoteRepo = expandRemoteHome(cfg.RemoteRepo, home)
+	cfg.RemotePath = expandRemoteHome(cfg.RemotePath, home)
+	return nil
+}
+
+// RemoteControlSocket returns the local ssh control socket for a session.
+// The name is hashed because unix socket paths are limited to ~100 bytes.
+func RemoteControlSocket(sessionName string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(sessionName))
+	return filepath.Join(home, ".devx", "ssh", hex.EncodeToString(sum[:])[:16]+".sock")
+}
+
+// IsRemoteRunning reports whether the session's control master is alive.
+func IsRemoteRunning(meta session.TargetMeta) bool {
+	if meta.Remote.Host == "" || meta.Remote.ControlSocket == "" {
+		return false
+	}
+	args := append(sshArgs(meta.Remote), "-O", "check", meta.Remote.Host)
+	cmd := exec.Command("ssh", args...)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	return cmd.Run() == nil
+}
+
+// EnsureRemoteMaster restarts the session's control master (and its port
+// forwards) when it is not running, e.g. after a laptop reboot.
+func EnsureRemoteMaster(ctx context.Context, meta session.TargetMeta) error {
+	if IsRemoteRunning(meta) {
+		return nil
+	}
+	return startRemoteMaster(ctx, meta.Remote)
+}
+
+// sshArgs returns the connection options shared by every ssh invocation for
+// a remote session.
+func sshArgs(meta session.RemoteMeta) []string {
+	var args []string
+	if meta.ControlSocket != "" {
+		args = append(args, "-o", "ControlPath="+meta.ControlSocket)
+	}
+	if meta.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(meta.Port))
+	}
+	if meta.IdentityFile != "" {
+		args = append(args, "-i", meta.IdentityFile)
+	}
+	return args
+}
+
+// remoteMasterArgs builds the ssh invocation that starts a backgrounded
+// control master forwarding each session port to local loopback.
+func remoteMasterArgs(meta session.RemoteMeta) []string {
+	args := append(sshArgs(meta),
+		"-M", "-f", "-N",
+		"-o", "ControlPersist=yes",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=30",
+	)
+	for _, port := range meta.Ports {
+		args = append(args, "-L", fmt.Sprintf("127.0.0.1:%d:127.0.0.1:%d", port, port))
+	}
+	return append(args, meta.Host)
+}
+
+func startRemoteMaster(ctx context.Context, meta session.RemoteMeta) error {
+	if err := os.MkdirAll(filepath.Dir(meta.ControlSocket), 0o700); err != nil {
+		return fmt.Errorf("create control socket dir: %w", err)
+	}
+	// A dead master can leave a stale socket behind that blocks a new one.
+	_ = os.Remove(meta.ControlSocket)
+
+	// ssh -f keeps its stderr open after backgrounding, so capture it in a
+	// file rather than a pipe that would block until the master exits.
+	errFile, err := os.CreateTemp("", "devx-ssh-*.log")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(errFile.Name())
+	defer errFile.Close()
+
+	cmd := exec.CommandContext(ctx, "ssh", remoteMasterArgs(meta)...)
+	cmd.Stderr = errFile
+	if err := cmd.Run(); err != nil {
+		out, _ := os.ReadFile(errFile.Name())
+		return fmt.Errorf("start ssh control master to %s: %w\n%s", meta.Host, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func stopRemoteMaster(meta session.RemoteMeta) error {
+	if meta.ControlSocket == "" {
+		return nil
+	}
+	if _, err := os.Stat(meta.ControlSocket); os.IsNotExist(err) {
+		return nil
+	}
+	args := append(sshArgs(meta), "-O", "exit", meta.Host)
+	if out, err := exec.Command("ssh", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// remoteArgs builds ssh arguments that run script with sh on the remote host
+// over the session's control master.
+func remoteArgs(meta session.RemoteMeta, script string, tty bool) []string {
+	args := sshArgs(meta)
+	if tty {
+		args = append(args, "-t")
+	}
+	return append(args, meta.Host, "sh -c "+shellQuote(script))
+}
+
+// remoteRun runs script on the remote host, feeding stdin when non-nil.
+func remoteRun(ctx context.Context, meta session.RemoteMeta, script string, stdin *os.File) error {
+	cmd := exec.CommandContext(ctx, "ssh", remoteArgs(meta, script, false)...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// remoteWorktreeScript creates the session worktree on the remote host,
+// reusing an existing directory and checking out an existing branch when one
+// matches the session name.
+func remoteWorktreeScript(repo, worktree, branch string) string {
+	return fmt.Sprintf(`set -e
+if [ -d %[2]s ]; then exit 0; fi
+cd %[1]s
+git fetch --quiet origin 2>/dev/null || true
+mkdir -p "$(dirname %[2]s)"
+if git show-ref --verify --quiet %[4]s; then
+  git worktree add %[2]s %[3]s
+else
+  git worktree add -b %[3]s %[2]s
+fi`, shellQuote(repo), shellQuote(worktree), shellQuote(branch), shellQuote("refs/heads/"+branch))
+}
+
+// SyncRemoteFiles copies worktree-relative files from a session's local
+// worktree into its remote worktree, e.g. after re-rendering templates.
+func SyncRemoteFiles(ctx context.Context, meta session.TargetMeta, localRoot string, files []string) error {
+	if err := EnsureRemoteMaster(ctx, meta); err != nil {
+		return err
+	}
+	return syncRemoteFiles(ctx, meta.Remote, localRoot, files)
+}
+
+// syncRemoteFiles copies worktree-relative files that devx generated locally
+// (.envrc, .tmuxp.yaml, bootstrap files) into the remote worktree.
+func syncRemoteFiles(ctx context.Context, meta session.RemoteMeta, localRoot string, files []string) error {
+	for _, rel := range files {
+		clean := filepath.Clean(rel)
+		if filepath.IsAbs(clean) || clean == "." || strings.HasPrefix(clean, "..") {
+			return fmt.Errorf("sync path must stay inside the worktree: %s", rel)
+		}
+		src := filepath.Join(localRoot, clean)
+		info, err := os.Stat(src)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		f, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		dst := path.Join(meta.RemotePath, filepath.ToSlash(clean))
+		script := fmt.Sprintf("mkdir -p %s && cat > %s && chmod %o %s",
+			shellQuote(path.Dir(dst)), shellQuote(dst), info.Mode().Perm(), shellQuote(dst))
+		err = remoteRun(ctx, meta, script, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", rel, err)
+		}
+	}
+	return nil
+}
+
+// ensureRemoteTmuxScript starts the session's tmux layout on the remote host
+// from its .tmuxp.yaml, falling back to a plain tmux session without tmuxp.
+func ensureRemoteTmuxScript(meta session.RemoteMeta) string {
+	sessionName := meta.TmuxSession
+	return fmt.Sprintf(`cd %[1]s || exit 1
+tmux has-session -t %[2]s 2>/dev/null && exit 0
+if command -v tmuxp >/dev/null 2>&1 && [ -f .tmuxp.yaml ]; then
+  tmuxp load -d .tmuxp.yaml -s %[3]s && exit 0
+fi
+tmux new-session -d -s %[3]s -c %[1]s`, shellQuote(meta.RemotePath), shellQuote("="+sessionName), shellQuote(sessionName))
+}
+
+// remoteAttachCommand returns the argv that attaches the current terminal to
+// the session's tmux session on the remote host.
+func remoteAttachCommand(meta session.RemoteMeta) []string {
+	args := append([]string{"ssh"}, sshArgs(meta)...)
+	return append(args, "-t", meta.Host, "tmux attach-session -t "+shellQuote("="+meta.TmuxSession))
+}
+
+// remoteExecScript builds the remote shell script that runs cmd inside the
+// remote worktree.
+func remoteExecScript(meta session.RemoteMeta, cmd []string) string {
+	quoted := make([]string, len(cmd))
+	for i, arg := range cmd {
+		quoted[i] = shellQuote(arg)
+	}
+	return "cd " + shellQuote(meta.RemotePath) + " && exec " + strings.Join(quoted, " ")
+}
+
+// expandRemoteHome replaces a leading ~ with the remote home directory.
+func expandRemoteHome(p, home string) string {
+	if home == "" {
+		return p
+	}
+	if p == "~" {
+		return home
+	}
+	if strings.HasPrefix(p, "~/") {
+		return path.Join(home, p[2:])
+	}
+	return p
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,216 @@
+package target
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jfox85/devx/session"
+)
+
+func TestRemoteMasterArgsForwardPortsToLoopback(t *testing.T) {
+	meta := session.RemoteMeta{
+		Host:          "dev@buildbox",
+		Port:          2222,
+		IdentityFile:  "/home/me/.ssh/id_ed25519",
+		ControlSocket: "/home/me/.devx/ssh/abc.sock",
+		Ports:         []int{3000, 8080},
+	}
+	joined := strings.Join(remoteMasterArgs(meta), " ")
+	for _, want := range []string{
+		"-o ControlPath=/home/me/.devx/ssh/abc.sock",
+		"-p 2222",
+		"-i /home/me/.ssh/id_ed25519",
+		"-M -f -N",
+		"-o ControlPersist=yes",
+		"-o ExitOnForwardFailure=yes",
+		"-L 127.0.0.1:3000:127.0.0.1:3000",
+		"-L 127.0.0.1:8080:127.0.0.1:8080",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("master args missing %q: %s", want, joined)
+		}
+	}
+	if !strings.HasSuffix(joined, " dev@buildbox") {
+		t.Errorf("host should be the last argument: %s", joined)
+	}
+}
+
+func TestExecInSessionRemote(t *testing.T) {
+	meta := session.TargetMeta{Type: "remote", Remote: session.RemoteMeta{
+		Host:          "buildbox",
+		RemotePath:    "/srv/wt/app/feat",
+		ControlSocket: "/tmp/devx.sock",
+	}}
+	cmd := ExecInSession(meta, []string{"echo", "it's"}, true)
+	want := []string{"ssh", "-o", "ControlPath=/tmp/devx.sock", "-t", "buildbox",
+		`sh -c 'cd '"'"'/srv/wt/app/feat'"'"' && exec '"'"'echo'"'"' '"'"'it'"'"'"'"'"'"'"'"'s'"'"''`}
+	if len(cmd.Args) != len(want) {
+		t.Fatalf("remote exec args = %q, want %q", cmd.Args, want)
+	}
+	for i := range want {
+		if cmd.Args[i] != want[i] {
+			t.Errorf("args[%d] = %q, want %q", i, cmd.Args[i], want[i])
+		}
+	}
+}
+
+func TestRemoteExecScriptRunsInWorktree(t *testing.T) {
+	dir := t.TempDir()
+	script := remoteExecScript(session.RemoteMeta{RemotePath: dir}, []string{"sh", "-c", "pwd; echo \"$1\"", "x", "a 'quoted' arg"})
+	out, err := exec.Command("sh", "-c", script).CombinedOutput()
+	if err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+	real, _ := filepath.EvalSymlinks(dir)
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 || (lines[0] != dir && lines[0] != real) || lines[1] != "a 'quoted' arg" {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
+
+func TestRemoteWorktreeScriptCreatesAndReusesWorktree(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	worktree := filepath.Join(t.TempDir(), "app", "feat")
+	script := remoteWorktreeScript(repo, worktree, "feat")
+	for i := 0; i < 2; i++ {
+		if out, err := exec.Command("sh", "-c", script).CombinedOutput(); err != nil {
+			t.Fatalf("run %d: %v\n%s", i, err, out)
+		}
+	}
+	out, err := exec.Command("git", "-C", worktree, "branch", "--show-current").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "feat" {
+		t.Fatalf("branch = %q, want feat", got)
+	}
+}
+
+func TestRemoteTeardownScriptKeepsDirtyWorktrees(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	worktree := filepath.Join(t.TempDir(), "feat")
+	if out, err := exec.Command("sh", "-c", remoteWorktreeScript(repo, worktree, "feat")).CombinedOutput(); err != nil {
+		t.Fatalf("create: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, ".envrc"), []byte("export X=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, "notes.txt"), []byte("wip\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	meta := session.RemoteMeta{RemoteRepo: repo, RemotePath: worktree, TmuxSession: "feat", Synced: []string{".envrc"}}
+
+	if out, err := exec.Command("sh", "-c", remoteTeardownScript(meta)).CombinedOutput(); err == nil {
+		t.Fatalf("teardown should refuse a worktree with new files, output:\n%s", out)
+	}
+	if _, err := os.Stat(worktree); err != nil {
+		t.Fatalf("dirty worktree should be kept: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(worktree, "notes.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("sh", "-c", remoteTeardownScript(meta)).CombinedOutput(); err != nil {
+		t.Fatalf("teardown of a clean worktree failed: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(worktree); !os.IsNotExist(err) {
+		t.Fatalf("clean worktree should be removed, stat err = %v", err)
+	}
+}
+
+func TestExpandRemoteHome(t *testing.T) {
+	tests := map[string]string{
+		"~":                 "/home/dev",
+		"~/.devx/worktrees": "/home/dev/.devx/worktrees",
+		"/srv/src":          "/srv/src",
+		"relative/path":     "relative/path",
+	}
+	for in, want := range tests {
+		if got := expandRemoteHome(in, "/home/dev"); got != want {
+			t.Errorf("expandRemoteHome(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRemoteControlSocketIsShort(t *testing.T) {
+	sock := RemoteControlSocket(strings.Repeat("very-long-session-name/", 10))
+	if len(filepath.Base(sock)) > 24 {
+		t.Fatalf("socket name should be hashed, got %s", sock)
+	}
+	if sock != RemoteControlSocket(strings.Repeat("very-long-session-name/", 10)) {
+		t.Fatal("socket path should be stable for a session")
+	}
+}
+
+// TestRemoteTargetAgainstSSHHost exercises Start/Exec/Stop against a real
+// sshd. Set DEVX_TEST_SSH_HOST (e.g. localhost) with key-based auth and
+// DEVX_TEST_SSH_REPO to a git checkout on that host.
+func TestRemoteTargetAgainstSSHHost(t *testing.T) {
+	host, repo := os.Getenv("DEVX_TEST_SSH_HOST"), os.Getenv("DEVX_TEST_SSH_REPO")
+	if host == "" || repo == "" {
+		t.Skip("DEVX_TEST_SSH_HOST and DEVX_TEST_SSH_REPO not set")
+	}
+	ctx := context.Background()
+	local := t.TempDir()
+	if err := os.WriteFile(filepath.Join(local, ".envrc"), []byte("export WEB_PORT=3999\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := RemoteRuntimeConfig{
+		Name:       "test",
+		Host:       host,
+		RemoteRepo: repo,
+		RemotePath: filepath.Join(t.TempDir(), "remote-target-test"),
+		SyncFiles:  []string{".envrc"},
+	}
+	if err := CheckRemoteAvailable(ctx, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	tgt := &RemoteTarget{}
+	result, err := tgt.Start(ctx, StartOpts{
+		SessionName:  "remote-target-test",
+		WorktreePath: local,
+		HostPorts:    map[string]int{"web": 3999},
+		Remote:       cfg,
+	})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer tgt.Stop(ctx, result.Meta)
+
+	if !IsRemoteRunning(result.Meta) {
+		t.Fatal("control master should be running")
+	}
+	out, err := ExecInSession(result.Meta, []string{"cat", ".envrc"}, false).Output()
+	if err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if !strings.Contains(string(out), "WEB_PORT=3999") {
+		t.Fatalf("synced .envrc not found remotely: %q", out)
+	}
+	if err := tgt.Stop(ctx, result.Meta); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if IsRemoteRunning(result.Meta) {
+		t.Fatal("control master should be closed after Stop")
+	}
+}
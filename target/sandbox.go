@@ -0,0 +1,201 @@
+package target
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/jfox85/devx/session"
+)
+
+// SandboxTarget runs sessions on the host inside a bubblewrap (bwrap)
+// sandbox: Linux namespaces without a container daemon. The worktree is bound
+// read-write, system toolchain directories read-only, and $HOME is replaced by
+// an empty tmpfs so credentials and dotfiles stay out of reach.
+type SandboxTarget struct{}
+
+// SandboxRuntimeConfig is the trusted host-side sandbox layout. Like
+// GatepostRuntimeConfig it must come from user-global configuration so a
+// project cannot widen its own sandbox.
+type SandboxRuntimeConfig struct {
+	NoNetwork bool
+	ReadOnly  []string // extra host paths (toolchains) bound read-only
+	Hide      []string // host directories masked with an empty tmpfs
+}
+
+// sandboxSystemDirs are bound read-only (or recreated as symlinks on merged-usr
+// systems) when they exist on the host.
+var sandboxSystemDirs = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc", "/opt", "/nix"}
+
+// sandboxEnvPassthrough lists the host environment variables kept inside the
+// sandbox; everything else is cleared so host tokens do not leak in via env.
+var sandboxEnvPassthrough = []string{"PATH", "USER", "LOGNAME", "SHELL", "TERM", "COLORTERM", "LANG", "LC_ALL", "TZ"}
+
+func (s *SandboxTarget) Type() string { return "sandbox" }
+
+func (s *SandboxTarget) Start(ctx context.Context, opts StartOpts) (*StartResult, error) {
+	if err := CheckSandboxAvailable(); err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(opts.WorktreePath) {
+		return nil, fmt.Errorf("sandbox worktree path must be absolute: %s", opts.WorktreePath)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("resolve home directory: %w", err)
+	}
+	for _, p := range append(append([]string{}, opts.Sandbox.ReadOnly...), opts.Sandbox.Hide...) {
+		if !filepath.IsAbs(p) {
+			return nil, fmt.Errorf("sandbox paths must be absolute: %s", p)
+		}
+	}
+
+	gitDir, worktreeGitDir := sandboxGitDirs(ctx, opts.WorktreePath)
+	return &StartResult{
+		Meta: session.TargetMeta{
+			Type: "sandbox",
+			Sandbox: session.SandboxMeta{
+				Runtime:        "bwrap",
+				Home:           home,
+				Worktree:       opts.WorktreePath,
+				GitDir:         gitDir,
+				WorktreeGitDir: worktreeGitDir,
+				NoNetwork:      opts.Sandbox.NoNetwork,
+				ReadOnly:       opts.Sandbox.ReadOnly,
+				Hide:           opts.Sandbox.Hide,
+				Env:            opts.Env,
+			},
+		},
+	}, nil
+}
+
+// Stop is a no-op: sandboxed processes are started with --die-with-parent and
+// exit with their tmux panes.
+func (s *SandboxTarget) Stop(_ context.Context, _ session.TargetMeta) error {
+	return nil
+}
+
+// CheckSandboxAvailable returns nil if bubblewrap is installed, or an error
+// with a clear message.
+func CheckSandboxAvailable() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("the sandbox target requires Linux namespaces; use --target docker or podman on %s", runtime.GOOS)
+	}
+	if _, err := exec.LookPath("bwrap"); err != nil {
+		return fmt.Errorf("bubblewrap is not installed. Install bwrap (e.g. apt install bubblewrap) to use --target sandbox")
+	}
+	return nil
+}
+
+// sandboxGitDirs returns the shared git directory of a linked worktree and
+// the worktree's own directory inside it. Both live outside the worktree and
+// parts of them must stay writable for commits. It returns "" when the
+// worktree holds its own .git directory.
+func sandboxGitDirs(ctx context.Context, worktreePath string) (gitDir, worktreeGitDir string) {
+	out, err := exec.CommandContext(ctx, "git", "-C", worktreePath, "rev-parse", "--path-format=absolute", "--git-common-dir", "--absolute-git-dir").Output()
+	if err != nil {
+		return "", ""
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 || lines[0] == "" || lines[0] == filepath.Join(worktreePath, ".git") {
+		return "", ""
+	}
+	return lines[0], lines[1]
+}
+
+// sandboxGitBinds binds the shared git directory read-only and only the
+// stores a commit writes read-write. Its config and hooks are run by every
+// git command on the host, so sandboxed code must not be able to change them.
+func sandboxGitBinds(meta session.SandboxMeta) []string {
+	args := []string{"--ro-bind", meta.GitDir, meta.GitDir}
+	for _, dir := range []string{"objects", "refs", "logs"} {
+		p := filepath.Join(meta.GitDir, dir)
+		args = append(args, "--bind-try", p, p)
+	}
+	worktreeGitDir := meta.WorktreeGitDir
+	if worktreeGitDir == "" {
+		// Sessions created before the worktree's dir was recorded.
+		worktreeGitDir = filepath.Join(meta.GitDir, "worktrees")
+	}
+	args = append(args, "--bind", worktreeGitDir, worktreeGitDir)
+	if meta.WorktreeGitDir != "" {
+		// Read when extensions.worktreeConfig is on.
+		p := filepath.Join(meta.WorktreeGitDir, "config.worktree")
+		args = append(args, "--ro-bind-try", p, p)
+	}
+	return args
+}
+
+// sandboxArgs builds the bwrap arguments for a sandbox session, ending with
+// "--" so the caller can append the command to run.
+func sandboxArgs(meta session.SandboxMeta) []string {
+	args := []string{"--die-with-parent", "--unshare-pid", "--unshare-ipc", "--unshare-uts"}
+	if meta.NoNetwork {
+		args = append(args, "--unshare-net")
+	}
+
+	for _, dir := range sandboxSystemDirs {
+		info, err := os.Lstat(dir)
+		if err != nil {
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err := os.Readlink(dir); err == nil {
+				args = append(args, "--symlink", link, dir)
+			}
+			continue
+		}
+		args = append(args, "--ro-bind", dir, dir)
+	}
+	if !meta.NoNetwork {
+		// resolv.conf commonly points into /run on systemd hosts.
+		args = append(args, "--ro-bind-try", "/run/systemd/resolve", "/run/systemd/resolve")
+	}
+	args = append(args, "--proc", "/proc", "--dev", "/dev", "--tmpfs", "/tmp")
+
+	// Mask $HOME first so toolchain, git and worktree binds below it are
+	// layered on top of the empty tmpfs.
+	if meta.Home != "" {
+		args = append(args, "--tmpfs", meta.Home)
+	}
+	for _, p := range meta.ReadOnly {
+		args = append(args, "--ro-bind-try", p, p)
+	}
+	for _, p := range meta.Hide {
+		args = append(args, "--tmpfs", p)
+	}
+	if meta.GitDir != "" {
+		args = append(args, sandboxGitBinds(meta)...)
+	}
+	args = append(args, "--bind", meta.Worktree, meta.Worktree, "--chdir", meta.Worktree)
+
+	args = append(args, "--clearenv")
+	for _, key := range sandboxEnvPassthrough {
+		if val, ok := os.LookupEnv(key); ok {
+			args = append(args, "--setenv", key, val)
+		}
+	}
+	if meta.Home != "" {
+		args = append(args, "--setenv", "HOME", meta.Home)
+	}
+	keys := make([]string, 0, len(meta.Env))
+	for k := range meta.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--setenv", k, meta.Env[k])
+	}
+	return append(args, "--")
+}
+
+// sandboxCommandPrefix returns the argv that wraps a command in the session's
+// sandbox.
+func sandboxCommandPrefix(meta session.TargetMeta) []string {
+	return append([]string{"bwrap"}, sandboxArgs(meta.Sandbox)...)
+}
@@ -1,5 +1,12 @@
 package target
 
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
 // SecurityOpts holds container security configuration.
 type SecurityOpts struct {
 	MemoryLimit  string // e.g. "4g"
@@ -7,6 +14,7 @@ type SecurityOpts struct {
 	PidsLimit    int
 	ReadOnlyRoot bool
 	CapDrop      []string // capabilities to drop
+	CapAdd       []string // capabilities added back after CapDrop
 	NoNewPrivs   bool
 	TmpfsMounts  []string // tmpfs paths when read-only root is on
 }
@@ -21,3 +29,108 @@ func DefaultSecurityOpts() SecurityOpts {
 		NoNewPrivs:  true,
 	}
 }
+
+// SecurityPreset returns a built-in preset by name: "default", "small",
+// "large" or "strict" (read-only root with tmpfs scratch space).
+func SecurityPreset(name string) (SecurityOpts, bool) {
+	opts := DefaultSecurityOpts()
+	switch name {
+	case "default":
+	case "small":
+		opts.MemoryLimit, opts.CPULimit, opts.PidsLimit = "2g", "2", 1024
+	case "large":
+		opts.MemoryLimit, opts.CPULimit, opts.PidsLimit = "16g", "8", 8192
+	case "strict":
+		opts.MemoryLimit, opts.CPULimit, opts.PidsLimit = "2g", "2", 512
+		opts.ReadOnlyRoot = true
+		opts.TmpfsMounts = []string{"/tmp", "/run", "/root"}
+	default:
+		return SecurityOpts{}, false
+	}
+	return opts, true
+}
+
+// allowedCapAdd are the capabilities a config may add back. They cover common
+// development needs (file ownership, privileged ports, debuggers) without
+// granting control over the host kernel or network.
+var allowedCapAdd = map[string]bool{
+	"CHOWN":            true,
+	"DAC_OVERRIDE":     true,
+	"FOWNER":           true,
+	"FSETID":           true,
+	"KILL":             true,
+	"SETGID":           true,
+	"SETUID":           true,
+	"NET_BIND_SERVICE": true,
+	"SYS_PTRACE":       true,
+}
+
+// Validate checks limits and rejects capabilities outside allowedCapAdd.
+func (s SecurityOpts) Validate() error {
+	for _, c := range s.CapAdd {
+		name := strings.TrimPrefix(strings.ToUpper(c), "CAP_")
+		if !allowedCapAdd[name] {
+			allowed := make([]string, 0, len(allowedCapAdd))
+			for a := range allowedCapAdd {
+				allowed = append(allowed, a)
+			}
+			sort.Strings(allowed)
+			return fmt.Errorf("capability %s cannot be added (allowed: %s)", c, strings.Join(allowed, ", "))
+		}
+	}
+	if s.CPULimit != "" {
+		if v, err := strconv.ParseFloat(s.CPULimit, 64); err != nil || v <= 0 {
+			return fmt.Errorf("invalid cpus %q: must be a positive number", s.CPULimit)
+		}
+	}
+	if s.MemoryLimit != "" && !validMemoryLimit(s.MemoryLimit) {
+		return fmt.Errorf("invalid memory %q: use a number with an optional b, k, m or g suffix", s.MemoryLimit)
+	}
+	if s.PidsLimit < 0 {
+		return fmt.Errorf("invalid pids limit %d", s.PidsLimit)
+	}
+	return nil
+}
+
+func validMemoryLimit(s string) bool {
+	s = strings.ToLower(s)
+	if n := len(s); n > 0 && strings.ContainsRune("bkmg", rune(s[n-1])) {
+		s = s[:n-1]
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return err == nil && v > 0
+}
+
+// securityArgs converts SecurityOpts to container run flags. Empty options
+// fall back to DefaultSecurityOpts.
+func securityArgs(sec SecurityOpts) []string {
+	if sec.MemoryLimit == "" {
+		sec = DefaultSecurityOpts()
+	}
+	var args []string
+	for _, cap := range sec.CapDrop {
+		args = append(args, "--cap-drop="+cap)
+	}
+	for _, cap := range sec.CapAdd {
+		args = append(args, "--cap-add="+strings.TrimPrefix(strings.ToUpper(cap), "CAP_"))
+	}
+	if sec.NoNewPrivs {
+		args = append(args, "--security-opt", "no-new-privileges")
+	}
+	if sec.MemoryLimit != "" {
+		args = append(args, "--memory", sec.MemoryLimit)
+	}
+	if sec.CPULimit != "" {
+		args = append(args, "--cpus", sec.CPULimit)
+	}
+	if sec.PidsLimit > 0 {
+		args = append(args, "--pids-limit", fmt.Sprintf("%d", sec.PidsLimit))
+	}
+	if sec.ReadOnlyRoot {
+		args = append(args, "--read-only")
+		for _, m := range sec.TmpfsMounts {
+			args = append(args, "--tmpfs", m)
+		}
+	}
+	return args
+}
@@ -0,0 +1,71 @@
+package target
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSecurityPresets(t *testing.T) {
+	for _, name := range []string{"default", "small", "large", "strict"} {
+		opts, ok := SecurityPreset(name)
+		if !ok {
+			t.Fatalf("preset %q missing", name)
+		}
+		if err := opts.Validate(); err != nil {
+			t.Errorf("preset %q invalid: %v", name, err)
+		}
+		if len(opts.CapDrop) != 1 || opts.CapDrop[0] != "ALL" || !opts.NoNewPrivs {
+			t.Errorf("preset %q must keep the hardening defaults: %+v", name, opts)
+		}
+	}
+	if strict, _ := SecurityPreset("strict"); !strict.ReadOnlyRoot || len(strict.TmpfsMounts) == 0 {
+		t.Errorf("strict preset should use a read-only root with tmpfs: %+v", strict)
+	}
+	if _, ok := SecurityPreset("huge"); ok {
+		t.Error("unknown preset should not resolve")
+	}
+}
+
+func TestSecurityOptsValidate(t *testing.T) {
+	valid := DefaultSecurityOpts()
+	valid.CapAdd = []string{"NET_BIND_SERVICE", "cap_sys_ptrace"}
+	valid.MemoryLimit, valid.CPULimit = "512m", "1.5"
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid opts rejected: %v", err)
+	}
+	for name, mutate := range map[string]func(*SecurityOpts){
+		"dangerous cap":  func(o *SecurityOpts) { o.CapAdd = []string{"SYS_ADMIN"} },
+		"bad cpus":       func(o *SecurityOpts) { o.CPULimit = "two" },
+		"negative cpus":  func(o *SecurityOpts) { o.CPULimit = "-1" },
+		"bad memory":     func(o *SecurityOpts) { o.MemoryLimit = "lots" },
+		"negative pids":  func(o *SecurityOpts) { o.PidsLimit = -1 },
+		"net admin caps": func(o *SecurityOpts) { o.CapAdd = []string{"NET_ADMIN"} },
+	} {
+		opts := DefaultSecurityOpts()
+		mutate(&opts)
+		if err := opts.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSecurityArgs(t *testing.T) {
+	opts, _ := SecurityPreset("strict")
+	opts.CapAdd = []string{"cap_chown"}
+	joined := strings.Join(securityArgs(opts), " ")
+	for _, want := range []string{
+		"--cap-drop=ALL --cap-add=CHOWN",
+		"--security-opt no-new-privileges",
+		"--memory 2g",
+		"--cpus 2",
+		"--pids-limit 512",
+		"--read-only --tmpfs /tmp",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("security args missing %q: %s", want, joined)
+		}
+	}
+	if got := strings.Join(securityArgs(SecurityOpts{}), " "); got != strings.Join(securityArgs(DefaultSecurityOpts()), " ") {
+		t.Errorf("empty opts should fall back to defaults, got %s", got)
+	}
+}
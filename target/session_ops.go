@@ -1,6 +1,7 @@
 package target
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/jfox85/devx/session"
@@ -24,7 +25,15 @@ type SessionOperator interface {
 
 type hostSessionOperator struct{}
 type dockerSessionOperator struct{}
+type podmanSessionOperator struct{}
+
+// composeSessionOperator drives the dev container of a compose session
+// exactly like a docker session.
+type composeSessionOperator struct{ dockerSessionOperator }
+
 type gatepostSessionOperator struct{}
+type sandboxSessionOperator struct{}
+type remoteSessionOperator struct{}
 
 // RuntimeName returns a human-readable runtime identifier for status messages.
 func RuntimeName(meta session.TargetMeta) string {
@@ -40,10 +49,23 @@ func ResolveSessionOperator(meta session.TargetMeta) (SessionOperator, error) {
 		return hostSessionOperator{}, nil
 	case "docker":
 		return dockerSessionOperator{}, nil
+	case "podman":
+		return podmanSessionOperator{}, nil
+	case "compose":
+		return composeSessionOperator{}, nil
 	case "gatepost":
 		return gatepostSessionOperator{}, nil
+	case "sandbox":
+		return sandboxSessionOperator{}, nil
+	case "remote":
+		return remoteSessionOperator{}, nil
 	default:
-		return nil, fmt.Errorf("unknown target type %q (valid: host, docker, gatepost)", meta.Type)
+		if IsValidPluginName(meta.Type) {
+			if p, err := LookupPlugin(meta.Type); err == nil {
+				return pluginSessionOperator{p}, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown target type %q (valid: host, docker, podman, compose, gatepost, sandbox, remote, or a %s<name> plugin on PATH)", meta.Type, PluginPrefix)
 	}
 }
 
@@ -101,6 +123,37 @@ func (dockerSessionOperator) KillTmuxServer(meta session.TargetMeta) error {
 	return ExecInSession(meta, []string{"tmux", "kill-server"}, false).Run()
 }
 
+func (podmanSessionOperator) IsRunning(meta session.TargetMeta) bool { return IsPodmanRunning(meta) }
+
+func (podmanSessionOperator) EnsureTmuxSession(name string, sess *session.Session) error {
+	if sess.Target.ContainerName == "" {
+		return fmt.Errorf("podman session %q has no runtime container", name)
+	}
+	return session.EnsureTmuxSessionInContainer(name, sess.Target.ContainerName, sess)
+}
+
+func (op podmanSessionOperator) AttachTmuxSession(name string, sess *session.Session) error {
+	if err := op.EnsureTmuxSession(name, sess); err != nil {
+		return err
+	}
+	wait, err := op.StartReadyTmuxSession(name, sess)
+	if err != nil {
+		return err
+	}
+	return wait()
+}
+
+func (podmanSessionOperator) StartReadyTmuxSession(name string, _ *session.Session) (func() error, error) {
+	return session.StartReadyTmuxSession(name)
+}
+
+func (podmanSessionOperator) KillTmuxServer(meta session.TargetMeta) error {
+	if meta.ContainerName == "" {
+		return nil
+	}
+	return ExecInSession(meta, []string{"tmux", "kill-server"}, false).Run()
+}
+
 func (gatepostSessionOperator) IsRunning(meta session.TargetMeta) bool { return IsDockerRunning(meta) }
 
 func (gatepostSessionOperator) EnsureTmuxSession(name string, sess *session.Session) error {
@@ -127,6 +180,80 @@ func (gatepostSessionOperator) StartReadyTmuxSession(name string, _ *session.Ses
 
 func (gatepostSessionOperator) KillTmuxServer(_ session.TargetMeta) error { return nil }
 
+func (sandboxSessionOperator) IsRunning(_ session.TargetMeta) bool {
+	return CheckSandboxAvailable() == nil
+}
+
+func (sandboxSessionOperator) EnsureTmuxSession(name string, sess *session.Session) error {
+	if sess.Target.Sandbox.Worktree == "" {
+		return fmt.Errorf("sandbox session %q has no recorded sandbox layout", name)
+	}
+	return session.EnsureTmuxSessionInSandbox(name, sess, sandboxCommandPrefix(sess.Target))
+}
+
+func (op sandboxSessionOperator) AttachTmuxSession(name string, sess *session.Session) error {
+	if err := op.EnsureTmuxSession(name, sess); err != nil {
+		return err
+	}
+	wait, err := op.StartReadyTmuxSession(name, sess)
+	if err != nil {
+		return err
+	}
+	return wait()
+}
+
+func (sandboxSessionOperator) StartReadyTmuxSession(name string, _ *session.Session) (func() error, error) {
+	return session.StartReadyTmuxSession(name)
+}
+
+// KillTmuxServer is a no-op: sandbox panes live on the host tmux server.
+func (sandboxSessionOperator) KillTmuxServer(_ session.TargetMeta) error { return nil }
+
+func (remoteSessionOperator) IsRunning(meta session.TargetMeta) bool { return IsRemoteRunning(meta) }
+
+// EnsureTmuxSession reconnects the control master if needed, starts the tmux
+// session on the remote host, and mirrors it into a local tmux session whose
+// pane attaches over ssh, so TUI previews and the web terminal work unchanged.
+func (remoteSessionOperator) EnsureTmuxSession(name string, sess *session.Session) error {
+	rm := sess.Target.Remote
+	if rm.Host == "" {
+		return fmt.Errorf("remote session %q has no recorded host", name)
+	}
+	ctx := context.Background()
+	if err := EnsureRemoteMaster(ctx, sess.Target); err != nil {
+		return err
+	}
+	if err := remoteRun(ctx, rm, ensureRemoteTmuxScript(rm), nil); err != nil {
+		return fmt.Errorf("start remote tmux session on %s: %w", rm.Host, err)
+	}
+	return session.EnsureTmuxSessionRemote(name, remoteAttachCommand(rm))
+}
+
+func (op remoteSessionOperator) AttachTmuxSession(name string, sess *session.Session) error {
+	if err := op.EnsureTmuxSession(name, sess); err != nil {
+		return err
+	}
+	wait, err := op.StartReadyTmuxSession(name, sess)
+	if err != nil {
+		return err
+	}
+	return wait()
+}
+
+func (remoteSessionOperator) StartReadyTmuxSession(name string, _ *session.Session) (func() error, error) {
+	return session.StartReadyTmuxSession(name)
+}
+
+// KillTmuxServer kills only the session's tmux session on the remote host;
+// the remote tmux server is shared with other sessions.
+func (remoteSessionOperator) KillTmuxServer(meta session.TargetMeta) error {
+	if meta.Remote.Host == "" || !IsRemoteRunning(meta) {
+		return nil
+	}
+	return remoteRun(context.Background(), meta.Remote,
+		"tmux kill-session -t "+shellQuote("="+meta.Remote.TmuxSession)+" 2>/dev/null || true", nil)
+}
+
 // IsRunning reports whether the target runtime needed for session commands is available.
 func IsRunning(meta session.TargetMeta) bool {
 	op, err := ResolveSessionOperator(meta)
@@ -16,7 +16,11 @@ func TestResolveSessionOperator(t *testing.T) {
 		{"", "target.hostSessionOperator"},
 		{"host", "target.hostSessionOperator"},
 		{"docker", "target.dockerSessionOperator"},
+		{"podman", "target.podmanSessionOperator"},
+		{"compose", "target.composeSessionOperator"},
 		{"gatepost", "target.gatepostSessionOperator"},
+		{"sandbox", "target.sandboxSessionOperator"},
+		{"remote", "target.remoteSessionOperator"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.typ, func(t *testing.T) {
@@ -44,10 +48,16 @@ func TestSessionOperationDispatchErrors(t *testing.T) {
 	if err := EnsureTmuxSession("demo", &session.Session{Target: session.TargetMeta{Type: "gatepost"}}); err == nil || !strings.Contains(err.Error(), "no runtime container") {
 		t.Fatalf("gatepost without container should fail with runtime-container error, got %v", err)
 	}
+	if err := EnsureTmuxSession("demo", &session.Session{Target: session.TargetMeta{Type: "sandbox"}}); err == nil || !strings.Contains(err.Error(), "no recorded sandbox layout") {
+		t.Fatalf("sandbox without layout should fail, got %v", err)
+	}
+	if err := EnsureTmuxSession("demo", &session.Session{Target: session.TargetMeta{Type: "remote"}}); err == nil || !strings.Contains(err.Error(), "no recorded host") {
+		t.Fatalf("remote without host should fail, got %v", err)
+	}
 }
 
 func TestNoopKillTmuxServerTargets(t *testing.T) {
-	for _, typ := range []string{"", "host", "gatepost"} {
+	for _, typ := range []string{"", "host", "gatepost", "sandbox", "remote"} {
 		if err := KillTmuxServer(session.TargetMeta{Type: typ}); err != nil {
 			t.Fatalf("KillTmuxServer(%q) should be no-op, got %v", typ, err)
 		}
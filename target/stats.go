@@ -0,0 +1,194 @@
+package target
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jfox85/devx/session"
+)
+
+// ResourceStats is a point-in-time resource snapshot of a session container.
+type ResourceStats struct {
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryUsage   string  `json:"memory_usage"` // e.g. "512MiB"
+	MemoryLimit   string  `json:"memory_limit"` // e.g. "4GiB"
+	MemoryPercent float64 `json:"memory_percent"`
+	PIDs          int     `json:"pids"`
+	OOMKills      int     `json:"oom_kills"` // processes killed at the memory limit
+}
+
+// AtMemoryLimit reports whether the container hit its memory limit: a process
+// was OOM-killed, or usage is within 5% of the limit.
+func (s *ResourceStats) AtMemoryLimit() bool {
+	return s != nil && (s.OOMKills > 0 || s.MemoryPercent >= 95)
+}
+
+// String renders the stats compactly, e.g. "cpu 3.2% mem 512MiB/4GiB pids 34".
+func (s *ResourceStats) String() string {
+	if s == nil {
+		return "-"
+	}
+	out := fmt.Sprintf("cpu %.1f%% mem %s/%s pids %d", s.CPUPercent, s.MemoryUsage, s.MemoryLimit, s.PIDs)
+	if s.OOMKills > 0 {
+		out += fmt.Sprintf(" oom %d", s.OOMKills)
+	}
+	return out
+}
+
+// HasResourceStats reports whether a session's target runs in a container the
+// runtime can report stats for.
+func HasResourceStats(meta session.TargetMeta) bool {
+	switch meta.Type {
+	case "docker", "podman", "compose", "gatepost":
+		return meta.ContainerName != ""
+	}
+	return false
+}
+
+// CollectResourceStats samples every containerized session in one call per
+// container runtime. Sessions whose container is not running are absent from
+// the result.
+func CollectResourceStats(ctx context.Context, metas map[string]session.TargetMeta) map[string]*ResourceStats {
+	byCLI := make(map[string]map[string]string) // cli -> container -> session
+	for name, meta := range metas {
+		if !HasResourceStats(meta) {
+			continue
+		}
+		cli := session.ContainerCLI(meta)
+		if byCLI[cli] == nil {
+			byCLI[cli] = make(map[string]string)
+		}
+		byCLI[cli][meta.ContainerName] = name
+	}
+
+	result := make(map[string]*ResourceStats)
+	for cli, containers := range byCLI {
+		names := make([]string, 0, len(containers))
+		for c := range containers {
+			names = append(names, c)
+		}
+		out, err := cliOutput(ctx, cli, append([]string{"stats", "--no-stream", "--format", "{{json .}}"}, names...)...)
+		if err != nil {
+			// docker stats fails outright if any container is gone; retry
+			// one by one so the running ones are still reported.
+			var parts []string
+			for _, c := range names {
+				if o, err := cliOutput(ctx, cli, "stats", "--no-stream", "--format", "{{json .}}", c); err == nil {
+					parts = append(parts, o)
+				}
+			}
+			out = strings.Join(parts, "\n")
+		}
+		stats := parseContainerStats(out)
+		ooms := containerOOMKills(ctx, cli, names)
+		for container, st := range stats {
+			sessionName, ok := containers[container]
+			if !ok {
+				continue
+			}
+			st.OOMKills = ooms[container]
+			result[sessionName] = st
+		}
+	}
+	return result
+}
+
+// parseContainerStats parses `stats --format '{{json .}}'` output from docker
+// or podman, keyed by container name.
+func parseContainerStats(out string) map[string]*ResourceStats {
+	stats := make(map[string]*ResourceStats)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var fields map[string]interface{}
+		if json.Unmarshal([]byte(line), &fields) != nil {
+			continue
+		}
+		get := func(keys ...string) string {
+			for _, k := range keys {
+				for fk, v := range fields {
+					if strings.EqualFold(fk, k) {
+						return strings.TrimSpace(fmt.Sprint(v))
+					}
+				}
+			}
+			return ""
+		}
+		name := get("Name", "Container")
+		if name == "" {
+			continue
+		}
+		st := &ResourceStats{
+			CPUPercent:    parsePercent(get("CPUPerc", "CPU")),
+			MemoryPercent: parsePercent(get("MemPerc", "Mem")),
+		}
+		usage, limit, _ := strings.Cut(get("MemUsage"), "/")
+		st.MemoryUsage = strings.ReplaceAll(strings.TrimSpace(usage), " ", "")
+		st.MemoryLimit = strings.ReplaceAll(strings.TrimSpace(limit), " ", "")
+		st.PIDs, _ = strconv.Atoi(get("PIDs", "PIDS"))
+		stats[strings.TrimPrefix(name, "/")] = st
+	}
+	return stats
+}
+
+func parsePercent(s string) float64 {
+	v, _ := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	return v
+}
+
+// containerOOMKills counts OOM kills per container. Docker reports an "oom"
+// event for every process the kernel kills in the container's cgroup; the
+// inspect OOMKilled flag only covers the main process and is the fallback
+// for runtimes without those events.
+func containerOOMKills(ctx context.Context, cli string, containers []string) map[string]int {
+	kills := make(map[string]int)
+	if cli == "docker" {
+		args := []string{"events", "--since", "720h", "--until", "0s", "--filter", "event=oom", "--format", "{{.Actor.Attributes.name}}"}
+		for _, c := range containers {
+			args = append(args, "--filter", "container="+c)
+		}
+		if out, err := cliOutput(ctx, cli, args...); err == nil {
+			for _, line := range strings.Split(out, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					kills[line]++
+				}
+			}
+		}
+	}
+	for _, c := range containers {
+		if kills[c] > 0 {
+			continue
+		}
+		if out, err := cliOutput(ctx, cli, "inspect", "--format", "{{.State.OOMKilled}}", c); err == nil && out == "true" {
+			kills[c] = 1
+		}
+	}
+	return kills
+}
+
+// RecordOOMKills raises the attention flag on sessions whose containers had
+// OOM kills since the count last recorded in their metadata.
+func RecordOOMKills(store *session.SessionStore, stats map[string]*ResourceStats) {
+	for name, st := range stats {
+		sess, ok := store.GetSession(name)
+		if !ok || st.OOMKills <= sess.Target.OOMKills {
+			continue
+		}
+		kills := st.OOMKills
+		_ = store.UpdateSession(name, func(s *session.Session) {
+			s.Target.OOMKills = kills
+			s.AttentionFlag = true
+			s.AttentionReason = fmt.Sprintf("Out of memory: process killed at the %s limit", st.MemoryLimit)
+			s.AttentionSource = "oom"
+			s.AttentionTime = time.Now()
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package target
+
+import "testing"
+
+func TestParseContainerStats(t *testing.T) {
+	docker := `{"BlockIO":"0B / 0B","CPUPerc":"3.25%","Container":"abc","ID":"abc","MemPerc":"12.50%","MemUsage":"512MiB / 4GiB","Name":"devx-feat","NetIO":"1kB / 2kB","PIDs":"34"}`
+	podman := `{"ID":"def","Name":"devx-pod","CPUPerc":"0.50%","MemUsage":"3.9GB / 4GB","MemPerc":"97.50%","PIDS":"7"}`
+	stats := parseContainerStats(docker + "\n" + podman + "\nnot json\n")
+
+	feat := stats["devx-feat"]
+	if feat == nil {
+		t.Fatalf("docker stats not parsed: %v", stats)
+	}
+	if feat.CPUPercent != 3.25 || feat.MemoryPercent != 12.5 || feat.MemoryUsage != "512MiB" || feat.MemoryLimit != "4GiB" || feat.PIDs != 34 {
+		t.Errorf("docker stats = %+v", feat)
+	}
+	if feat.AtMemoryLimit() {
+		t.Error("12.5% memory is not at the limit")
+	}
+
+	pod := stats["devx-pod"]
+	if pod == nil || pod.PIDs != 7 || pod.MemoryLimit != "4GB" {
+		t.Fatalf("podman stats = %+v", pod)
+	}
+	if !pod.AtMemoryLimit() {
+		t.Error("97.5% memory should count as at the limit")
+	}
+}
+
+func TestResourceStatsString(t *testing.T) {
+	var none *ResourceStats
+	if none.String() != "-" || none.AtMemoryLimit() {
+		t.Error("nil stats should render as - and not be at the limit")
+	}
+	st := &ResourceStats{CPUPercent: 1.26, MemoryUsage: "1GiB", MemoryLimit: "2GiB", PIDs: 3, OOMKills: 2}
+	if got := st.String(); got != "cpu 1.3% mem 1GiB/2GiB pids 3 oom 2" {
+		t.Errorf("String() = %q", got)
+	}
+	if !st.AtMemoryLimit() {
+		t.Error("OOM kills mean the memory limit was hit")
+	}
+}
@@ -1,6 +1,7 @@
 // Package target defines the execution environment abstraction for DevX sessions.
 // A Target controls where a session's processes run: on the host, in a Docker
-// container, or (future) in a VM or remote server.
+// or Podman container (optionally beside a Compose stack), in a bubblewrap
+// sandbox, on a remote SSH host, or (future) in a VM.
 package target
 
 import (
@@ -30,6 +31,12 @@ type StartOpts struct {
 	Labels         map[string]string
 	Security       SecurityOpts
 	GatepostConfig GatepostRuntimeConfig
+	Sandbox        SandboxRuntimeConfig
+	Remote         RemoteRuntimeConfig
+	Compose        ComposeRuntimeConfig
+	Devcontainer   *Devcontainer        // docker target only; nil runs Image with devx defaults
+	Caches         []CacheMount         // shared project cache volumes
+	Network        NetworkRuntimeConfig // docker target only; egress fencing
 }
 
 // GatepostRuntimeConfig is the trusted host-side contract DevX passes to the
@@ -41,6 +48,9 @@ type GatepostRuntimeConfig struct {
 	ProviderBootstrapCommand string
 	AuthHome                 string
 	RequiredProviders        string
+	Policy                   []byte               // effective egress policy YAML; the default policy when empty
+	InstallCommands          []string             // run in the agent under the install phase before switching to run
+	Secrets                  []GatepostSecretSpec // gatepost.secrets entries injected by the proxy
 }
 
 // StartResult is returned by Target.Start with metadata to persist.
@@ -55,9 +65,22 @@ func Resolve(targetType string) (Target, error) {
 		return &HostTarget{}, nil
 	case "docker":
 		return &DockerTarget{}, nil
+	case "podman":
+		return &PodmanTarget{}, nil
+	case "compose":
+		return &ComposeTarget{}, nil
 	case "gatepost":
 		return &GatepostTarget{}, nil
+	case "sandbox":
+		return &SandboxTarget{}, nil
+	case "remote":
+		return &RemoteTarget{}, nil
 	default:
-		return nil, fmt.Errorf("unknown target type %q (valid: host, docker, gatepost)", targetType)
+		if IsValidPluginName(targetType) {
+			if p, err := LookupPlugin(targetType); err == nil {
+				return p, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown target type %q (valid: host, docker, podman, compose, gatepost, sandbox, remote, or a %s<name> plugin on PATH)", targetType, PluginPrefix)
 	}
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jfox85/devx/session"
@@ -18,6 +20,10 @@ func TestResolve(t *testing.T) {
 		{"", "host", false},
 		{"host", "host", false},
 		{"docker", "docker", false},
+		{"podman", "podman", false},
+		{"compose", "compose", false},
+		{"sandbox", "sandbox", false},
+		{"remote", "remote", false},
 		{"vm", "", true},
 		{"invalid", "", true},
 	}
@@ -172,6 +178,137 @@ func TestExecInSessionDockerInteractive(t *testing.T) {
 	}
 }
 
+func TestExecInSessionPodman(t *testing.T) {
+	meta := session.TargetMeta{Type: "podman", ContainerName: "devx-test"}
+
+	cmd := ExecInSession(meta, []string{"/bin/bash"}, true)
+	want := []string{"podman", "exec", "-it", "devx-test", "/bin/bash"}
+	if len(cmd.Args) != len(want) {
+		t.Fatalf("podman exec -it: args = %v, want %v", cmd.Args, want)
+	}
+	for i, a := range cmd.Args {
+		if a != want[i] {
+			t.Errorf("args[%d] = %q, want %q", i, a, want[i])
+		}
+	}
+}
+
+func TestContainerRunArgsPodman(t *testing.T) {
+	args, image := containerRunArgs("devx-demo", "devx-demo-net", StartOpts{
+		WorktreePath: "/tmp/wt",
+		HostPorts:    map[string]int{"web": 3000},
+		Security:     DefaultSecurityOpts(),
+	}, "--userns=keep-id")
+	if image != "devx-session-base:latest" {
+		t.Errorf("image = %q, want default base image", image)
+	}
+	joined := strings.Join(args, " ")
+	for _, want := range []string{
+		"run -d --userns=keep-id --name devx-demo",
+		"--network devx-demo-net",
+		"-v /tmp/wt:/workspace",
+		"-p 127.0.0.1:3000:3000",
+		"--cap-drop=ALL",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("run args missing %q: %s", want, joined)
+		}
+	}
+}
+
+func TestExecInSessionSandbox(t *testing.T) {
+	meta := session.TargetMeta{Type: "sandbox", Sandbox: session.SandboxMeta{
+		Home:     "/home/dev",
+		Worktree: "/home/dev/.devx/worktrees/feat",
+		Env:      map[string]string{"WEB_PORT": "3000"},
+	}}
+
+	cmd := ExecInSession(meta, []string{"ls", "-la"}, true)
+	if cmd.Args[0] != "bwrap" {
+		t.Fatalf("sandbox exec should run bwrap, got %v", cmd.Args)
+	}
+	n := len(cmd.Args)
+	if cmd.Args[n-3] != "--" || cmd.Args[n-2] != "ls" || cmd.Args[n-1] != "-la" {
+		t.Fatalf("command should follow --: %v", cmd.Args)
+	}
+}
+
+func TestSandboxArgsLayout(t *testing.T) {
+	meta := session.SandboxMeta{
+		Home:           "/home/dev",
+		Worktree:       "/home/dev/.devx/worktrees/feat",
+		GitDir:         "/home/dev/src/app/.git",
+		WorktreeGitDir: "/home/dev/src/app/.git/worktrees/feat",
+		NoNetwork:      true,
+		ReadOnly:       []string{"/home/dev/.cargo"},
+		Hide:           []string{"/etc/ssh"},
+		Env:            map[string]string{"WEB_PORT": "3000"},
+	}
+	args := sandboxArgs(meta)
+	joined := strings.Join(args, " ")
+
+	for _, want := range []string{
+		"--unshare-net",
+		"--tmpfs /home/dev",
+		"--ro-bind-try /home/dev/.cargo /home/dev/.cargo",
+		"--tmpfs /etc/ssh",
+		"--ro-bind /home/dev/src/app/.git /home/dev/src/app/.git",
+		"--bind-try /home/dev/src/app/.git/objects /home/dev/src/app/.git/objects",
+		"--bind-try /home/dev/src/app/.git/refs /home/dev/src/app/.git/refs",
+		"--bind /home/dev/src/app/.git/worktrees/feat /home/dev/src/app/.git/worktrees/feat",
+		"--ro-bind-try /home/dev/src/app/.git/worktrees/feat/config.worktree /home/dev/src/app/.git/worktrees/feat/config.worktree",
+		"--bind /home/dev/.devx/worktrees/feat /home/dev/.devx/worktrees/feat",
+		"--chdir /home/dev/.devx/worktrees/feat",
+		"--clearenv",
+		"--setenv HOME /home/dev",
+		"--setenv WEB_PORT 3000",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("sandbox args missing %q: %s", want, joined)
+		}
+	}
+	if strings.Index(joined, "--tmpfs /home/dev ") > strings.Index(joined, "--bind /home/dev/.devx") {
+		t.Errorf("home must be masked before the worktree is bound: %s", joined)
+	}
+	// hooks/ and config stay under the read-only bind of the git dir.
+	if strings.Contains(joined, "--bind /home/dev/src/app/.git ") || strings.Contains(joined, ".git/hooks") || strings.Contains(joined, ".git/config ") {
+		t.Errorf("git hooks and config must not be writable: %s", joined)
+	}
+	if args[len(args)-1] != "--" {
+		t.Errorf("sandbox args should end with --, got %q", args[len(args)-1])
+	}
+
+	meta.NoNetwork = false
+	if strings.Contains(strings.Join(sandboxArgs(meta), " "), "--unshare-net") {
+		t.Error("network should be shared unless no_network is set")
+	}
+}
+
+func TestSandboxGitDirsOfLinkedWorktree(t *testing.T) {
+	repo := t.TempDir()
+	worktree := filepath.Join(t.TempDir(), "feat")
+	for _, args := range [][]string{
+		{"init", "-q", repo},
+		{"-C", repo, "-c", "user.email=t@example.com", "-c", "user.name=t", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"-C", repo, "worktree", "add", "-q", worktree},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	gitDir, worktreeGitDir := sandboxGitDirs(context.Background(), worktree)
+	want, _ := filepath.EvalSymlinks(filepath.Join(repo, ".git"))
+	if got, _ := filepath.EvalSymlinks(gitDir); got != want {
+		t.Errorf("git dir = %s, want %s", gitDir, want)
+	}
+	if got, _ := filepath.EvalSymlinks(worktreeGitDir); got != filepath.Join(want, "worktrees", "feat") {
+		t.Errorf("worktree git dir = %s", worktreeGitDir)
+	}
+	if gitDir, worktreeGitDir := sandboxGitDirs(context.Background(), repo); gitDir != "" || worktreeGitDir != "" {
+		t.Errorf("main checkout should bind nothing extra, got %s, %s", gitDir, worktreeGitDir)
+	}
+}
+
 func TestDefaultSecurityOpts(t *testing.T) {
 	opts := DefaultSecurityOpts()
 	if opts.MemoryLimit != "4g" {
@@ -25,7 +25,9 @@ import (
 	"github.com/jfox85/devx/caddy"
 	"github.com/jfox85/devx/claude"
 	"github.com/jfox85/devx/config"
+	"github.com/jfox85/devx/egress"
 	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
 	"github.com/jfox85/devx/update"
 	"github.com/jfox85/devx/version"
 	"github.com/spf13/viper"
@@ -53,6 +55,7 @@ type sessionItem struct {
 	gatepostMode      string
 	pinned            bool
 	activityAt        time.Time
+	targetMeta        session.TargetMeta
 }
 
 type sessionViewMode string
@@ -130,6 +133,7 @@ const (
 	stateProjectAdd
 	stateRenaming
 	stateAskApproval
+	stateEgressApproval
 )
 
 type model struct {
@@ -144,6 +148,7 @@ type model struct {
 	confirmFunc     func()
 	deleteTarget    string
 	pendingAsk      *ask.Request
+	pendingEgress   *egress.Request
 	width           int
 	height          int
 	err             error
@@ -181,6 +186,9 @@ type model struct {
 	gitStatsTTLOthers       time.Duration
 	baseBranchTTL           time.Duration
 	maxStatsUpdatesPerCycle int
+	// Container resource stats for the selected session
+	resourceStatsCache map[string]resourceStatsEntry
+	resourceStatsTTL   time.Duration
 	// MRU slots
 	numberedSlots     map[int]string // slot number -> session name
 	slotsBootstrapped bool           // true after first bootstrap pass
@@ -370,6 +378,13 @@ func (m *model) ensureCursorVisible() {
 	}
 }
 
+// resourceStatsEntry holds the last container stats sample for a session.
+// updatedAt is set when a sample is requested so one is in flight at a time.
+type resourceStatsEntry struct {
+	stats     *target.ResourceStats
+	updatedAt time.Time
+}
+
 // gitStatsEntry holds cached additions/deletions for a repo+branch
 type gitStatsEntry struct {
 	additions int
@@ -582,6 +597,8 @@ func initialModel(persistence tuiPersistence) *model {
 		gitStatsTTLOthers:       2 * time.Minute,
 		baseBranchTTL:           time.Hour,
 		maxStatsUpdatesPerCycle: 5,
+		resourceStatsCache:      make(map[string]resourceStatsEntry),
+		resourceStatsTTL:        10 * time.Second,
 		numberedSlots:           make(map[int]string),
 		sessionView:             normalizedSessionView(initialView),
 		persistence:             persistence,
@@ -596,7 +613,7 @@ func initialModel(persistence tuiPersistence) *model {
 }
 
 func (m *model) Init() tea.Cmd {
-	return tea.Batch(m.loadSessions, m.loadPendingAsk, m.refreshPreview(), m.refreshSessions(), m.checkCaddyHealth(), m.checkForUpdates)
+	return tea.Batch(m.loadSessions, m.loadPendingAsk, m.loadPendingEgress, m.refreshPreview(), m.refreshSessions(), m.checkCaddyHealth(), m.checkForUpdates)
 }
 
 type pendingAskMsg struct{ req *ask.Request }
@@ -611,6 +628,22 @@ func (m *model) loadPendingAsk() tea.Msg {
 	return pendingAskMsg{req: reqs[0]}
 }
 
+type pendingEgressMsg struct{ req *egress.Request }
+
+type egressHandledMsg struct{ status string }
+
+func (m *model) loadPendingEgress() tea.Msg {
+	store := egress.NewStore()
+	if _, err := store.SyncSessions(); err != nil {
+		return nil
+	}
+	reqs, err := store.Pending("")
+	if err != nil || len(reqs) == 0 {
+		return nil
+	}
+	return pendingEgressMsg{req: reqs[0]}
+}
+
 func (m *model) loadSessions() tea.Msg {
 	store, err := session.LoadSessions()
 	if err != nil {
@@ -662,6 +695,7 @@ func (m *model) loadSessions() tea.Msg {
 			gatepostMode:      sess.Target.Gatepost.ProviderMode,
 			pinned:            sess.Pinned,
 			activityAt:        activityAt,
+			targetMeta:        sess.Target,
 		})
 	}
 
@@ -735,6 +769,10 @@ type attachToNewSessionMsg struct {
 }
 type refreshPreviewMsg struct{}
 type refreshSessionsMsg struct{}
+type resourceStatsMsg struct {
+	sessionName string
+	stats       *target.ResourceStats
+}
 type gitStatsMsg struct {
 	key         string
 	additions   int
@@ -807,6 +845,34 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
+		case stateEgressApproval:
+			if key.Matches(msg, m.keys.Quit) {
+				return m, tea.Quit
+			}
+			req := m.pendingEgress
+			switch strings.ToLower(msg.String()) {
+			case "o":
+				m.pendingEgress = nil
+				m.state = stateList
+				return m, allowPendingEgress(req, egress.ScopeOnce)
+			case "s":
+				m.pendingEgress = nil
+				m.state = stateList
+				return m, allowPendingEgress(req, egress.ScopeSession)
+			case "p":
+				m.pendingEgress = nil
+				m.state = stateList
+				return m, allowPendingEgress(req, egress.ScopeProject)
+			case "n":
+				m.pendingEgress = nil
+				m.state = stateList
+				return m, denyPendingEgress(req)
+			case "esc":
+				m.pendingEgress = nil
+				m.state = stateList
+				return m, nil
+			}
+
 		case stateList:
 			// When filter is active, intercept keys for the search input
 			if m.filterActive {
@@ -1427,6 +1493,15 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// Sample container resources for the selected session only.
+		if selectedName != "" && target.HasResourceStats(m.sessions[m.cursor].targetMeta) {
+			entry := m.resourceStatsCache[selectedName]
+			if now.Sub(entry.updatedAt) > m.resourceStatsTTL {
+				m.resourceStatsCache[selectedName] = resourceStatsEntry{stats: entry.stats, updatedAt: now}
+				cmds = append(cmds, queueResourceStats(selectedName, m.sessions[m.cursor].targetMeta))
+			}
+		}
+
 		if len(cmds) > 0 {
 			return m, tea.Batch(cmds...)
 		}
@@ -1491,7 +1566,11 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case refreshSessionsMsg:
 		// Reload sessions to reflect changes and continue periodic refresh
 		m.lastSessionRefresh = time.Now()
-		return m, tea.Batch(m.loadSessions, m.loadPendingAsk, m.refreshSessions())
+		return m, tea.Batch(m.loadSessions, m.loadPendingAsk, m.loadPendingEgress, m.refreshSessions())
+
+	case resourceStatsMsg:
+		m.resourceStatsCache[msg.sessionName] = resourceStatsEntry{stats: msg.stats, updatedAt: time.Now()}
+		return m, nil
 
 	case gitStatsMsg:
 		// Update cache
@@ -1538,6 +1617,17 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.statusMsg = fmt.Sprintf("ask %s", msg.status)
 		return m, m.loadPendingAsk
 
+	case pendingEgressMsg:
+		if msg.req != nil && m.state == stateList {
+			m.pendingEgress = msg.req
+			m.state = stateEgressApproval
+		}
+		return m, nil
+
+	case egressHandledMsg:
+		m.statusMsg = msg.status
+		return m, m.loadPendingEgress
+
 	case errMsg:
 		m.err = msg.err
 		m.state = stateList
@@ -1584,6 +1674,8 @@ func (m *model) View() string {
 		content = m.renameView()
 	case stateAskApproval:
 		content = m.askApprovalView()
+	case stateEgressApproval:
+		content = m.egressApprovalView()
 	}
 
 	// Create footer with commands
@@ -1615,6 +1707,8 @@ func (m *model) View() string {
 			footer = m.renderFooter("enter: save rename • esc: cancel")
 		case stateAskApproval:
 			footer = m.renderFooter("y: approve once • a: approve always • n: deny • esc: dismiss • q: quit")
+		case stateEgressApproval:
+			footer = m.renderFooter("o: allow once • s: allow for session • p: add to project policy • n: deny • esc: dismiss • q: quit")
 		}
 	}
 
@@ -1865,6 +1959,10 @@ func (m *model) listView() string {
 			b.WriteString(warningStyle.Render(m.caddyWarning) + "\n\n")
 		}
 
+		if banner := m.gatepostBypassBanner(time.Now()); banner != "" {
+			b.WriteString(warningStyle.Render(banner) + "\n\n")
+		}
+
 		entries := m.buildFilteredEntries()
 		m.renderSessionList(&b, entries, true, m.nonPreviewSessionBudget())
 		m.renderSearchBox(&b, m.width)
@@ -1896,6 +1994,10 @@ func (m *model) listView() string {
 		sessionList.WriteString(warningStyle.Render("⚠️ "+condensedWarning) + "\n\n")
 	}
 
+	if banner := m.gatepostBypassBanner(time.Now()); banner != "" {
+		sessionList.WriteString(warningStyle.Render(banner) + "\n\n")
+	}
+
 	// The logo (when shown) sits above the panes; subtract its height so the panes
 	// are sized to fit in the remaining terminal space.
 	logoLineCount := 0
@@ -1967,9 +2069,12 @@ func (m *model) getSessionDetails(sess sessionItem) string {
 	if sess.gatepostEnabled {
 		state := "enforced"
 		if sess.gatepostBypass {
-			state = "bypass"
+			state = "bypass " + gatepostBypassRemaining(sess.targetMeta.Gatepost, time.Now())
 		}
 		details += fmt.Sprintf("    Gatepost: %s\n", state)
+		if reason := sess.targetMeta.Gatepost.BypassReason; sess.gatepostBypass && reason != "" {
+			details += fmt.Sprintf("      Reason: %s\n", reason)
+		}
 		if sess.gatepostMode != "" || len(sess.gatepostProviders) > 0 {
 			details += fmt.Sprintf("      Providers: %s via %s\n", strings.Join(sess.gatepostProviders, ","), sess.gatepostMode)
 		}
@@ -1978,6 +2083,17 @@ func (m *model) getSessionDetails(sess sessionItem) string {
 		}
 	}
 
+	if entry, ok := m.resourceStatsCache[sess.name]; ok && entry.stats != nil {
+		st := entry.stats
+		details += fmt.Sprintf("    Resources: cpu %.1f%%  mem %s / %s (%.0f%%)  pids %d\n",
+			st.CPUPercent, st.MemoryUsage, st.MemoryLimit, st.MemoryPercent, st.PIDs)
+		if st.OOMKills > 0 {
+			details += deletionsStyle.Render(fmt.Sprintf("      Memory limit hit: %d process(es) OOM-killed", st.OOMKills)) + "\n"
+		} else if st.AtMemoryLimit() {
+			details += deletionsStyle.Render("      Near memory limit") + "\n"
+		}
+	}
+
 	// Show Caddy routes (from already loaded session data)
 	if len(sess.routes) > 0 {
 		details += "    Routes:\n"
@@ -2260,6 +2376,37 @@ func (m *model) calculateOptimalListWidth() int {
 	return optimalWidth
 }
 
+// gatepostBypassBanner warns about Gatepost sessions whose egress currently
+// bypasses the proxy, with the time left on each.
+func (m *model) gatepostBypassBanner(now time.Time) string {
+	var parts []string
+	for _, sess := range m.sessions {
+		if sess.gatepostEnabled && sess.gatepostBypass {
+			parts = append(parts, fmt.Sprintf("%s (%s)", sess.name, gatepostBypassRemaining(sess.targetMeta.Gatepost, now)))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "⚠️ Gatepost bypass: " + strings.Join(parts, ", ")
+}
+
+// gatepostBypassRemaining describes how long a bypass has left, e.g.
+// "12m left", or "no expiry" for an open-ended one.
+func gatepostBypassRemaining(gp session.GatepostMeta, now time.Time) string {
+	if gp.BypassUntil == nil {
+		return "no expiry"
+	}
+	left := gp.BypassUntil.Sub(now)
+	if left <= 0 {
+		return "expiring"
+	}
+	if left < time.Minute {
+		return left.Round(time.Second).String() + " left"
+	}
+	return strings.TrimSuffix(left.Round(time.Minute).String(), "0s") + " left"
+}
+
 func (m *model) refreshPreview() tea.Cmd {
 	return tea.Tick(time.Millisecond*500, func(t time.Time) tea.Msg {
 		return refreshPreviewMsg{}
@@ -2339,6 +2486,20 @@ func (m *model) computeGitStats(sessionPath, branch string) (int, int, error) {
 }
 
 // queueGitStats returns a Cmd that computes stats and emits a gitStatsMsg
+// queueResourceStats samples one session's container and raises its
+// attention flag on new OOM kills.
+func queueResourceStats(name string, meta session.TargetMeta) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		stats := target.CollectResourceStats(ctx, map[string]session.TargetMeta{name: meta})
+		if store, err := session.LoadSessions(); err == nil {
+			target.RecordOOMKills(store, stats)
+		}
+		return resourceStatsMsg{sessionName: name, stats: stats[name]}
+	}
+}
+
 func (m *model) queueGitStats(path, branch, sessionName string) tea.Cmd {
 	key := m.statsKey(path, branch)
 	return func() tea.Msg {
@@ -2449,6 +2610,22 @@ func (m *model) askApprovalView() string {
 		dimStyle.Render("  Approving runs the configured responder command in the target worktree.") + "\n"
 }
 
+func (m *model) egressApprovalView() string {
+	if m.pendingEgress == nil {
+		return headerStyle.Render("Pending Egress") + "\n\n  No blocked hosts waiting for approval.\n"
+	}
+	req := m.pendingEgress
+	view := headerStyle.Render("Gatepost Blocked an Unknown Host") + "\n\n" +
+		fmt.Sprintf("  Session %q tried to reach %s (%s phase, blocked %d times).\n", req.Session, req.Host, req.Phase, req.Count)
+	if req.URL != "" {
+		view += "\n  " + req.Method + " " + req.URL + "\n"
+	}
+	if req.Reason != "" {
+		view += "  Reason: " + req.Reason + "\n"
+	}
+	return view + "\n" + dimStyle.Render("  Allowing for the session or project reloads the session's egress policy.") + "\n"
+}
+
 func (m *model) hostnamesView() string {
 	if len(m.hostnames) == 0 {
 		return headerStyle.Render("Caddy Hostnames") + "\n\n" +
@@ -2799,6 +2976,32 @@ func denyPendingAsk(req *ask.Request) tea.Cmd {
 	}
 }
 
+func allowPendingEgress(req *egress.Request, scope string) tea.Cmd {
+	return func() tea.Msg {
+		if req == nil {
+			return egressHandledMsg{status: "egress approval skipped"}
+		}
+		updated, err := egress.NewStore().Allow(context.Background(), req.ID, scope)
+		if err != nil {
+			return errMsg{err}
+		}
+		return egressHandledMsg{status: fmt.Sprintf("%s allowed (%s)", updated.Host, updated.Scope)}
+	}
+}
+
+func denyPendingEgress(req *egress.Request) tea.Cmd {
+	return func() tea.Msg {
+		if req == nil {
+			return egressHandledMsg{status: "egress deny skipped"}
+		}
+		updated, err := egress.NewStore().Deny(req.ID)
+		if err != nil {
+			return errMsg{err}
+		}
+		return egressHandledMsg{status: fmt.Sprintf("%s denied", updated.Host)}
+	}
+}
+
 func (m *model) loadHostnames(sessionName string) tea.Cmd {
 	return func() tea.Msg {
 		store, err := session.LoadSessions()
@@ -8,6 +8,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/jfox85/devx/session"
 )
 
 // newTestModel returns a minimal model configured for a given terminal height
@@ -462,3 +463,21 @@ func TestRenderSessionList_NoMatchingMessage(t *testing.T) {
 		t.Errorf("expected 'No matching sessions' message, got %q", w.String())
 	}
 }
+
+func TestGatepostBypassBanner(t *testing.T) {
+	now := time.Now()
+	until := now.Add(12*time.Minute + 10*time.Second)
+	m := &model{sessions: []sessionItem{
+		{name: "feat", gatepostEnabled: true, gatepostBypass: true, targetMeta: session.TargetMeta{Gatepost: session.GatepostMeta{Bypass: true, BypassUntil: &until}}},
+		{name: "api", gatepostEnabled: true, gatepostBypass: true},
+		{name: "docs", gatepostEnabled: true},
+	}}
+	got := m.gatepostBypassBanner(now)
+	if got != "⚠️ Gatepost bypass: feat (12m left), api (no expiry)" {
+		t.Fatalf("unexpected banner %q", got)
+	}
+	m.sessions = m.sessions[2:]
+	if got := m.gatepostBypassBanner(now); got != "" {
+		t.Fatalf("expected no banner, got %q", got)
+	}
+}
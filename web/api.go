@@ -17,6 +17,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -26,7 +27,10 @@ import (
 	"github.com/jfox85/devx/ask"
 	"github.com/jfox85/devx/caddy"
 	"github.com/jfox85/devx/config"
+	"github.com/jfox85/devx/egress"
+	"github.com/jfox85/devx/internal/duration"
 	"github.com/jfox85/devx/session"
+	"github.com/jfox85/devx/target"
 	"github.com/jfox85/devx/web/imagepolicy"
 	"github.com/spf13/viper"
 )
@@ -50,7 +54,10 @@ func registerAPIRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/projects", handleListProjects)
 	mux.HandleFunc("GET /api/settings", handleSettings)
 	mux.HandleFunc("GET /api/asks/pending", handleAskPending)
+	mux.HandleFunc("GET /api/asks/thread", handleAskThread)
 	mux.HandleFunc("POST /api/asks/approve", handleAskApprove)
+	mux.HandleFunc("GET /api/asks/approvals", handleAskApprovals)
+	mux.HandleFunc("DELETE /api/asks/approvals", handleAskRevokeApproval)
 	mux.HandleFunc("POST /api/asks/deny", handleAskDeny)
 	mux.HandleFunc("POST /api/switch-window", handleSwitchWindow)
 	mux.HandleFunc("POST /api/send-keys", handleSendKeys)
@@ -64,6 +71,10 @@ func registerAPIRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/sessions/pin", handlePinSession)
 	mux.HandleFunc("DELETE /api/sessions/pin", handlePinSession)
 	mux.HandleFunc("GET /api/gatepost/logs", handleGatepostLogsRedirect)
+	mux.HandleFunc("GET /api/gatepost/audit", handleGatepostAudit)
+	mux.HandleFunc("GET /api/gatepost/pending", handleEgressPending)
+	mux.HandleFunc("POST /api/gatepost/pending/allow", handleEgressAllow)
+	mux.HandleFunc("POST /api/gatepost/pending/deny", handleEgressDeny)
 	// Reverse-proxy the per-session Gatepost Logs UI so it is reachable wherever
 	// the devx web UI is (Caddy / Cloudflare tunnel), with the token injected
 	// server-side. Catch-all so branch-style session names (with slashes) work.
@@ -213,9 +224,41 @@ func handleAskPending(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{"requests": reqs})
 }
 
+func handleAskThread(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+	reqs, err := ask.NewStore().Thread(id)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"requests": reqs})
+}
+
 type askActionRequest struct {
-	ID     string `json:"id"`
-	Always bool   `json:"always"`
+	ID       string `json:"id"`
+	Always   bool   `json:"always"`
+	For      string `json:"for,omitempty"`      // with always: expire the approval, e.g. "7d"
+	Projects bool   `json:"projects,omitempty"` // with always: approve the two projects
+}
+
+// remember returns how an approval is kept, or nil for a one-off approval.
+func (b askActionRequest) remember() (*ask.Remember, error) {
+	if !b.Always {
+		return nil, nil
+	}
+	remember := &ask.Remember{Projects: b.Projects}
+	if b.For != "" {
+		d, err := duration.Parse(b.For)
+		if err != nil {
+			return nil, err
+		}
+		remember.For = d
+	}
+	return remember, nil
 }
 
 func handleAskApprove(w http.ResponseWriter, r *http.Request) {
@@ -224,11 +267,28 @@ func handleAskApprove(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
 		return
 	}
+	remember, err := body.remember()
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
 	store := ask.NewStore()
+	if ask.IsBroadcastID(body.ID) {
+		reqs, err := store.ApproveBroadcast(context.Background(), body.ID, ask.Policy{}, remember)
+		if reqs == nil {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+			return
+		}
+		resp := askBroadcastResponse(reqs)
+		if err != nil {
+			resp["error"] = err.Error()
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
 	var req *ask.Request
-	var err error
-	if body.Always {
-		req, err = store.ApproveAlwaysAndExecute(context.Background(), body.ID, ask.Policy{})
+	if remember != nil {
+		req, err = store.ApproveRememberAndExecute(context.Background(), body.ID, ask.Policy{}, *remember)
 	} else {
 		req, err = store.ApproveAndExecute(context.Background(), body.ID, ask.Policy{})
 	}
@@ -246,6 +306,15 @@ func handleAskDeny(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	store := ask.NewStore()
+	if ask.IsBroadcastID(body.ID) {
+		reqs, err := store.DenyBroadcast(body.ID)
+		if err != nil {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, askBroadcastResponse(reqs))
+		return
+	}
 	req, err := store.Deny(body.ID)
 	if err != nil {
 		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
@@ -254,6 +323,104 @@ func handleAskDeny(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, req)
 }
 
+func handleAskApprovals(w http.ResponseWriter, r *http.Request) {
+	approvals, err := ask.NewStore().Approvals()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	out := make([]map[string]any, 0, len(approvals.Approvals))
+	for _, a := range approvals.Approvals {
+		out = append(out, map[string]any{
+			"from":       a.From(),
+			"to":         a.To(),
+			"project":    a.IsProject(),
+			"created_at": a.CreatedAt,
+			"expires_at": a.ExpiresAt,
+			"expired":    a.Expired(now),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"approvals": out})
+}
+
+func handleAskRevokeApproval(w http.ResponseWriter, r *http.Request) {
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "from and to are required"})
+		return
+	}
+	n, err := ask.NewStore().Revoke(from, to)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if n == 0 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no approval from " + from + " to " + to})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"revoked": n})
+}
+
+// askBroadcastResponse reports a broadcast with its combined status and the
+// aggregated answer document.
+func askBroadcastResponse(reqs []*ask.Request) map[string]any {
+	return map[string]any{
+		"broadcast_id": reqs[0].BroadcastID,
+		"status":       ask.BroadcastStatus(reqs),
+		"document":     ask.RenderBroadcast(reqs),
+		"requests":     reqs,
+	}
+}
+
+func handleEgressPending(w http.ResponseWriter, r *http.Request) {
+	reqs, err := egress.NewStore().Pending(r.URL.Query().Get("name"))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"requests": reqs})
+}
+
+type egressActionRequest struct {
+	ID    string `json:"id"`
+	Scope string `json:"scope"`
+}
+
+func handleEgressAllow(w http.ResponseWriter, r *http.Request) {
+	var body egressActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.ID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+	switch body.Scope {
+	case egress.ScopeOnce, egress.ScopeSession, egress.ScopeProject:
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "scope must be once, session or project"})
+		return
+	}
+	req, err := egress.NewStore().Allow(r.Context(), body.ID, body.Scope)
+	if err != nil {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, req)
+}
+
+func handleEgressDeny(w http.ResponseWriter, r *http.Request) {
+	var body egressActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.ID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id is required"})
+		return
+	}
+	req, err := egress.NewStore().Deny(body.ID)
+	if err != nil {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, req)
+}
+
 func defaultStaleDays() int {
 	days := viper.GetInt("stale_sessions.threshold_days")
 	if days <= 0 || days > session.MaxStaleThresholdDays {
@@ -317,13 +484,17 @@ func handleLogin(w http.ResponseWriter, r *http.Request) {
 
 // sessionResponse is the JSON shape returned for each session.
 type gatepostResponse struct {
-	Enabled             bool     `json:"enabled"`
-	Runtime             string   `json:"runtime,omitempty"`
-	LogsURL             string   `json:"logs_url,omitempty"`
-	Bypass              bool     `json:"bypass,omitempty"`
-	ProviderMode        string   `json:"provider_mode,omitempty"`
-	RegisteredProviders []string `json:"registered_providers,omitempty"`
-	ProviderWarnings    []string `json:"provider_warnings,omitempty"`
+	Enabled             bool       `json:"enabled"`
+	Runtime             string     `json:"runtime,omitempty"`
+	LogsURL             string     `json:"logs_url,omitempty"`
+	Bypass              bool       `json:"bypass,omitempty"`
+	BypassUntil         *time.Time `json:"bypass_until,omitempty"`
+	BypassReason        string     `json:"bypass_reason,omitempty"`
+	Phase               string     `json:"phase,omitempty"`
+	PhaseRevertAt       *time.Time `json:"phase_revert_at,omitempty"`
+	ProviderMode        string     `json:"provider_mode,omitempty"`
+	RegisteredProviders []string   `json:"registered_providers,omitempty"`
+	ProviderWarnings    []string   `json:"provider_warnings,omitempty"`
 }
 
 type sessionResponse struct {
@@ -344,6 +515,8 @@ type sessionResponse struct {
 	FocusedArtifactID   string                       `json:"focused_artifact_id,omitempty"`
 	UnseenArtifactCount int                          `json:"unseen_artifact_count,omitempty"`
 	Gatepost            *gatepostResponse            `json:"gatepost,omitempty"`
+	Services            []target.ComposeService      `json:"services,omitempty"` // compose sessions only
+	Stats               *target.ResourceStats        `json:"stats,omitempty"`    // with ?stats=1
 	Stale               session.StaleStatus          `json:"stale"`
 	Status              session.SessionStatusSummary `json:"status"`
 }
@@ -386,7 +559,14 @@ func buildSessionResponse(sess *session.Session) sessionResponse {
 			// Caddy / the Cloudflare tunnel and on other devices.
 			logsURL = gatepostLogsProxyURL(sess.Name)
 		}
-		gatepost = &gatepostResponse{Enabled: true, Runtime: sess.Target.Gatepost.Runtime, LogsURL: logsURL, Bypass: sess.Target.Gatepost.Bypass, ProviderMode: sess.Target.Gatepost.ProviderMode, RegisteredProviders: sess.Target.Gatepost.RegisteredProviders, ProviderWarnings: sess.Target.Gatepost.ProviderWarnings}
+		gatepost = &gatepostResponse{Enabled: true, Runtime: sess.Target.Gatepost.Runtime, LogsURL: logsURL, Bypass: sess.Target.Gatepost.Bypass, Phase: sess.Target.Gatepost.CurrentPhase(), ProviderMode: sess.Target.Gatepost.ProviderMode, RegisteredProviders: sess.Target.Gatepost.RegisteredProviders, ProviderWarnings: sess.Target.Gatepost.ProviderWarnings}
+		if gatepost.Phase != "run" {
+			gatepost.PhaseRevertAt = sess.Target.Gatepost.PhaseRevertAt
+		}
+		if gatepost.Bypass {
+			gatepost.BypassUntil = sess.Target.Gatepost.BypassUntil
+			gatepost.BypassReason = sess.Target.Gatepost.BypassReason
+		}
 	}
 	return sessionResponse{
 		Name:                sess.Name,
@@ -432,9 +612,91 @@ func handleGatepostLogsRedirect(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, gatepostLogsProxyURL(sess.Name), http.StatusFound)
 }
 
+// handleGatepostAudit serves a session's parsed Gatepost audit log, newest
+// first, with the same filters as `devx session gatepost audit`.
+func handleGatepostAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	name := q.Get("name")
+	if name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name query param required"})
+		return
+	}
+	since, err := target.ParseAuditSince(q.Get("since"), time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	decision := q.Get("decision")
+	if decision != "" && decision != "allowed" && decision != "denied" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "decision must be allowed or denied"})
+		return
+	}
+	limit, offset := 100, 0
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > 1000 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 1000"})
+			return
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "offset must be a non-negative integer"})
+			return
+		}
+	}
+
+	store, err := session.LoadSessions()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	sess, ok := store.GetSession(name)
+	if !ok || !sess.Target.Gatepost.Enabled || sess.Target.Gatepost.AuditLog == "" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "gatepost audit log not found"})
+		return
+	}
+	entries, err := target.ReadGatepostAudit(sess.Target.Gatepost.AuditLog, target.GatepostAuditFilter{
+		Since:    since,
+		Host:     q.Get("host"),
+		Decision: decision,
+	})
+	if err != nil && !os.IsNotExist(err) {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	summary := target.SummarizeGatepostAudit(entries, 10)
+	slices.Reverse(entries)
+	page := []target.GatepostAuditEntry{}
+	if offset < len(entries) {
+		page = entries[offset:min(offset+limit, len(entries))]
+	}
+	bypassEvents := []target.GatepostBypassEvent{}
+	if q.Get("host") == "" && decision == "" {
+		events, err := target.ReadGatepostBypassEvents(sess.Target.Gatepost, since)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		bypassEvents = append(bypassEvents, events...)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"entries":       page,
+		"total":         len(entries),
+		"offset":        offset,
+		"limit":         limit,
+		"summary":       summary,
+		"bypass_events": bypassEvents,
+	})
+}
+
 func handleListSessions(w http.ResponseWriter, r *http.Request) {
 	cacheKey := os.Getenv("HOME") + "|" + session.SessionsMetadataFingerprint() + "|" + strconv.Itoa(defaultStaleDays())
+	withStats := r.URL.Query().Get("stats") == "1"
 	if payload, ok := getCachedSessionList(cacheKey); ok {
+		payload = withComposeServices(payload)
+		if withStats {
+			payload = withResourceStats(payload)
+		}
 		writeJSON(w, http.StatusOK, payload)
 		return
 	}
@@ -466,9 +728,84 @@ func handleListSessions(w http.ResponseWriter, r *http.Request) {
 	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Name < sessions[j].Name })
 	payload := map[string]any{"sessions": sessions, "stale_summary": summary}
 	setCachedSessionList(cacheKey, payload)
+	payload = withComposeServices(payload)
+	if withStats {
+		payload = withResourceStats(payload)
+	}
 	writeJSON(w, http.StatusOK, payload)
 }
 
+// withComposeServices returns a copy of a session list payload with the
+// service states of compose sessions attached. They are sampled per request
+// under a short timeout, so a slow Docker daemon leaves them out instead of
+// stalling the list.
+func withComposeServices(payload map[string]any) map[string]any {
+	sessions, ok := payload["sessions"].([]sessionResponse)
+	if !ok {
+		return payload
+	}
+	store, err := session.LoadSessions()
+	if err != nil {
+		return payload
+	}
+	var withServices []sessionResponse
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for i, resp := range sessions {
+		sess, ok := store.GetSession(resp.Name)
+		if !ok || sess.TargetType() != "compose" {
+			continue
+		}
+		if withServices == nil {
+			withServices = make([]sessionResponse, len(sessions))
+			copy(withServices, sessions)
+		}
+		withServices[i].Services, _ = target.ComposeServices(ctx, sess.Target)
+	}
+	if withServices == nil {
+		return payload
+	}
+	out := make(map[string]any, len(payload))
+	for k, v := range payload {
+		out[k] = v
+	}
+	out["sessions"] = withServices
+	return out
+}
+
+// withResourceStats returns a copy of a session list payload with live
+// container stats attached. Stats are sampled per request and never cached.
+func withResourceStats(payload map[string]any) map[string]any {
+	sessions, ok := payload["sessions"].([]sessionResponse)
+	if !ok {
+		return payload
+	}
+	store, err := session.LoadSessions()
+	if err != nil {
+		return payload
+	}
+	metas := make(map[string]session.TargetMeta, len(store.Sessions))
+	for name, sess := range store.Sessions {
+		metas[name] = sess.Target
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stats := target.CollectResourceStats(ctx, metas)
+	target.RecordOOMKills(store, stats)
+
+	withStats := make([]sessionResponse, len(sessions))
+	copy(withStats, sessions)
+	for i := range withStats {
+		withStats[i].Stats = stats[withStats[i].Name]
+	}
+	out := make(map[string]any, len(payload))
+	for k, v := range payload {
+		out[k] = v
+	}
+	out["sessions"] = withStats
+	return out
+}
+
 func handleStaleSessions(w http.ResponseWriter, r *http.Request) {
 	days, err := parseStaleDays(r.URL.Query().Get("days"))
 	if err != nil {
@@ -515,12 +852,13 @@ type createSessionRequest struct {
 	Target  string `json:"target"`
 }
 
-func isValidSessionTarget(target string) bool {
-	switch target {
-	case "", "host", "docker", "gatepost":
+func isValidSessionTarget(targetType string) bool {
+	switch targetType {
+	case "", "host", "docker", "podman", "compose", "gatepost", "sandbox", "remote":
 		return true
 	default:
-		return false
+		_, err := target.LookupPlugin(targetType)
+		return err == nil
 	}
 }
 
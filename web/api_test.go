@@ -17,6 +17,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jfox85/devx/ask"
 	"github.com/jfox85/devx/session"
 	"github.com/spf13/viper"
 )
@@ -893,3 +894,143 @@ func TestHandleUploadImageAcceptsValidSession(t *testing.T) {
 		t.Fatalf("valid session wrongly rejected: %s", w.Body.String())
 	}
 }
+
+func TestGatepostAuditPagesNewestFirstWithFilters(t *testing.T) {
+	setupEmptySessionStoreForTest(t)
+	auditLog := filepath.Join(t.TempDir(), "audit.jsonl")
+	var lines []string
+	for i, host := range []string{"api.github.com", "evil.example.com", "codeload.github.com", "api.github.com"} {
+		decision := "allow"
+		if strings.HasPrefix(host, "evil") || strings.HasPrefix(host, "codeload") {
+			decision = "deny"
+		}
+		lines = append(lines, fmt.Sprintf(`{"ts":"2026-10-18T10:0%d:00Z","seq":%d,"host":%q,"decision":%q}`, i, i+1, host, decision))
+	}
+	if err := os.WriteFile(auditLog, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := &session.SessionStore{Sessions: map[string]*session.Session{
+		"gp":   {Name: "gp", Path: t.TempDir(), Target: session.TargetMeta{Type: "gatepost", Gatepost: session.GatepostMeta{Enabled: true, AuditLog: auditLog}}},
+		"host": {Name: "host", Path: t.TempDir()},
+	}, NumberedSlots: map[int]string{}}
+	if err := store.Overwrite(); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	registerAPIRoutes(mux)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/gatepost/audit?"+query, nil))
+		return w
+	}
+
+	w := get("name=gp&host=*.github.com&limit=2&offset=1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Entries []struct {
+			Seq      int    `json:"seq"`
+			Host     string `json:"host"`
+			Decision string `json:"decision"`
+		} `json:"entries"`
+		Total   int `json:"total"`
+		Summary struct {
+			Denied    int `json:"denied"`
+			TopDenied []struct {
+				Host string `json:"host"`
+			} `json:"top_denied"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 3 || len(resp.Entries) != 2 || resp.Entries[0].Seq != 3 || resp.Entries[1].Seq != 1 {
+		t.Fatalf("unexpected page: %s", w.Body.String())
+	}
+	if resp.Summary.Denied != 1 || len(resp.Summary.TopDenied) != 1 || resp.Summary.TopDenied[0].Host != "codeload.github.com" {
+		t.Fatalf("unexpected summary: %s", w.Body.String())
+	}
+
+	for query, code := range map[string]int{
+		"":                        http.StatusBadRequest,
+		"name=gp&decision=maybe":  http.StatusBadRequest,
+		"name=gp&since=yesterday": http.StatusBadRequest,
+		"name=gp&limit=0":         http.StatusBadRequest,
+		"name=host":               http.StatusNotFound,
+		"name=missing":            http.StatusNotFound,
+	} {
+		if w := get(query); w.Code != code {
+			t.Errorf("%q: expected %d, got %d: %s", query, code, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestEgressApprovalEndpointsValidateRequests(t *testing.T) {
+	setupEmptySessionStoreForTest(t)
+	mux := http.NewServeMux()
+	registerAPIRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/gatepost/pending", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"requests"`) {
+		t.Fatalf("pending: %d %s", w.Code, w.Body.String())
+	}
+	for _, tc := range []struct {
+		path, body string
+		code       int
+	}{
+		{"/api/gatepost/pending/allow", `{}`, http.StatusBadRequest},
+		{"/api/gatepost/pending/allow", `{"id":"egr_1","scope":"forever"}`, http.StatusBadRequest},
+		{"/api/gatepost/pending/allow", `{"id":"egr_1","scope":"once"}`, http.StatusConflict},
+		{"/api/gatepost/pending/deny", `{}`, http.StatusBadRequest},
+		{"/api/gatepost/pending/deny", `{"id":"egr_1"}`, http.StatusConflict},
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body)))
+		if w.Code != tc.code {
+			t.Errorf("%s %s: expected %d, got %d: %s", tc.path, tc.body, tc.code, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestAskApprovalsListAndRevoke(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	store := ask.NewStore()
+	if err := store.Allow(ask.Approval{FromProject: "web", ToProject: "api"}); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	registerAPIRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/asks/approvals", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("list = %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Approvals []struct {
+			From    string `json:"from"`
+			To      string `json:"to"`
+			Project bool   `json:"project"`
+		} `json:"approvals"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Approvals) != 1 || resp.Approvals[0].From != "project:web" || resp.Approvals[0].To != "project:api" || !resp.Approvals[0].Project {
+		t.Fatalf("approvals = %+v", resp.Approvals)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/asks/approvals?from=project:web&to=project:api", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("revoke = %d: %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/asks/approvals?from=project:web&to=project:api", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("second revoke = %d, want 404", w.Code)
+	}
+}
@@ -52,18 +52,58 @@ export async function listPendingAsks() {
   return data.requests || []
 }
 
-export async function approveAsk(id, { always = false } = {}) {
-  const res = await apiFetch('/asks/approve', { method: 'POST', body: JSON.stringify({ id, always }) })
+export async function getAskThread(id) {
+  const res = await apiFetch(`/asks/thread?id=${encodeURIComponent(id)}`)
+  await requireOK(res, 'Failed to load ask thread')
+  const data = await res.json()
+  return data.requests || []
+}
+
+export async function approveAsk(id, { always = false, forDuration = '', projects = false } = {}) {
+  const res = await apiFetch('/asks/approve', { method: 'POST', body: JSON.stringify({ id, always, for: forDuration || undefined, projects: projects || undefined }) })
   await requireOK(res, 'Failed to approve ask')
   return res.json()
 }
 
+export async function listAskApprovals() {
+  const res = await apiFetch('/asks/approvals')
+  await requireOK(res, 'Failed to list ask approvals')
+  const data = await res.json()
+  return data.approvals || []
+}
+
+export async function revokeAskApproval(from, to) {
+  const params = new URLSearchParams({ from, to })
+  const res = await apiFetch(`/asks/approvals?${params}`, { method: 'DELETE' })
+  await requireOK(res, 'Failed to revoke ask approval')
+  return res.json()
+}
+
 export async function denyAsk(id) {
   const res = await apiFetch('/asks/deny', { method: 'POST', body: JSON.stringify({ id }) })
   await requireOK(res, 'Failed to deny ask')
   return res.json()
 }
 
+export async function listPendingEgress() {
+  const res = await apiFetch('/gatepost/pending')
+  await requireOK(res, 'Failed to list pending egress approvals')
+  const data = await res.json()
+  return data.requests || []
+}
+
+export async function allowEgress(id, scope) {
+  const res = await apiFetch('/gatepost/pending/allow', { method: 'POST', body: JSON.stringify({ id, scope }) })
+  await requireOK(res, 'Failed to allow host')
+  return res.json()
+}
+
+export async function denyEgress(id) {
+  const res = await apiFetch('/gatepost/pending/deny', { method: 'POST', body: JSON.stringify({ id }) })
+  await requireOK(res, 'Failed to deny host')
+  return res.json()
+}
+
 export async function createSession(name, project, options = {}) {
   const body = { name, project }
   if (options.target) body.target = options.target
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jfox85/devx/egress"
+)
+
+const egressWatchInterval = 3 * time.Second
+
+// watchGatepostEgress tails the audit logs of Gatepost sessions and turns
+// blocked unknown hosts into pending egress approvals, announcing each new one
+// to browser clients as an "egress" event. It also restores enforcement for
+// sessions whose time-boxed bypass has run out and returns timed phase
+// switches to the run phase.
+func (s *Server) watchGatepostEgress(ctx context.Context) {
+	store := egress.NewStore()
+	ticker := time.NewTicker(egressWatchInterval)
+	defer ticker.Stop()
+	var lastErr, lastBypassErr, lastPhaseErr string
+	for {
+		added, err := store.SyncSessions()
+		if err != nil && err.Error() != lastErr {
+			log.Printf("gatepost egress watch: %v", err)
+		}
+		lastErr = ""
+		if err != nil {
+			lastErr = err.Error()
+		}
+		for _, req := range added {
+			if payload, err := json.Marshal(req); err == nil {
+				s.hub.broadcastEvent("egress", string(payload))
+			}
+		}
+		expired, err := egress.ExpireBypasses(ctx, time.Now())
+		for _, name := range expired {
+			log.Printf("gatepost bypass for %s expired; enforcement restored", name)
+		}
+		if err != nil && err.Error() != lastBypassErr {
+			log.Printf("gatepost bypass expiry: %v", err)
+		}
+		lastBypassErr = ""
+		if err != nil {
+			lastBypassErr = err.Error()
+		}
+		reverted, err := egress.ExpirePhases(ctx, time.Now())
+		for _, name := range reverted {
+			log.Printf("gatepost phase for %s expired; back to the run phase", name)
+		}
+		if err != nil && err.Error() != lastPhaseErr {
+			log.Printf("gatepost phase expiry: %v", err)
+		}
+		lastPhaseErr = ""
+		if err != nil {
+			lastPhaseErr = err.Error()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -27,6 +27,7 @@ type Server struct {
 	terminal    *terminalService
 	hub         *sseHub
 	gatepostCfg target.GatepostRuntimeConfig
+	stopWatch   context.CancelFunc
 }
 
 // New creates a new Server. token must be non-empty.
@@ -74,12 +75,19 @@ func (s *Server) Start() error {
 		return fmt.Errorf("failed to listen on port %d: %w", s.port, err)
 	}
 
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	s.stopWatch = stopWatch
+	go s.watchGatepostEgress(watchCtx)
+
 	fmt.Printf("devx web listening on http://%s:%d\n", s.bind, s.port)
 	return s.server.Serve(ln)
 }
 
 // Shutdown gracefully stops the server.
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.stopWatch != nil {
+		s.stopWatch()
+	}
 	if s.server == nil {
 		return nil
 	}